@@ -0,0 +1,142 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package client
+
+//go:generate easyjson
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	PathAdminPrefix          = "/api/admin/"
+	PathAdminAccountCreateV1 = "/api/admin/account/create/v1"
+	PathAdminAccountListV1   = "/api/admin/account/list/v1"
+	PathAdminAccountShowV1   = "/api/admin/account/show/v1"
+	PathAdminAccountUpdateV1 = "/api/admin/account/update/v1"
+	PathAdminAccountDeleteV1 = "/api/admin/account/delete/v1"
+	PathAdminScepChallengeV1 = "/api/admin/scep/challenge/v1"
+)
+
+//easyjson:json
+type AccountModel struct {
+	ID       int64    `json:"id"`
+	TokenID  string   `json:"token_id"`
+	TokenKey string   `json:"token_key,omitempty"`
+	Domains  []string `json:"domains"`
+	Profiles []string `json:"profiles,omitempty"`
+	Locked   bool     `json:"locked"`
+	// Deleted is false after a delete request if the account is locked instead,
+	// it has certificates that must stay in CRLs and OCSP until they expire
+	Deleted   bool      `json:"deleted,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//easyjson:json
+type AccountsModel struct {
+	Accounts   []AccountModel `json:"accounts"`
+	NextCursor int64          `json:"next_cursor,omitempty"`
+}
+
+//easyjson:json
+type AccountRequest struct {
+	TokenID   string   `json:"token_id,omitempty"`
+	Domains   []string `json:"domains,omitempty"`
+	Profiles  []string `json:"profiles,omitempty"`
+	Locked    *bool    `json:"locked,omitempty"`
+	RotateKey bool     `json:"rotate_key,omitempty"`
+}
+
+//easyjson:json
+type AccountsRequest struct {
+	Cursor int64 `json:"cursor"`
+	Limit  uint  `json:"limit"`
+}
+
+//easyjson:json
+type ScepChallengeRequest struct {
+	TokenID string `json:"token_id"`
+}
+
+//easyjson:json
+type ScepChallengeModel struct {
+	Challenge string    `json:"challenge"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AdminClient interface {
+	AccountCreateV1(ctx context.Context, req AccountRequest) (*AccountModel, error)
+	AccountListV1(ctx context.Context, req AccountsRequest) (*AccountsModel, error)
+	AccountShowV1(ctx context.Context, tokenId string) (*AccountModel, error)
+	AccountUpdateV1(ctx context.Context, req AccountRequest) (*AccountModel, error)
+	AccountDeleteV1(ctx context.Context, tokenId string) (*AccountModel, error)
+	ScepChallengeV1(ctx context.Context, tokenId string) (*ScepChallengeModel, error)
+}
+
+// NewAdmin creates a client for the admin API, AuthID and AuthKey must be one of the admin tokens.
+func NewAdmin(c Config) (AdminClient, error) {
+	cli, err := New(c)
+	if err != nil {
+		return nil, err
+	}
+	return cli.(*_client), nil
+}
+
+func (c *_client) AccountCreateV1(ctx context.Context, req AccountRequest) (*AccountModel, error) {
+	resp := &AccountModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminAccountCreateV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *_client) AccountListV1(ctx context.Context, req AccountsRequest) (*AccountsModel, error) {
+	resp := &AccountsModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminAccountListV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *_client) AccountShowV1(ctx context.Context, tokenId string) (*AccountModel, error) {
+	req := AccountRequest{TokenID: tokenId}
+	resp := &AccountModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminAccountShowV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to show account: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *_client) AccountUpdateV1(ctx context.Context, req AccountRequest) (*AccountModel, error) {
+	resp := &AccountModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminAccountUpdateV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+	return resp, nil
+}
+
+// AccountDeleteV1 deletes the account, an account with certificates is locked instead.
+func (c *_client) AccountDeleteV1(ctx context.Context, tokenId string) (*AccountModel, error) {
+	req := AccountRequest{TokenID: tokenId}
+	resp := &AccountModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminAccountDeleteV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to delete account: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *_client) ScepChallengeV1(ctx context.Context, tokenId string) (*ScepChallengeModel, error) {
+	req := ScepChallengeRequest{TokenID: tokenId}
+	resp := &ScepChallengeModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathAdminScepChallengeV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to create scep challenge: %w", err)
+	}
+	return resp, nil
+}
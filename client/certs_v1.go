@@ -0,0 +1,96 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package client
+
+//go:generate easyjson
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const PathCertsV1 = "/api/certs/v1"
+
+const (
+	CertsQueryDomain        = "domain"
+	CertsQueryRevoked       = "revoked"
+	CertsQueryExpiresAfter  = "expires_after"
+	CertsQueryExpiresBefore = "expires_before"
+	CertsQueryCursor        = "cursor"
+	CertsQueryLimit         = "limit"
+)
+
+//easyjson:json
+type CertsModel struct {
+	Certs      []CertModel `json:"certs"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+//easyjson:json
+type CertModel struct {
+	SerialNumber  string    `json:"serial_number"`
+	Subject       string    `json:"subject"`
+	FingerPrint   string    `json:"fingerprint"`
+	Domains       []string  `json:"domains"`
+	Revoked       bool      `json:"revoked"`
+	RevokedReason int64     `json:"revoked_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	ValidUntil    time.Time `json:"valid_until"`
+}
+
+type CertsRequest struct {
+	Domain        string
+	Revoked       *bool
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+	Cursor        string
+	Limit         uint
+}
+
+func (v CertsRequest) Encode() string {
+	q := url.Values{}
+	if len(v.Domain) > 0 {
+		q.Set(CertsQueryDomain, v.Domain)
+	}
+	if v.Revoked != nil {
+		q.Set(CertsQueryRevoked, strconv.FormatBool(*v.Revoked))
+	}
+	if !v.ExpiresAfter.IsZero() {
+		q.Set(CertsQueryExpiresAfter, v.ExpiresAfter.UTC().Format(time.RFC3339))
+	}
+	if !v.ExpiresBefore.IsZero() {
+		q.Set(CertsQueryExpiresBefore, v.ExpiresBefore.UTC().Format(time.RFC3339))
+	}
+	if len(v.Cursor) > 0 {
+		q.Set(CertsQueryCursor, v.Cursor)
+	}
+	if v.Limit > 0 {
+		q.Set(CertsQueryLimit, strconv.FormatUint(uint64(v.Limit), 10))
+	}
+	return q.Encode()
+}
+
+func (c *_client) CertsV1(ctx context.Context, req CertsRequest) (*CertsModel, error) {
+	uri := c.cfg.Address + PathCertsV1
+	if query := req.Encode(); len(query) > 0 {
+		uri += "?" + query
+	}
+
+	if c.get == nil {
+		return nil, fmt.Errorf("failed to list certs: signature v2 is required")
+	}
+
+	resp := &CertsModel{}
+	if err := c.get.Send(ctx, http.MethodGet, uri, nil, resp); err != nil {
+		return nil, fmt.Errorf("failed to list certs: %w", err)
+	}
+
+	return resp, nil
+}
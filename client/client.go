@@ -7,6 +7,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net/url"
@@ -20,11 +21,18 @@ import (
 
 type Client interface {
 	RenewalV1(ctx context.Context, force bool, csr x509.CertificateRequest) (*RenewalModel, error)
+	RenewalProfileV1(ctx context.Context, force bool, profile string, csr x509.CertificateRequest) (*RenewalModel, error)
+	RevokeV1(ctx context.Context, req RevokeRequest) (*RevokeModel, error)
+	CertsV1(ctx context.Context, req CertsRequest) (*CertsModel, error)
 }
 
 type _client struct {
 	cfg *Config
 	cli wc.HTTPClient
+	// get sends GET requests, they are signed over the method, path and query
+	get wc.HTTPClient
+	// renewal sends renewal requests, authenticated by the client certificate if it is set
+	renewal wc.HTTPClient
 }
 
 func New(c Config) (Client, error) {
@@ -38,6 +46,21 @@ func New(c Config) (Client, error) {
 		return nil, fmt.Errorf("failed to parse address %s: %w", obj.cfg.Address, err)
 	}
 
+	var conf *tls.Config
+	if len(obj.cfg.TLSCert) > 0 || len(obj.cfg.TLSKey) > 0 {
+		if conf, err = newTLSConfig(obj.cfg); err != nil {
+			return nil, err
+		}
+		if obj.renewal, err = newDirectClient(obj.cfg, conf, nil); err != nil {
+			return nil, err
+		}
+		if len(obj.cfg.AuthID) == 0 && len(obj.cfg.AuthKey) == 0 {
+			noAuth := errClient{err: fmt.Errorf("got empty auth id and auth key, the client certificate authenticates renewal only")}
+			obj.cli, obj.get = noAuth, noAuth
+			return obj, nil
+		}
+	}
+
 	if len(obj.cfg.AuthID) == 0 {
 		return nil, fmt.Errorf("got empty auth id")
 	}
@@ -45,6 +68,29 @@ func New(c Config) (Client, error) {
 		return nil, fmt.Errorf("got empty auth key")
 	}
 
+	var sig signature.Signature
+	switch obj.cfg.SignatureVersion {
+	case "", "v2":
+		if sig, err = NewSignatureV2(obj.cfg.AuthID, obj.cfg.AuthKey, SignatureAlgSHA256V2); err != nil {
+			return nil, err
+		}
+		if obj.get, err = newDirectClient(obj.cfg, conf, sig); err != nil {
+			return nil, err
+		}
+	case "v1":
+		sig = signature.NewSHA1(obj.cfg.AuthID, obj.cfg.AuthKey)
+	default:
+		return nil, fmt.Errorf("unsupported signature version: %s", obj.cfg.SignatureVersion)
+	}
+
+	if conf != nil {
+		// the other requests are signed as without the certificate, over the same TLS config
+		if obj.cli, err = newDirectClient(obj.cfg, conf, sig); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
 	obj.cli = wc.NewHTTPClient(
 		wc.WithProxy(c.Proxy),
 		wc.WithDefaultHeaders(map[string]string{
@@ -55,9 +101,17 @@ func New(c Config) (Client, error) {
 			comparison.JSON{},
 		),
 		wc.WithSignatures(map[string]signature.Signature{
-			uri.Host: signature.NewSHA1(obj.cfg.AuthID, obj.cfg.AuthKey),
+			uri.Host: sig,
 		}),
 	)
+	obj.renewal = obj.cli
 
 	return obj, nil
 }
+
+// errClient fails every request, it stands for a client without credentials.
+type errClient struct {
+	err error
+}
+
+func (v errClient) Send(context.Context, string, string, any, any) error { return v.err }
@@ -0,0 +1,226 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.osspkg.com/goppy/v2/auth/signature"
+)
+
+const (
+	testAuthID  = "00000000-0000-0000-0000-000000000001"
+	testAuthKey = "secret"
+)
+
+type testCall struct {
+	path     string
+	peerCert bool
+	signed   bool
+}
+
+// testServer serves TLS, asks for a client certificate and records how every call was authenticated,
+// a signature must be valid v2 over the data the server verifies.
+func testServer(t *testing.T) (*httptest.Server, func() []testCall) {
+	t.Helper()
+
+	var (
+		mux   sync.Mutex
+		calls []testCall
+	)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		call := testCall{path: r.URL.Path, peerCert: r.TLS != nil && len(r.TLS.PeerCertificates) > 0}
+		if data, err := signature.Decode(r.Header); err == nil && data != nil && len(data.Sig) > 0 {
+			sig, err := NewSignatureV2(data.ID, testAuthKey, data.Alg)
+			if err != nil || data.ID != testAuthID {
+				http.Error(w, "invalid signature", http.StatusForbidden)
+				return
+			}
+			signed := body
+			if r.Method == http.MethodGet {
+				signed = SignedRequestData(r.Method, r.URL.Path, r.URL.RawQuery, body)
+			}
+			if !sig.Verify(signed, data.Sig) {
+				http.Error(w, "invalid signature", http.StatusForbidden)
+				return
+			}
+			call.signed = true
+		}
+
+		mux.Lock()
+		calls = append(calls, call)
+		mux.Unlock()
+
+		switch r.URL.Path {
+		case PathRenewalV1:
+			_, _ = w.Write([]byte(`{"status":"actual"}`))
+		case PathRevokeV1:
+			_, _ = w.Write([]byte(`{"status":"revoked","serial_number":"1"}`))
+		case PathCertsV1:
+			_, _ = w.Write([]byte(`{"certs":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv, func() []testCall {
+		mux.Lock()
+		defer mux.Unlock()
+		return append([]testCall(nil), calls...)
+	}
+}
+
+// testClientCert writes a self-signed client certificate with its key and the PEM of the server certificate.
+func testClientCert(t *testing.T, srv *httptest.Server) (certFile, keyFile, rootFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "host.example.com"},
+		DNSNames:     []string{"host.example.com"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "host.example.com"}}, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile, rootFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "root.pem")
+	for file, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "PRIVATE KEY", Bytes: keyDER},
+		rootFile: {Type: "CERTIFICATE", Bytes: srv.Certificate().Raw},
+	} {
+		if err = os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+	return certFile, keyFile, rootFile
+}
+
+func testCSR(t *testing.T) x509.CertificateRequest {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"host.example.com"}}, key)
+	if err != nil {
+		t.Fatalf("failed to create csr: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to decode csr: %v", err)
+	}
+	return *csr
+}
+
+func TestClientTLSCert(t *testing.T) {
+	tests := []struct {
+		name    string
+		authID  string
+		authKey string
+		want    []testCall
+		wantErr string
+	}{
+		{
+			name:    "certificate and auth key",
+			authID:  testAuthID,
+			authKey: testAuthKey,
+			want: []testCall{
+				{path: PathRenewalV1, peerCert: true},
+				{path: PathRevokeV1, peerCert: true, signed: true},
+				{path: PathCertsV1, peerCert: true, signed: true},
+			},
+		},
+		{
+			name:    "certificate only",
+			want:    []testCall{{path: PathRenewalV1, peerCert: true}},
+			wantErr: "renewal only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := testServer(t)
+			certFile, keyFile, rootFile := testClientCert(t, srv)
+
+			cli, err := New(Config{
+				Address:   srv.URL,
+				AuthID:    tt.authID,
+				AuthKey:   tt.authKey,
+				TLSCert:   certFile,
+				TLSKey:    keyFile,
+				TLSRootCA: rootFile,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			ctx := context.Background()
+			if _, err = cli.RenewalV1(ctx, false, testCSR(t)); err != nil {
+				t.Fatalf("RenewalV1() error = %v", err)
+			}
+			_, revokeErr := cli.RevokeV1(ctx, RevokeRequest{SerialNumber: "1", Reason: 1})
+			_, certsErr := cli.CertsV1(ctx, CertsRequest{Domain: "host.example.com", Limit: 10})
+			for _, err = range []error{revokeErr, certsErr} {
+				if len(tt.wantErr) == 0 && err != nil {
+					t.Fatalf("unexpected error = %v", err)
+				}
+				if len(tt.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			}
+
+			got := calls()
+			if len(got) != len(tt.want) {
+				t.Fatalf("calls = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("call %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
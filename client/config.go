@@ -10,4 +10,13 @@ type Config struct {
 	Proxy   string `yaml:"proxy"`
 	AuthID  string `yaml:"auth_id"`
 	AuthKey string `yaml:"auth_key"`
+	// SignatureVersion selects the request signature scheme: v2 (default) or v1
+	// for servers without replay protection.
+	SignatureVersion string `yaml:"signature_version,omitempty"`
+	// TLSCert and TLSKey authenticate renewal with a certificate issued by casper
+	// instead of AuthID and AuthKey, the other requests are still signed with them.
+	// TLSRootCA overrides the system roots for the server.
+	TLSCert   string `yaml:"tls_cert,omitempty"`
+	TLSKey    string `yaml:"tls_key,omitempty"`
+	TLSRootCA string `yaml:"tls_root_ca,omitempty"`
 }
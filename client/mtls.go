@@ -0,0 +1,128 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+
+	"go.osspkg.com/goppy/v2/auth/signature"
+)
+
+const mtlsMaxResponseSize = 10 << 20
+
+// directClient sends requests with net/http: authenticated by a certificate issued by casper,
+// which the server accepts for renewal of the same names only, or signed over the body,
+// and for GET over the whole request with SignedRequestData.
+type directClient struct {
+	cli *http.Client
+	sig signature.Signature
+}
+
+func newTLSConfig(c *Config) (*tls.Config, error) {
+	crt, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{crt},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(c.TLSRootCA) > 0 {
+		b, err := os.ReadFile(c.TLSRootCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to decode root ca: %s", c.TLSRootCA)
+		}
+		conf.RootCAs = pool
+	}
+
+	return conf, nil
+}
+
+func newDirectClient(c *Config, conf *tls.Config, sig signature.Signature) (*directClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if conf != nil {
+		transport.TLSClientConfig = conf
+	}
+	switch c.Proxy {
+	case "env":
+	case "":
+		transport.Proxy = nil
+	default:
+		uri, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy %s: %w", c.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(uri)
+	}
+
+	return &directClient{cli: &http.Client{Transport: transport}, sig: sig}, nil
+}
+
+func (v *directClient) Send(ctx context.Context, method, uri string, in, out any) error {
+	var raw []byte
+	if in != nil {
+		var err error
+		if raw, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "casper-client, go_version: "+runtime.Version())
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if v.sig != nil {
+		data := raw
+		if method == http.MethodGet {
+			data = SignedRequestData(method, req.URL.Path, req.URL.RawQuery, raw)
+		}
+		signature.Encode(req.Header, v.sig, data)
+	}
+
+	resp, err := v.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, mtlsMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response code %d: %s", resp.StatusCode, string(b))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err = json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
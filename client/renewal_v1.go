@@ -30,30 +30,42 @@ const (
 
 //easyjson:json
 type RenewalModel struct {
-	Status RenewalStatus `json:"status"`
-	CA     []string      `json:"ca,omitempty"`
-	Cert   string        `json:"cert,omitempty"`
+	Status   RenewalStatus `json:"status"`
+	CA       []string      `json:"ca,omitempty"`
+	Cert     string        `json:"cert,omitempty"`
+	DNSNames []string      `json:"dns_names,omitempty"`
+	URIs     []string      `json:"uris,omitempty"`
+	Emails   []string      `json:"emails,omitempty"`
 }
 
 //easyjson:json
 type RenewalRequest struct {
-	Force bool   `json:"force"`
-	CSR   string `json:"csr"`
+	Force   bool   `json:"force"`
+	CSR     string `json:"csr"`
+	Profile string `json:"profile,omitempty"`
 }
 
 func (c *_client) RenewalV1(ctx context.Context, force bool, csr x509.CertificateRequest) (*RenewalModel, error) {
+	return c.RenewalProfileV1(ctx, force, "", csr)
+}
+
+// RenewalProfileV1 requests the certificate with the named profile, an empty profile selects the server default.
+func (c *_client) RenewalProfileV1(
+	ctx context.Context, force bool, profile string, csr x509.CertificateRequest,
+) (*RenewalModel, error) {
 	b, err := pki.MarshalCsrPEM(csr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal CSR: %w", err)
 	}
 
 	req := RenewalRequest{
-		Force: force,
-		CSR:   string(b),
+		Force:   force,
+		CSR:     string(b),
+		Profile: profile,
 	}
 
 	resp := &RenewalModel{}
-	if err = c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathRenewalV1, &req, resp); err != nil {
+	if err = c.renewal.Send(ctx, http.MethodPost, c.cfg.Address+PathRenewalV1, &req, resp); err != nil {
 		return nil, fmt.Errorf("failed to renewal: %w", err)
 	}
 
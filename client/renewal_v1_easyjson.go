@@ -44,6 +44,12 @@ func easyjsonCb6870ccDecodeGoArwosOrgCasperClient(in *jlexer.Lexer, out *Renewal
 			} else {
 				out.CSR = string(in.String())
 			}
+		case "profile":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Profile = string(in.String())
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -68,6 +74,11 @@ func easyjsonCb6870ccEncodeGoArwosOrgCasperClient(out *jwriter.Writer, in Renewa
 		out.RawString(prefix)
 		out.String(string(in.CSR))
 	}
+	if in.Profile != "" {
+		const prefix string = ",\"profile\":"
+		out.RawString(prefix)
+		out.String(string(in.Profile))
+	}
 	out.RawByte('}')
 }
 
@@ -147,6 +158,87 @@ func easyjsonCb6870ccDecodeGoArwosOrgCasperClient1(in *jlexer.Lexer, out *Renewa
 			} else {
 				out.Cert = string(in.String())
 			}
+		case "dns_names":
+			if in.IsNull() {
+				in.Skip()
+				out.DNSNames = nil
+			} else {
+				in.Delim('[')
+				if out.DNSNames == nil {
+					if !in.IsDelim(']') {
+						out.DNSNames = make([]string, 0, 4)
+					} else {
+						out.DNSNames = []string{}
+					}
+				} else {
+					out.DNSNames = (out.DNSNames)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v2 = string(in.String())
+					}
+					out.DNSNames = append(out.DNSNames, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "uris":
+			if in.IsNull() {
+				in.Skip()
+				out.URIs = nil
+			} else {
+				in.Delim('[')
+				if out.URIs == nil {
+					if !in.IsDelim(']') {
+						out.URIs = make([]string, 0, 4)
+					} else {
+						out.URIs = []string{}
+					}
+				} else {
+					out.URIs = (out.URIs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v3 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v3 = string(in.String())
+					}
+					out.URIs = append(out.URIs, v3)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "emails":
+			if in.IsNull() {
+				in.Skip()
+				out.Emails = nil
+			} else {
+				in.Delim('[')
+				if out.Emails == nil {
+					if !in.IsDelim(']') {
+						out.Emails = make([]string, 0, 4)
+					} else {
+						out.Emails = []string{}
+					}
+				} else {
+					out.Emails = (out.Emails)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v4 = string(in.String())
+					}
+					out.Emails = append(out.Emails, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -171,11 +263,11 @@ func easyjsonCb6870ccEncodeGoArwosOrgCasperClient1(out *jwriter.Writer, in Renew
 		out.RawString(prefix)
 		{
 			out.RawByte('[')
-			for v2, v3 := range in.CA {
-				if v2 > 0 {
+			for v5, v6 := range in.CA {
+				if v5 > 0 {
 					out.RawByte(',')
 				}
-				out.String(string(v3))
+				out.String(string(v6))
 			}
 			out.RawByte(']')
 		}
@@ -185,6 +277,48 @@ func easyjsonCb6870ccEncodeGoArwosOrgCasperClient1(out *jwriter.Writer, in Renew
 		out.RawString(prefix)
 		out.String(string(in.Cert))
 	}
+	if len(in.DNSNames) != 0 {
+		const prefix string = ",\"dns_names\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v7, v8 := range in.DNSNames {
+				if v7 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v8))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.URIs) != 0 {
+		const prefix string = ",\"uris\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v9, v10 := range in.URIs {
+				if v9 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v10))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Emails) != 0 {
+		const prefix string = ",\"emails\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v11, v12 := range in.Emails {
+				if v11 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v12))
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -0,0 +1,65 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package client
+
+//go:generate easyjson
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const PathRevokeV1 = "/api/revoke/v1"
+
+type (
+	RevokeStatus string
+)
+
+const (
+	RevokeStatusRevoked        = "revoked"
+	RevokeStatusAlreadyRevoked = "already_revoked"
+)
+
+// RevocationReasons contains the RFC 5280 reason codes accepted by the revoke API.
+// certificateHold and removeFromCRL are not supported because revocation is permanent.
+var RevocationReasons = map[string]int64{
+	"unspecified":          0,
+	"keyCompromise":        1,
+	"cACompromise":         2,
+	"affiliationChanged":   3,
+	"superseded":           4,
+	"cessationOfOperation": 5,
+	"privilegeWithdrawn":   9,
+	"aACompromise":         10,
+}
+
+//easyjson:json
+type RevokeModel struct {
+	Status       RevokeStatus `json:"status"`
+	SerialNumber string       `json:"serial_number"`
+}
+
+//easyjson:json
+type RevokeRequest struct {
+	SerialNumber string `json:"serial_number,omitempty"`
+	FingerPrint  string `json:"fingerprint,omitempty"`
+	Cert         string `json:"cert,omitempty"`
+	Reason       int64  `json:"reason"`
+}
+
+func (c *_client) RevokeV1(ctx context.Context, req RevokeRequest) (*RevokeModel, error) {
+	if len(req.SerialNumber) == 0 && len(req.FingerPrint) == 0 && len(req.Cert) == 0 {
+		return nil, fmt.Errorf("require serial number, fingerprint or certificate")
+	}
+
+	resp := &RevokeModel{}
+	if err := c.cli.Send(ctx, http.MethodPost, c.cfg.Address+PathRevokeV1, &req, resp); err != nil {
+		return nil, fmt.Errorf("failed to revoke: %w", err)
+	}
+
+	return resp, nil
+}
@@ -16,5 +16,9 @@ func main() {
 	cli.AddCommand(cmds.GenerateCA())
 	cli.AddCommand(cmds.RenewalCert())
 	cli.AddCommand(cmds.RenewalCertAuto())
+	cli.AddCommand(cmds.RevokeCert())
+	cli.AddCommand(cmds.ListCerts())
+	cli.AddCommand(cmds.Account())
+	cli.AddCommand(cmds.Signer())
 	cli.Exec()
 }
@@ -4,6 +4,7 @@ go 1.25.3
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/miekg/pkcs11 v1.1.2
 	go.arwos.org/casper/client v0.0.0
 	go.osspkg.com/console v0.3.3
 	go.osspkg.com/do v0.2.1
@@ -16,6 +17,8 @@ require (
 	go.osspkg.com/routine v0.4.1
 	go.osspkg.com/syncing v0.4.3
 	go.osspkg.com/validate v0.1.0
+	golang.org/x/crypto v0.46.0
+	golang.org/x/term v0.38.0
 )
 
 replace go.arwos.org/casper/client => ./client
@@ -44,7 +47,6 @@ require (
 	go.osspkg.com/xc v0.4.0 // indirect
 	go.uber.org/automaxprocs v1.6.0 // indirect
 	go.uber.org/mock v0.5.0 // indirect
-	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/exp v0.0.0-20251219203646-944ab1f22d93 // indirect
 	golang.org/x/mod v0.31.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
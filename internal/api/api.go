@@ -7,13 +7,20 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"sync/atomic"
 
 	"go.osspkg.com/goppy/v2/web"
 	"go.osspkg.com/logx"
 	"go.osspkg.com/routine/tick"
 
 	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/acme"
 	"go.arwos.org/casper/internal/pkgs/certs"
 )
 
@@ -23,12 +30,61 @@ type API struct {
 
 	entityRepo *entity.Repo
 	certStore  *certs.Store
+	conf       *ConfigGroup
+
+	acmeNonces    *acme.Nonces
+	acmeValidator *acme.Validator
+
+	pkiTable      atomic.Pointer[pkiRoutes]
+	pkiRegistered map[string]struct{}
+	pkiMux        sync.Mutex
+
+	ocspRefresh chan struct{}
+
+	trustedProxies []netip.Prefix
+	proxySecret    []byte
+	mtls           *http.Server
 }
 
-func NewAPI(sp web.ServerPool, r *entity.Repo, cs *certs.Store) (*API, error) {
+func NewAPI(sp web.ServerPool, r *entity.Repo, cs *certs.Store, c *ConfigGroup) (*API, error) {
+	if err := c.Admin.Validate(); err != nil {
+		return nil, err
+	}
+
+	nonceKey := []byte(c.ACME.NonceKey)
+	if len(nonceKey) == 0 {
+		if c.ACME.Enabled {
+			logx.Warn("ACME nonce key is not set, nonces are accepted by this instance only")
+		}
+		nonceKey = acme.NewNonceKey()
+	}
+
 	obj := &API{
-		entityRepo: r,
-		certStore:  cs,
+		entityRepo:    r,
+		certStore:     cs,
+		conf:          c,
+		acmeNonces:    acme.NewNonces(nonceKey),
+		acmeValidator: acme.NewValidator(c.ACME),
+		pkiRegistered: make(map[string]struct{}),
+		ocspRefresh:   make(chan struct{}, 1),
+	}
+
+	var err error
+	if c.SCEP.Enabled {
+		if err = scepCheckIssuers(cs.List()); err != nil {
+			return nil, err
+		}
+	}
+	if c.MTLS.Enabled {
+		if obj.trustedProxies, err = parseTrustedProxies(c.MTLS.TrustedProxies); err != nil {
+			return nil, err
+		}
+		if obj.proxySecret, err = mtlsProxySecret(c.MTLS); err != nil {
+			return nil, err
+		}
+		if obj.mtls, err = obj.newMTLSServer(); err != nil {
+			return nil, err
+		}
 	}
 
 	var ok bool
@@ -44,25 +100,62 @@ func NewAPI(sp web.ServerPool, r *entity.Repo, cs *certs.Store) (*API, error) {
 
 func (v *API) Up(ctx context.Context) error {
 	v.addApiHandlers()
-	v.addCrlHandlers()
-	v.addIcuHandlers()
-	v.addOCSPHandlers()
+	v.applyPkiRoutes()
+
+	calls := []tick.Config{
+		v.tickerConfigCleanCrl(),
+		v.tickerConfigCleanCrlStore(),
+		v.tickerConfigBuildCrl(),
+		v.tickerConfigCleanNonce(),
+	}
+
+	if v.conf.CRL.DeltaInterval > 0 {
+		calls = append(calls, v.tickerConfigBuildDeltaCrl())
+	}
+
+	if v.conf.EST.Enabled {
+		v.addEstHandlers()
+	}
+
+	if v.conf.SCEP.Enabled {
+		v.addScepHandlers()
+		calls = append(calls, v.tickerConfigCleanScep())
+	}
+
+	if v.conf.ACME.Enabled {
+		v.addAcmeHandlers()
+		calls = append(calls, v.tickerConfigCleanAcme())
+	}
 
 	tik := tick.Ticker{
 		OnError: func(name string, err error) {
 			logx.Error(name, "err", err)
 		},
-		Calls: []tick.Config{
-			v.tickerConfigCleanCrl(),
-			v.tickerConfigBuildCrl(),
-		},
+		Calls: calls,
+	}
+
+	if v.mtls != nil {
+		ln, err := net.Listen("tcp", v.mtls.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen mtls %s: %w", v.mtls.Addr, err)
+		}
+		go func() {
+			if err := v.mtls.ServeTLS(ln, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logx.Error("mTLS server stopped", "addr", v.mtls.Addr, "err", err)
+			}
+		}()
 	}
 
 	go tik.Run(ctx)
+	go v.watchReload(ctx)
+	go v.runOCSPPresigner(ctx)
 
 	return nil
 }
 
 func (v *API) Down() error {
+	if v.mtls != nil {
+		return v.mtls.Close()
+	}
 	return nil
 }
@@ -5,4 +5,18 @@
 
 package api
 
+import (
+	"fmt"
+	"math/big"
+)
+
 type apiCtx string
+
+// parseSerial decodes a decimal serial number as it is stored in the database.
+func parseSerial(s string) (*big.Int, error) {
+	sn, ok := new(big.Int).SetString(s, 10)
+	if !ok || sn.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid serial number: %s", s)
+	}
+	return sn, nil
+}
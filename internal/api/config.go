@@ -0,0 +1,146 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go.arwos.org/casper/internal/pkgs/acme"
+)
+
+type ConfigGroup struct {
+	ACME      acme.Config     `yaml:"acme"`
+	EST       ESTConfig       `yaml:"est"`
+	SCEP      SCEPConfig      `yaml:"scep"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Signature SignatureConfig `yaml:"signature"`
+	CRL       CRLConfig       `yaml:"crl"`
+	OCSP      OCSPConfig      `yaml:"ocsp"`
+	MTLS      MTLSConfig      `yaml:"mtls"`
+}
+
+// ESTConfig enables RFC 7030 endpoints on the main server, client certificate
+// authentication requires the mTLS listener or a TLS proxy with the proxy secret, see MTLSConfig.
+type ESTConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// SCEPConfig enables RFC 8894 endpoints on the main server, challenge passwords
+// are one-time secrets created per account with the admin API. Requests are encrypted
+// to the issuing CA, so its key must be an RSA key of the file signer.
+type SCEPConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	ChallengeTTL time.Duration `yaml:"challenge_ttl"`
+}
+
+// SignatureConfig controls the HMAC signatures of the account and admin API.
+// Nonces of v2 signatures are kept in the database, so every instance rejects a replay.
+type SignatureConfig struct {
+	ClockSkew time.Duration `yaml:"clock_skew"`
+	AllowV1   bool          `yaml:"allow_v1"`
+}
+
+// CRLConfig controls delta CRLs, they are built for issuers with delta_crl_urls
+// from revocations missing in the last complete CRL. Zero interval turns them off.
+type CRLConfig struct {
+	DeltaInterval time.Duration `yaml:"delta_interval"`
+}
+
+// OCSPConfig controls the delegated responder certificates, they are issued per issuer
+// and instance, so the CA key signs them instead of every response. They are renewed
+// when a third of the validity is left. A request nonce is echoed in a response signed
+// for it, PreferCached ignores nonces and answers with the pre-signed response.
+// LiveSignRate limits the responses signed per request, for unknown serials and nonces,
+// per issuer and second: over it unknown serials get tryLater and nonces are ignored.
+// Zero turns the limit off.
+type OCSPConfig struct {
+	Delegated         bool          `yaml:"delegated"`
+	ResponderValidity time.Duration `yaml:"responder_validity"`
+	PreferCached      bool          `yaml:"prefer_cached"`
+	LiveSignRate      int           `yaml:"live_sign_rate"`
+}
+
+// MTLSConfig serves the main API over TLS with client certificates issued by casper, they
+// authenticate renewal and EST. The listener verifies the certificate against the issuing CAs
+// and forwards the request to Upstream, the main server, with the certificate in the
+// X-Client-Cert header (URL-escaped PEM) and the proxy secret in X-Client-Cert-Auth.
+// The main server reads the certificate header only with mTLS enabled and the secret given,
+// and from TrustedProxies (IPs or CIDRs) if they are listed. The secret is random per start
+// unless ProxySecret is set, then a TLS proxy sending it with nginx $ssl_client_escaped_cert
+// may be used instead of the listener.
+type MTLSConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Addr           string   `yaml:"addr"`
+	Cert           string   `yaml:"cert"`
+	Key            string   `yaml:"key"`
+	Upstream       string   `yaml:"upstream"`
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	ProxySecret    string   `yaml:"proxy_secret"`
+}
+
+type AdminConfig struct {
+	Tokens []AdminToken `yaml:"tokens"`
+}
+
+type AdminToken struct {
+	ID  string `yaml:"id"`
+	Key string `yaml:"key"`
+}
+
+const (
+	adminKeyMinSize = 32
+	// a random key of adminKeyMinSize characters has far more distinct ones
+	adminKeyMinDistinct = 8
+)
+
+// Validate rejects admin tokens that look like samples: the id must be a non-nil UUID,
+// the key a random secret of at least adminKeyMinSize characters.
+func (c AdminConfig) Validate() error {
+	ids := make(map[uuid.UUID]struct{}, len(c.Tokens))
+	for i, t := range c.Tokens {
+		id, err := uuid.Parse(t.ID)
+		if err != nil {
+			return fmt.Errorf("admin token #%d: invalid id %q: %w", i, t.ID, err)
+		}
+		if id == uuid.Nil {
+			return fmt.Errorf("admin token #%d: id %q is a placeholder", i, t.ID)
+		}
+		if _, ok := ids[id]; ok {
+			return fmt.Errorf("admin token #%d: duplicate id %q", i, t.ID)
+		}
+		ids[id] = struct{}{}
+
+		if len(t.Key) < adminKeyMinSize {
+			return fmt.Errorf("admin token %q: key must be at least %d characters", t.ID, adminKeyMinSize)
+		}
+		distinct := make(map[rune]struct{}, adminKeyMinDistinct)
+		for _, r := range t.Key {
+			distinct[r] = struct{}{}
+		}
+		if len(distinct) < adminKeyMinDistinct {
+			return fmt.Errorf("admin token %q: key is a placeholder, generate a random one", t.ID)
+		}
+	}
+	return nil
+}
+
+func (c *ConfigGroup) Default() {
+	c.ACME.Default()
+	c.SCEP.ChallengeTTL = 24 * time.Hour
+	c.Signature.ClockSkew = 5 * time.Minute
+	c.Signature.AllowV1 = true
+	c.CRL.DeltaInterval = 5 * time.Minute
+	c.OCSP.Delegated = true
+	c.OCSP.ResponderValidity = 72 * time.Hour
+	c.OCSP.LiveSignRate = 20
+	c.MTLS.Addr = "127.0.0.2:20443"
+	c.MTLS.Cert = "/var/lib/casper/tls.crt"
+	c.MTLS.Key = "/var/lib/casper/tls.key"
+	c.MTLS.Upstream = "http://127.0.0.2:20000"
+}
@@ -0,0 +1,37 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import "testing"
+
+func TestAdminConfigValidate(t *testing.T) {
+	const (
+		id  = "c958e408-d558-4964-aac1-960f815c0c2e"
+		key = "Ae8fL1pAB+83qaob3cQkX/bGHxDycUjW"
+	)
+
+	tests := []struct {
+		name    string
+		tokens  []AdminToken
+		wantErr bool
+	}{
+		{name: "no tokens"},
+		{name: "random token", tokens: []AdminToken{{ID: id, Key: key}}},
+		{name: "nil id", tokens: []AdminToken{{ID: "00000000-0000-0000-0000-000000000000", Key: key}}, wantErr: true},
+		{name: "invalid id", tokens: []AdminToken{{ID: "admin", Key: key}}, wantErr: true},
+		{name: "duplicate id", tokens: []AdminToken{{ID: id, Key: key}, {ID: id, Key: key}}, wantErr: true},
+		{name: "short key", tokens: []AdminToken{{ID: id, Key: key[:31]}}, wantErr: true},
+		{name: "placeholder key", tokens: []AdminToken{{ID: id, Key: "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AdminConfig{Tokens: tt.tokens}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
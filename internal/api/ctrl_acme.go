@@ -0,0 +1,915 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.osspkg.com/encrypt/pki"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+	"go.osspkg.com/routine/tick"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/access"
+	"go.arwos.org/casper/internal/pkgs/acme"
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+const (
+	acmePathDirectory  = "/acme/directory"
+	acmePathNewNonce   = "/acme/new-nonce"
+	acmePathNewAccount = "/acme/new-account"
+	acmePathNewOrder   = "/acme/new-order"
+	acmePathRevokeCert = "/acme/revoke-cert"
+	acmePathAccount    = "/acme/account"
+	acmePathOrder      = "/acme/order"
+	acmePathAuthz      = "/acme/authz"
+	acmePathChallenge  = "/acme/challenge"
+	acmePathFinalize   = "/acme/finalize"
+	acmePathCert       = "/acme/cert"
+)
+
+const (
+	acmeErrAccountDoesNotExist     = "accountDoesNotExist"
+	acmeErrAlreadyRevoked          = "alreadyRevoked"
+	acmeErrBadCSR                  = "badCSR"
+	acmeErrBadNonce                = "badNonce"
+	acmeErrBadRevocationReason     = "badRevocationReason"
+	acmeErrExternalAccountRequired = "externalAccountRequired"
+	acmeErrMalformed               = "malformed"
+	acmeErrOrderNotReady           = "orderNotReady"
+	acmeErrRejectedIdentifier      = "rejectedIdentifier"
+	acmeErrServerInternal          = "serverInternal"
+	acmeErrUnauthorized            = "unauthorized"
+	acmeErrUnsupportedIdentifier   = "unsupportedIdentifier"
+)
+
+const acmeMaxBodySize = 1 << 20
+
+func (v *API) addAcmeHandlers() {
+	v.apiRoute.Get(acmePathDirectory, v.AcmeDirectory)
+	v.apiRoute.Head(acmePathNewNonce, v.AcmeNewNonce)
+	v.apiRoute.Get(acmePathNewNonce, v.AcmeNewNonce)
+	v.apiRoute.Post(acmePathNewAccount, v.AcmeNewAccount)
+	v.apiRoute.Post(acmePathAccount, v.AcmeAccount)
+	v.apiRoute.Post(acmePathNewOrder, v.AcmeNewOrder)
+	v.apiRoute.Post(acmePathOrder, v.AcmeOrder)
+	v.apiRoute.Post(acmePathAuthz, v.AcmeAuthz)
+	v.apiRoute.Post(acmePathChallenge, v.AcmeChallenge)
+	v.apiRoute.Post(acmePathFinalize, v.AcmeFinalize)
+	v.apiRoute.Post(acmePathCert, v.AcmeCert)
+	v.apiRoute.Post(acmePathRevokeCert, v.AcmeRevokeCert)
+
+	logx.Info("Adding ACME directory", "url", v.acmeURL(acmePathDirectory, ""))
+}
+
+func (v *API) tickerConfigCleanAcme() tick.Config {
+	return tick.Config{
+		Name:     "clean acme orders",
+		OnStart:  false,
+		Interval: 10 * time.Minute,
+		Func: func(ctx context.Context, _ time.Time) error {
+			return v.entityRepo.DeleteAcmeOrderExpired(ctx)
+		},
+	}
+}
+
+func (v *API) acmeURL(path, id string) string {
+	uri := strings.TrimRight(v.conf.ACME.BaseURL, "/") + path
+	if len(id) > 0 {
+		uri += "?id=" + url.QueryEscape(id)
+	}
+	return uri
+}
+
+func (v *API) acmeAccountURL(id int64) string {
+	return v.acmeURL(acmePathAccount, strconv.FormatInt(id, 10))
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type (
+	acmeProblem struct {
+		Type   string `json:"type"`
+		Detail string `json:"detail,omitempty"`
+	}
+	acmeDirectory struct {
+		NewNonce   string            `json:"newNonce"`
+		NewAccount string            `json:"newAccount"`
+		NewOrder   string            `json:"newOrder"`
+		RevokeCert string            `json:"revokeCert"`
+		Meta       acmeDirectoryMeta `json:"meta"`
+	}
+	acmeDirectoryMeta struct {
+		ExternalAccountRequired bool `json:"externalAccountRequired"`
+	}
+	acmeAccountModel struct {
+		Status  string   `json:"status"`
+		Contact []string `json:"contact,omitempty"`
+	}
+	acmeOrderModel struct {
+		Status         string            `json:"status"`
+		Expires        string            `json:"expires"`
+		Identifiers    []acme.Identifier `json:"identifiers"`
+		Authorizations []string          `json:"authorizations"`
+		Finalize       string            `json:"finalize"`
+		Certificate    string            `json:"certificate,omitempty"`
+		Error          *acmeProblem      `json:"error,omitempty"`
+	}
+	acmeAuthzModel struct {
+		Status     string               `json:"status"`
+		Expires    string               `json:"expires"`
+		Identifier acme.Identifier      `json:"identifier"`
+		Challenges []acmeChallengeModel `json:"challenges"`
+		Wildcard   bool                 `json:"wildcard,omitempty"`
+	}
+	acmeChallengeModel struct {
+		Type      string       `json:"type"`
+		URL       string       `json:"url"`
+		Token     string       `json:"token"`
+		Status    string       `json:"status"`
+		Validated string       `json:"validated,omitempty"`
+		Error     *acmeProblem `json:"error,omitempty"`
+	}
+)
+
+func (v *API) acmeWrite(wc web.Ctx, code int, contentType string, body []byte) {
+	h := wc.Response().Header()
+	h.Set("Replay-Nonce", v.acmeNonces.New(time.Now()))
+	h.Set("Cache-Control", "no-store")
+	h.Add("Link", "<"+v.acmeURL(acmePathDirectory, "")+">;rel=\"index\"")
+	if len(contentType) > 0 {
+		h.Set("Content-Type", contentType)
+	}
+
+	wc.Response().WriteHeader(code)
+	if len(body) == 0 {
+		return
+	}
+	if _, err := wc.Response().Write(body); err != nil {
+		logx.Error("Failed to write ACME response", "err", err)
+	}
+}
+
+func (v *API) acmeJSON(wc web.Ctx, code int, obj any) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		logx.Error("Failed to encode ACME response", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	v.acmeWrite(wc, code, "application/json", b)
+}
+
+func (v *API) acmeError(wc web.Ctx, code int, typ, detail string) {
+	b, err := json.Marshal(acmeProblem{Type: "urn:ietf:params:acme:error:" + typ, Detail: detail})
+	if err != nil {
+		logx.Error("Failed to encode ACME problem", "err", err)
+	}
+	v.acmeWrite(wc, code, "application/problem+json", b)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type acmeRequest struct {
+	msg     *acme.Message
+	account *entity.AcmeAccount
+	auth    *entity.Auth
+}
+
+// acmeParse verifies nonce, url and signature of the request. When the request is signed
+// with a kid, the account and its linked auth record are loaded as well.
+func (v *API) acmeParse(wc web.Ctx, allowJWK bool) (*acmeRequest, bool) {
+	b, err := io.ReadAll(io.LimitReader(wc.Request().Body, acmeMaxBodySize))
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, "failed read request")
+		return nil, false
+	}
+
+	msg, err := acme.ParseJWS(b)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return nil, false
+	}
+
+	if ok, err := v.acmeUseNonce(wc.Context(), msg.Header.Nonce); err != nil {
+		logx.Error("failed to use acme nonce", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return nil, false
+	} else if !ok {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadNonce, "invalid or expired nonce")
+		return nil, false
+	}
+
+	if msg.Header.URL != v.acmeURL(wc.Request().URL.Path, wc.Request().URL.Query().Get("id")) {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "url header mismatch")
+		return nil, false
+	}
+
+	req := &acmeRequest{msg: msg}
+
+	if len(msg.Header.JWK) > 0 {
+		if !allowJWK {
+			v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, "request must be signed with kid")
+			return nil, false
+		}
+		pub, err := acme.ParseJWK(msg.Header.JWK)
+		if err != nil {
+			v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+			return nil, false
+		}
+		if err = msg.Verify(pub); err != nil {
+			v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, err.Error())
+			return nil, false
+		}
+		return req, true
+	}
+
+	prefix := v.acmeURL(acmePathAccount, "") + "?id="
+	id, err := strconv.ParseInt(strings.TrimPrefix(msg.Header.Kid, prefix), 10, 64)
+	if err != nil || !strings.HasPrefix(msg.Header.Kid, prefix) {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, "invalid kid")
+		return nil, false
+	}
+
+	accounts, err := v.entityRepo.SelectAcmeAccountByID(wc.Context(), id)
+	if err != nil {
+		logx.Error("failed to fetch acme account", "id", id, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return nil, false
+	}
+	if len(accounts) != 1 || accounts[0].Status != acme.StatusValid {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrAccountDoesNotExist, "")
+		return nil, false
+	}
+	req.account = &accounts[0]
+
+	pub, err := acme.ParseJWK([]byte(req.account.JWK))
+	if err != nil {
+		logx.Error("failed to decode acme account key", "id", id, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return nil, false
+	}
+	if err = msg.Verify(pub); err != nil {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, err.Error())
+		return nil, false
+	}
+
+	var ok bool
+	if req.auth, ok = v.acmeLinkedAuth(wc, req.account.Owner); !ok {
+		return nil, false
+	}
+
+	return req, true
+}
+
+func (v *API) acmeLinkedAuth(wc web.Ctx, id int64) (*entity.Auth, bool) {
+	auth, err := v.entityRepo.SelectAuthByID(wc.Context(), id)
+	if err != nil {
+		logx.Error("failed to fetch auth by id", "id", id, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return nil, false
+	}
+	if len(auth) != 1 || auth[0].Locked || len(auth[0].Domains) == 0 {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "linked account is locked")
+		return nil, false
+	}
+	return &auth[0], true
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (v *API) AcmeDirectory(wc web.Ctx) {
+	v.acmeJSON(wc, http.StatusOK, acmeDirectory{
+		NewNonce:   v.acmeURL(acmePathNewNonce, ""),
+		NewAccount: v.acmeURL(acmePathNewAccount, ""),
+		NewOrder:   v.acmeURL(acmePathNewOrder, ""),
+		RevokeCert: v.acmeURL(acmePathRevokeCert, ""),
+		Meta: acmeDirectoryMeta{
+			ExternalAccountRequired: true,
+		},
+	})
+}
+
+func (v *API) AcmeNewNonce(wc web.Ctx) {
+	code := http.StatusOK
+	if wc.Request().Method == http.MethodGet {
+		code = http.StatusNoContent
+	}
+	v.acmeWrite(wc, code, "", nil)
+}
+
+func (v *API) AcmeNewAccount(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, true)
+	if !ok {
+		return
+	}
+
+	payload := struct {
+		Contact                []string        `json:"contact"`
+		OnlyReturnExisting     bool            `json:"onlyReturnExisting"`
+		ExternalAccountBinding json.RawMessage `json:"externalAccountBinding"`
+	}{}
+	if err := json.Unmarshal(req.msg.Payload, &payload); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+
+	thumbprint, err := acme.Thumbprint(req.msg.Header.JWK)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+
+	exists, err := v.entityRepo.SelectAcmeAccountByThumbprint(wc.Context(), thumbprint)
+	if err != nil {
+		logx.Error("failed to fetch acme account by thumbprint", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if len(exists) == 1 {
+		wc.Response().Header().Set("Location", v.acmeAccountURL(exists[0].ID))
+		v.acmeJSON(wc, http.StatusOK, acmeAccountModel{Status: exists[0].Status, Contact: exists[0].Contacts})
+		return
+	}
+	if payload.OnlyReturnExisting {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrAccountDoesNotExist, "")
+		return
+	}
+
+	if len(payload.ExternalAccountBinding) == 0 {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrExternalAccountRequired,
+			"use auth token id as eab kid and base64url encoded auth token key as eab hmac key")
+		return
+	}
+
+	eab, err := acme.ParseJWS(payload.ExternalAccountBinding)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+	eabThumbprint, err := acme.Thumbprint(eab.Payload)
+	if err != nil || eabThumbprint != thumbprint || eab.Header.URL != req.msg.Header.URL {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "external account binding does not match request")
+		return
+	}
+
+	tokenId, err := uuid.Parse(eab.Header.Kid)
+	if err != nil {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "invalid external account kid")
+		return
+	}
+	auth, err := v.entityRepo.SelectAuthByTokenId(wc.Context(), tokenId)
+	if err != nil {
+		logx.Error("failed to fetch auth by token id", "id", tokenId, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if len(auth) != 1 || auth[0].Locked || len(auth[0].Domains) == 0 {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "external account not found")
+		return
+	}
+	if err = eab.VerifyHMAC([]byte(auth[0].TokenKey)); err != nil {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "invalid external account binding signature")
+		return
+	}
+
+	account := entity.AcmeAccount{
+		Owner:      auth[0].ID,
+		Thumbprint: thumbprint,
+		JWK:        string(req.msg.Header.JWK),
+		Contacts:   payload.Contact,
+		Status:     acme.StatusValid,
+	}
+	if account.Contacts == nil {
+		account.Contacts = []string{}
+	}
+	if err = v.entityRepo.CreateAcmeAccount(wc.Context(), &account); err != nil {
+		logx.Error("failed to create acme account", "owner", auth[0].ID, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+
+	logx.Info("ACME account created", "id", account.ID, "owner", account.Owner)
+
+	wc.Response().Header().Set("Location", v.acmeAccountURL(account.ID))
+	v.acmeJSON(wc, http.StatusCreated, acmeAccountModel{Status: account.Status, Contact: account.Contacts})
+}
+
+func (v *API) AcmeAccount(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	if wc.Request().URL.Query().Get("id") != strconv.FormatInt(req.account.ID, 10) {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized, "account mismatch")
+		return
+	}
+
+	if len(req.msg.Payload) > 0 {
+		payload := struct {
+			Contact []string `json:"contact"`
+			Status  string   `json:"status"`
+		}{}
+		if err := json.Unmarshal(req.msg.Payload, &payload); err != nil {
+			v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+			return
+		}
+		if payload.Contact != nil {
+			req.account.Contacts = payload.Contact
+		}
+		if payload.Status == acme.StatusDeactivated {
+			req.account.Status = acme.StatusDeactivated
+		}
+		if err := v.entityRepo.UpdateAcmeAccountByID(wc.Context(), req.account); err != nil {
+			logx.Error("failed to update acme account", "id", req.account.ID, "err", err)
+			v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+			return
+		}
+	}
+
+	v.acmeJSON(wc, http.StatusOK, acmeAccountModel{Status: req.account.Status, Contact: req.account.Contacts})
+}
+
+func (v *API) AcmeNewOrder(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	payload := struct {
+		Identifiers []acme.Identifier `json:"identifiers"`
+	}{}
+	if err := json.Unmarshal(req.msg.Payload, &payload); err != nil || len(payload.Identifiers) == 0 {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, "require identifiers")
+		return
+	}
+
+	names := make([]string, 0, len(payload.Identifiers))
+	for i, ident := range payload.Identifiers {
+		if ident.Type != "dns" {
+			v.acmeError(wc, http.StatusBadRequest, acmeErrUnsupportedIdentifier, ident.Type)
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(ident.Value))
+		if !isValidDNSName(name) {
+			v.acmeError(wc, http.StatusBadRequest, acmeErrRejectedIdentifier, "invalid domain: "+ident.Value)
+			return
+		}
+		payload.Identifiers[i].Value = name
+		names = append(names, name)
+	}
+
+	rules, err := access.Parse(req.auth.Domains)
+	if err != nil {
+		logx.Error("failed to parse user access domains", "owner", req.auth.ID, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+
+	if denied := rules.Check(names...); len(denied) > 0 {
+		v.acmeError(wc, http.StatusForbidden, acmeErrRejectedIdentifier, strings.Join(denied, "; "))
+		return
+	}
+
+	if _, err = v.getRootCertificate(names); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrRejectedIdentifier, err.Error())
+		return
+	}
+
+	expires := time.Now().Add(v.conf.ACME.OrderTTL)
+
+	order := acme.Order{
+		ID:          acme.NewID(),
+		AccountID:   req.account.ID,
+		Status:      acme.StatusPending,
+		Expires:     expires,
+		Identifiers: payload.Identifiers,
+	}
+
+	authzs := make([]acme.Authz, 0, len(payload.Identifiers))
+	for _, ident := range payload.Identifiers {
+		authz := acme.Authz{
+			ID:         acme.NewID(),
+			AccountID:  req.account.ID,
+			Identifier: ident,
+			Status:     acme.StatusPending,
+			Expires:    expires,
+			Challenges: []acme.Challenge{
+				{ID: acme.NewID(), Type: acme.ChallengeHTTP01, Token: acme.NewID(), Status: acme.StatusPending},
+				{ID: acme.NewID(), Type: acme.ChallengeDNS01, Token: acme.NewID(), Status: acme.StatusPending},
+			},
+		}
+		// RFC 8555 7.1.3: wildcard authorization carries the base domain and only dns-01
+		if certs.IsWildcard(ident.Value) {
+			authz.Wildcard = true
+			authz.Identifier.Value = certs.TrimWildcard(ident.Value)
+			authz.Challenges = authz.Challenges[1:]
+		}
+		authzs = append(authzs, authz)
+	}
+
+	order, err = v.acmeCreateOrder(wc.Context(), order, authzs)
+	if err != nil {
+		logx.Error("failed to create acme order", "account", req.account.ID, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	order.Refresh(authzs, time.Now())
+
+	wc.Response().Header().Set("Location", v.acmeURL(acmePathOrder, order.ID))
+	v.acmeJSON(wc, http.StatusCreated, v.acmeOrderModel(order))
+}
+
+func (v *API) AcmeOrder(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	order, ok, err := v.acmeOrder(wc.Context(), wc.Request().URL.Query().Get("id"))
+	if err != nil {
+		logx.Error("failed to fetch acme order", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !ok || order.AccountID != req.account.ID {
+		v.acmeError(wc, http.StatusNotFound, acmeErrMalformed, "order not found")
+		return
+	}
+
+	v.acmeJSON(wc, http.StatusOK, v.acmeOrderModel(order))
+}
+
+func (v *API) AcmeAuthz(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	authz, ok, err := v.acmeAuthz(wc.Context(), wc.Request().URL.Query().Get("id"))
+	if err != nil {
+		logx.Error("failed to fetch acme authorization", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !ok || authz.AccountID != req.account.ID {
+		v.acmeError(wc, http.StatusNotFound, acmeErrMalformed, "authorization not found")
+		return
+	}
+
+	v.acmeJSON(wc, http.StatusOK, v.acmeAuthzModel(authz))
+}
+
+func (v *API) AcmeChallenge(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	id := wc.Request().URL.Query().Get("id")
+	ch, authz, ok, err := v.acmeChallenge(wc.Context(), id)
+	if err != nil {
+		logx.Error("failed to fetch acme challenge", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !ok || authz.AccountID != req.account.ID {
+		v.acmeError(wc, http.StatusNotFound, acmeErrMalformed, "challenge not found")
+		return
+	}
+
+	if ch.Status == acme.StatusPending && authz.Status == acme.StatusPending {
+		ctx, cancel := context.WithTimeout(wc.Context(), v.conf.ACME.ValidationTimeout)
+		keyAuth := acme.KeyAuthorization(ch.Token, req.account.Thumbprint)
+		err = v.acmeValidator.Validate(ctx, ch.Type, authz.Identifier.Value, ch.Token, keyAuth)
+		cancel()
+
+		status, errMsg, validated := acme.StatusValid, "", time.Now()
+		if err != nil {
+			logx.Warn("ACME challenge failed", "type", ch.Type, "domain", authz.Identifier.Value, "err", err)
+			status, errMsg, validated = acme.StatusInvalid, err.Error(), time.Time{}
+		}
+
+		if err = v.entityRepo.UpdateAcmeChallengeResult(wc.Context(), id, status, errMsg, validated); err != nil {
+			logx.Error("failed to store acme challenge result", "id", id, "err", err)
+			v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+			return
+		}
+		if ch, _, _, err = v.acmeChallenge(wc.Context(), id); err != nil {
+			logx.Error("failed to fetch acme challenge", "id", id, "err", err)
+			v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+			return
+		}
+	}
+
+	wc.Response().Header().Add("Link", "<"+v.acmeURL(acmePathAuthz, authz.ID)+">;rel=\"up\"")
+	v.acmeJSON(wc, http.StatusOK, v.acmeChallengeModel(ch))
+}
+
+func (v *API) AcmeFinalize(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	order, ok, err := v.acmeOrder(wc.Context(), wc.Request().URL.Query().Get("id"))
+	if err != nil {
+		logx.Error("failed to fetch acme order", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !ok || order.AccountID != req.account.ID {
+		v.acmeError(wc, http.StatusNotFound, acmeErrMalformed, "order not found")
+		return
+	}
+	if order.Status != acme.StatusReady {
+		v.acmeError(wc, http.StatusForbidden, acmeErrOrderNotReady, "order status is "+order.Status)
+		return
+	}
+
+	payload := struct {
+		CSR string `json:"csr"`
+	}{}
+	if err = json.Unmarshal(req.msg.Payload, &payload); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+
+	der, err := acme.DecodeBase64(payload.CSR)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, err.Error())
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, err.Error())
+		return
+	}
+	if err = csr.CheckSignature(); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, err.Error())
+		return
+	}
+
+	if err = v.validateCRS(csr); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, err.Error())
+		return
+	}
+	if len(csr.URIs) > 0 || len(csr.EmailAddresses) > 0 {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, "only DNS names are supported")
+		return
+	}
+
+	want := make([]string, 0, len(order.Identifiers))
+	for _, ident := range order.Identifiers {
+		want = append(want, ident.Value)
+	}
+	got := make([]string, 0, len(csr.DNSNames))
+	for _, name := range csr.DNSNames {
+		got = append(got, strings.ToLower(name))
+	}
+	slices.Sort(want)
+	slices.Sort(got)
+	if !slices.Equal(slices.Compact(want), slices.Compact(got)) {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, "csr names do not match order identifiers")
+		return
+	}
+
+	// account grants may have changed since the order was created
+	rules, err := access.Parse(req.auth.Domains)
+	if err != nil {
+		logx.Error("failed to parse user access domains", "owner", req.auth.ID, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if denied := rules.Check(got...); len(denied) > 0 {
+		v.acmeError(wc, http.StatusForbidden, acmeErrUnauthorized, strings.Join(denied, "; "))
+		return
+	}
+
+	ca, err := v.getRootCertificate(csr.DNSNames)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, err.Error())
+		return
+	}
+
+	profile, err := v.getProfile("", req.auth.Profiles)
+	if err != nil {
+		v.acmeError(wc, http.StatusForbidden, acmeErrUnauthorized, err.Error())
+		return
+	}
+
+	// only the request moving the order to processing issues, a concurrent finalize gets orderNotReady
+	claimed, err := v.entityRepo.ClaimAcmeOrder(wc.Context(), order.ID,
+		[]string{acme.StatusPending, acme.StatusReady}, acme.StatusProcessing)
+	if err != nil {
+		logx.Error("failed to claim acme order", "id", order.ID, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !claimed {
+		v.acmeError(wc, http.StatusForbidden, acmeErrOrderNotReady, "order is already finalized")
+		return
+	}
+
+	result, err := v.issueCertificate(wc.Context(), req.account.Owner, ca, profile, csr, true)
+	if msg, ok := policyError(err); ok {
+		order.Status, order.Error = acme.StatusInvalid, msg
+		v.acmeStoreOrderResult(wc, order)
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadCSR, msg)
+		return
+	}
+	if err != nil {
+		logx.Error("failed to issue acme certificate", "domains", csr.DNSNames, "err", err)
+		order.Status, order.Error = acme.StatusInvalid, "failed to issue certificate"
+		v.acmeStoreOrderResult(wc, order)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if result.Status != client.RenewalStatusIssued {
+		order.Status, order.Error = acme.StatusInvalid, "domain is owned by another account"
+		v.acmeStoreOrderResult(wc, order)
+		v.acmeError(wc, http.StatusForbidden, acmeErrUnauthorized, order.Error)
+		return
+	}
+
+	order.Status = acme.StatusValid
+	order.SerialNumber = result.SerialNumber
+	order.CertPEM = []byte(result.Cert + strings.Join(result.CA, ""))
+	if !v.acmeStoreOrderResult(wc, order) {
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+
+	wc.Response().Header().Set("Location", v.acmeURL(acmePathOrder, order.ID))
+	v.acmeJSON(wc, http.StatusOK, v.acmeOrderModel(order))
+}
+
+func (v *API) AcmeCert(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, false)
+	if !ok {
+		return
+	}
+
+	order, ok, err := v.acmeOrder(wc.Context(), wc.Request().URL.Query().Get("id"))
+	if err != nil {
+		logx.Error("failed to fetch acme order", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if !ok || order.AccountID != req.account.ID || order.Status != acme.StatusValid {
+		v.acmeError(wc, http.StatusNotFound, acmeErrMalformed, "certificate not found")
+		return
+	}
+
+	v.acmeWrite(wc, http.StatusOK, "application/pem-certificate-chain", order.CertPEM)
+}
+
+func (v *API) AcmeRevokeCert(wc web.Ctx) {
+	req, ok := v.acmeParse(wc, true)
+	if !ok {
+		return
+	}
+	if req.account == nil {
+		v.acmeError(wc, http.StatusUnauthorized, acmeErrUnauthorized,
+			"revocation with certificate key is not supported, sign with account key")
+		return
+	}
+
+	payload := struct {
+		Certificate string `json:"certificate"`
+		Reason      int64  `json:"reason"`
+	}{}
+	if err := json.Unmarshal(req.msg.Payload, &payload); err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+
+	if !slices.Contains(slices.Collect(maps.Values(client.RevocationReasons)), payload.Reason) {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrBadRevocationReason, "")
+		return
+	}
+
+	der, err := acme.DecodeBase64(payload.Certificate)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+	fp, err := (&pki.Certificate{Crt: crt}).FingerPrint(entity.Hash)
+	if err != nil {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrMalformed, err.Error())
+		return
+	}
+
+	list, err := v.entityRepo.SelectCertByFingerPrint(wc.Context(), hex.EncodeToString(fp))
+	if err != nil {
+		logx.Error("failed to fetch cert by fingerprint", "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+	if len(list) != 1 || list[0].Owner != req.account.Owner {
+		v.acmeError(wc, http.StatusForbidden, acmeErrUnauthorized, "certificate belongs to another account")
+		return
+	}
+	if list[0].Revoked {
+		v.acmeError(wc, http.StatusBadRequest, acmeErrAlreadyRevoked, "")
+		return
+	}
+
+	if err = v.entityRepo.UpdateCertsAsRevoked(wc.Context(),
+		req.account.Owner, []string{list[0].SerialNumber}, payload.Reason,
+	); err != nil {
+		logx.Error("failed to revoke certificate", "serial", list[0].SerialNumber, "err", err)
+		v.acmeError(wc, http.StatusInternalServerError, acmeErrServerInternal, "")
+		return
+	}
+
+	v.refreshOCSP()
+
+	logx.Info("Certificate revoked", "serial", list[0].SerialNumber, "owner", req.account.Owner, "reason", payload.Reason)
+
+	v.acmeWrite(wc, http.StatusOK, "", nil)
+}
+
+func (v *API) acmeStoreOrderResult(wc web.Ctx, o acme.Order) bool {
+	if err := v.acmeFinishOrder(wc.Context(), o); err != nil {
+		logx.Error("failed to store acme order result", "id", o.ID, "status", o.Status, "err", err)
+		return false
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (v *API) acmeOrderModel(o acme.Order) acmeOrderModel {
+	m := acmeOrderModel{
+		Status:         o.Status,
+		Expires:        o.Expires.UTC().Format(time.RFC3339),
+		Identifiers:    o.Identifiers,
+		Authorizations: make([]string, 0, len(o.AuthzIDs)),
+		Finalize:       v.acmeURL(acmePathFinalize, o.ID),
+	}
+	for _, id := range o.AuthzIDs {
+		m.Authorizations = append(m.Authorizations, v.acmeURL(acmePathAuthz, id))
+	}
+	if o.Status == acme.StatusValid {
+		m.Certificate = v.acmeURL(acmePathCert, o.ID)
+	}
+	if len(o.Error) > 0 {
+		m.Error = &acmeProblem{Type: "urn:ietf:params:acme:error:" + acmeErrUnauthorized, Detail: o.Error}
+	}
+	return m
+}
+
+func (v *API) acmeAuthzModel(a acme.Authz) acmeAuthzModel {
+	m := acmeAuthzModel{
+		Status:     a.Status,
+		Expires:    a.Expires.UTC().Format(time.RFC3339),
+		Identifier: a.Identifier,
+		Challenges: make([]acmeChallengeModel, 0, len(a.Challenges)),
+		Wildcard:   a.Wildcard,
+	}
+	for _, ch := range a.Challenges {
+		m.Challenges = append(m.Challenges, v.acmeChallengeModel(ch))
+	}
+	return m
+}
+
+func (v *API) acmeChallengeModel(ch acme.Challenge) acmeChallengeModel {
+	m := acmeChallengeModel{
+		Type:   ch.Type,
+		URL:    v.acmeURL(acmePathChallenge, ch.ID),
+		Token:  ch.Token,
+		Status: ch.Status,
+	}
+	if !ch.Validated.IsZero() {
+		m.Validated = ch.Validated.UTC().Format(time.RFC3339)
+	}
+	if len(ch.Error) > 0 {
+		m.Error = &acmeProblem{Type: "urn:ietf:params:acme:error:" + acmeErrUnauthorized, Detail: ch.Error}
+	}
+	return m
+}
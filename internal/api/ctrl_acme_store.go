@@ -0,0 +1,204 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/acme"
+)
+
+// ACME orders, authorizations and challenges are kept in the database, so any instance
+// behind the base url continues an order and a restart keeps the pending ones.
+
+// acmeUseNonce verifies the nonce and records its use, a nonce is accepted once by all instances.
+func (v *API) acmeUseNonce(ctx context.Context, nonce string) (bool, error) {
+	expires, err := v.acmeNonces.Verify(nonce, time.Now())
+	if err != nil {
+		return false, nil
+	}
+	model := entity.RequestNonce{
+		Nonce:     "acme:" + nonce,
+		ExpiresAt: expires,
+	}
+	if err = v.entityRepo.CreateRequestNonce(ctx, &model, entity.ConflictIgnore()); err != nil {
+		return false, fmt.Errorf("failed to save acme nonce: %w", err)
+	}
+	return model.ID != 0, nil
+}
+
+func (v *API) acmeCreateOrder(ctx context.Context, o acme.Order, authzs []acme.Authz) (acme.Order, error) {
+	model := entity.AcmeOrder{
+		OrderId:     o.ID,
+		AccountId:   o.AccountID,
+		Status:      o.Status,
+		Identifiers: make([]string, 0, len(o.Identifiers)),
+		CertPEM:     []byte{},
+		ExpiresAt:   o.Expires,
+	}
+	for _, ident := range o.Identifiers {
+		model.Identifiers = append(model.Identifiers, ident.Value)
+	}
+	if err := v.entityRepo.CreateAcmeOrder(ctx, &model); err != nil {
+		return o, fmt.Errorf("failed to create acme order: %w", err)
+	}
+
+	az := make([]*entity.AcmeAuthz, 0, len(authzs))
+	chs := make([]*entity.AcmeChallenge, 0, len(authzs)*2)
+	for _, a := range authzs {
+		az = append(az, &entity.AcmeAuthz{
+			AuthzId:    a.ID,
+			OrderId:    o.ID,
+			AccountId:  a.AccountID,
+			Identifier: a.Identifier.Value,
+			Wildcard:   a.Wildcard,
+			Status:     a.Status,
+			ExpiresAt:  a.Expires,
+		})
+		for _, ch := range a.Challenges {
+			chs = append(chs, &entity.AcmeChallenge{
+				ChallengeId: ch.ID,
+				AuthzId:     a.ID,
+				Type:        ch.Type,
+				Token:       ch.Token,
+				Status:      ch.Status,
+			})
+		}
+		o.AuthzIDs = append(o.AuthzIDs, a.ID)
+	}
+
+	err := v.entityRepo.CreateBulkAcmeAuthz(ctx, az)
+	if err == nil {
+		err = v.entityRepo.CreateBulkAcmeChallenge(ctx, chs)
+	}
+	if err != nil {
+		// the order is not returned to the client yet, authorizations go by cascade
+		if e := v.entityRepo.DeleteAcmeOrderByOrderId(ctx, o.ID); e != nil {
+			err = fmt.Errorf("%w, and failed to delete it: %w", err, e)
+		}
+		return o, fmt.Errorf("failed to create acme authorizations: %w", err)
+	}
+
+	return o, nil
+}
+
+// acmeOrder returns the order with its status recalculated from the authorizations.
+func (v *API) acmeOrder(ctx context.Context, id string) (acme.Order, bool, error) {
+	list, err := v.entityRepo.SelectAcmeOrderByOrderId(ctx, id)
+	if err != nil || len(list) != 1 {
+		return acme.Order{}, false, err
+	}
+	m := list[0]
+
+	authzs, err := v.entityRepo.SelectAcmeAuthzByOrderId(ctx, id)
+	if err != nil {
+		return acme.Order{}, false, err
+	}
+	slices.SortFunc(authzs, func(a, b entity.AcmeAuthz) int { return cmp.Compare(a.ID, b.ID) })
+
+	o := acme.Order{
+		ID:           m.OrderId,
+		AccountID:    m.AccountId,
+		Status:       m.Status,
+		Expires:      m.ExpiresAt,
+		Identifiers:  make([]acme.Identifier, 0, len(m.Identifiers)),
+		AuthzIDs:     make([]string, 0, len(authzs)),
+		Error:        m.Error,
+		SerialNumber: m.SerialNumber,
+		CertPEM:      m.CertPEM,
+	}
+	for _, name := range m.Identifiers {
+		o.Identifiers = append(o.Identifiers, acme.Identifier{Type: "dns", Value: name})
+	}
+	states := make([]acme.Authz, 0, len(authzs))
+	for _, a := range authzs {
+		o.AuthzIDs = append(o.AuthzIDs, a.AuthzId)
+		states = append(states, acmeAuthzFromEntity(a, nil))
+	}
+	o.Refresh(states, time.Now())
+
+	return o, true, nil
+}
+
+// acmeFinishOrder stores the result of the order claimed for processing.
+func (v *API) acmeFinishOrder(ctx context.Context, o acme.Order) error {
+	list, err := v.entityRepo.SelectAcmeOrderByOrderId(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if len(list) != 1 {
+		return fmt.Errorf("failed to find acme order %s", o.ID)
+	}
+	m := list[0]
+	m.Status = o.Status
+	m.Error = o.Error
+	m.SerialNumber = o.SerialNumber
+	m.CertPEM = o.CertPEM
+	if m.CertPEM == nil {
+		m.CertPEM = []byte{}
+	}
+	return v.entityRepo.UpdateAcmeOrderByID(ctx, &m)
+}
+
+func (v *API) acmeAuthz(ctx context.Context, id string) (acme.Authz, bool, error) {
+	list, err := v.entityRepo.SelectAcmeAuthzByAuthzId(ctx, id)
+	if err != nil || len(list) != 1 {
+		return acme.Authz{}, false, err
+	}
+	chs, err := v.entityRepo.SelectAcmeChallengeByAuthzId(ctx, id)
+	if err != nil {
+		return acme.Authz{}, false, err
+	}
+	return acmeAuthzFromEntity(list[0], chs), true, nil
+}
+
+// acmeChallenge returns the challenge and the authorization it belongs to.
+func (v *API) acmeChallenge(ctx context.Context, id string) (acme.Challenge, acme.Authz, bool, error) {
+	list, err := v.entityRepo.SelectAcmeChallengeByChallengeId(ctx, id)
+	if err != nil || len(list) != 1 {
+		return acme.Challenge{}, acme.Authz{}, false, err
+	}
+	authz, ok, err := v.acmeAuthz(ctx, list[0].AuthzId)
+	if err != nil || !ok {
+		return acme.Challenge{}, acme.Authz{}, false, err
+	}
+	for _, ch := range authz.Challenges {
+		if ch.ID == id {
+			return ch, authz, true, nil
+		}
+	}
+	return acme.Challenge{}, acme.Authz{}, false, nil
+}
+
+func acmeAuthzFromEntity(m entity.AcmeAuthz, chs []entity.AcmeChallenge) acme.Authz {
+	slices.SortFunc(chs, func(a, b entity.AcmeChallenge) int { return cmp.Compare(a.ID, b.ID) })
+
+	a := acme.Authz{
+		ID:         m.AuthzId,
+		AccountID:  m.AccountId,
+		Identifier: acme.Identifier{Type: "dns", Value: m.Identifier},
+		Wildcard:   m.Wildcard,
+		Status:     m.Status,
+		Expires:    m.ExpiresAt,
+		Challenges: make([]acme.Challenge, 0, len(chs)),
+	}
+	for _, ch := range chs {
+		a.Challenges = append(a.Challenges, acme.Challenge{
+			ID:        ch.ChallengeId,
+			Type:      ch.Type,
+			Token:     ch.Token,
+			Status:    ch.Status,
+			Validated: ch.ValidatedAt,
+			Error:     ch.Error,
+		})
+	}
+	return a
+}
@@ -0,0 +1,450 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/access"
+)
+
+const (
+	adminRequestCtx apiCtx = "admin_request"
+
+	adminDefaultLimit = 100
+	adminMaxLimit     = 1000
+)
+
+func (v *API) addAdminHandlers() {
+	if len(v.conf.Admin.Tokens) == 0 {
+		logx.Warn("Admin API is disabled, no admin tokens configured")
+		return
+	}
+
+	v.apiRoute.Post(client.PathAdminAccountCreateV1, v.AdminAccountCreateV1)
+	v.apiRoute.Post(client.PathAdminAccountListV1, v.AdminAccountListV1)
+	v.apiRoute.Post(client.PathAdminAccountShowV1, v.AdminAccountShowV1)
+	v.apiRoute.Post(client.PathAdminAccountUpdateV1, v.AdminAccountUpdateV1)
+	v.apiRoute.Post(client.PathAdminAccountDeleteV1, v.AdminAccountDeleteV1)
+
+	if v.conf.SCEP.Enabled {
+		v.apiRoute.Post(client.PathAdminScepChallengeV1, v.AdminScepChallengeV1)
+	}
+}
+
+// adminValidate authenticates admin routes with the tokens from the server config,
+// account tokens from the database are never accepted here.
+func (v *API) adminValidate() web.Middleware {
+	return func(next func(web.Ctx)) func(web.Ctx) {
+		return func(wc web.Ctx) {
+			if !strings.HasPrefix(wc.Request().URL.Path, client.PathAdminPrefix) {
+				next(wc)
+				return
+			}
+
+			sr, ok := decodeSignedRequest(wc)
+			if !ok {
+				return
+			}
+
+			idx := slices.IndexFunc(v.conf.Admin.Tokens, func(t AdminToken) bool {
+				return t.ID == sr.ID.String()
+			})
+			if idx < 0 || len(v.conf.Admin.Tokens[idx].Key) == 0 {
+				wc.ErrorJSON(http.StatusForbidden, errForbidden,
+					"authorization", "admin not found", "id", sr.ID)
+				return
+			}
+
+			if !v.verifySignedRequest(wc, sr, v.conf.Admin.Tokens[idx].Key) {
+				return
+			}
+
+			wc.SetContextValue(adminRequestCtx, &sr.Body)
+
+			next(wc)
+		}
+	}
+}
+
+func generateTokenKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func normalizeDomains(domains []string) ([]string, error) {
+	result := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if len(strings.TrimSpace(domain)) == 0 {
+			continue
+		}
+		rule, err := access.NormalizeRule(domain)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(result, rule) {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+func (v *API) normalizeProfiles(profiles []string) ([]string, error) {
+	result := make([]string, 0, len(profiles))
+	for _, name := range profiles {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 || slices.Contains(result, name) {
+			continue
+		}
+		if _, ok := v.certStore.Profile(name); !ok {
+			return nil, fmt.Errorf("unknown profile: %s", name)
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+func accountModel(m entity.Auth) client.AccountModel {
+	return client.AccountModel{
+		ID:        m.ID,
+		TokenID:   m.TokenId.String(),
+		Domains:   m.Domains,
+		Profiles:  m.Profiles,
+		Locked:    m.Locked,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func (v *API) adminRequest(wc web.Ctx, out any) bool {
+	req, ok := wc.GetContextValue(adminRequestCtx).(*[]byte)
+	if !ok || req == nil || len(*req) == 0 {
+		logx.Error("failed to fetch admin request")
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest)
+		return false
+	}
+
+	if err := json.Unmarshal(*req, out); err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "unmarshal request", "err", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func (v *API) adminFindAccount(ctx context.Context, wc web.Ctx, tokenId string) (*entity.Auth, bool) {
+	id, err := uuid.Parse(tokenId)
+	if err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", "invalid token id")
+		return nil, false
+	}
+
+	auth, err := v.entityRepo.SelectAuthByTokenId(ctx, id)
+	if err != nil {
+		logx.Error("failed to fetch auth by token id", "id", id, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return nil, false
+	}
+
+	if len(auth) != 1 {
+		wc.ErrorJSON(http.StatusNotFound, errNotFound,
+			"request", "account not found", "id", id)
+		return nil, false
+	}
+
+	return &auth[0], true
+}
+
+func (v *API) AdminAccountCreateV1(wc web.Ctx) {
+	req := client.AccountRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	domains, err := normalizeDomains(req.Domains)
+	if err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", err.Error())
+		return
+	}
+	if len(domains) == 0 {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", "require domains")
+		return
+	}
+
+	profiles, err := v.normalizeProfiles(req.Profiles)
+	if err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", err.Error())
+		return
+	}
+
+	key, err := generateTokenKey()
+	if err != nil {
+		logx.Error("failed to create account", "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	model := entity.Auth{
+		TokenId:  uuid.New(),
+		TokenKey: key,
+		Domains:  domains,
+		Profiles: profiles,
+		Locked:   req.Locked != nil && *req.Locked,
+	}
+
+	if err = v.entityRepo.CreateAuth(wc.Context(), &model); err != nil {
+		logx.Error("failed to create account", "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("Account created", "id", model.ID, "token_id", model.TokenId)
+
+	resp := accountModel(model)
+	resp.TokenKey = model.TokenKey
+	wc.JSON(http.StatusOK, &resp)
+}
+
+func (v *API) AdminAccountListV1(wc web.Ctx) {
+	req := client.AccountsRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = adminDefaultLimit
+	}
+	if req.Limit > adminMaxLimit {
+		req.Limit = adminMaxLimit
+	}
+
+	list, err := v.entityRepo.SelectAuthCursor(wc.Context(), req.Cursor, req.Limit)
+	if err != nil {
+		logx.Error("failed to fetch accounts", "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	resp := client.AccountsModel{
+		Accounts: make([]client.AccountModel, 0, len(list)),
+	}
+	for _, item := range list {
+		resp.Accounts = append(resp.Accounts, accountModel(item))
+	}
+	if uint(len(list)) == req.Limit {
+		resp.NextCursor = list[len(list)-1].ID
+	}
+
+	wc.JSON(http.StatusOK, &resp)
+}
+
+func (v *API) AdminAccountShowV1(wc web.Ctx) {
+	req := client.AccountRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	auth, ok := v.adminFindAccount(wc.Context(), wc, req.TokenID)
+	if !ok {
+		return
+	}
+
+	resp := accountModel(*auth)
+	wc.JSON(http.StatusOK, &resp)
+}
+
+func (v *API) AdminAccountUpdateV1(wc web.Ctx) {
+	req := client.AccountRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	auth, ok := v.adminFindAccount(wc.Context(), wc, req.TokenID)
+	if !ok {
+		return
+	}
+
+	if req.Domains != nil {
+		domains, err := normalizeDomains(req.Domains)
+		if err != nil {
+			wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+				"request", "validate", "err", err.Error())
+			return
+		}
+		if len(domains) == 0 {
+			wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+				"request", "validate", "err", "require domains")
+			return
+		}
+		auth.Domains = domains
+	}
+
+	if req.Profiles != nil {
+		profiles, err := v.normalizeProfiles(req.Profiles)
+		if err != nil {
+			wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+				"request", "validate", "err", err.Error())
+			return
+		}
+		auth.Profiles = profiles
+	}
+
+	if req.Locked != nil {
+		auth.Locked = *req.Locked
+	}
+
+	if req.RotateKey {
+		key, err := generateTokenKey()
+		if err != nil {
+			logx.Error("failed to rotate key", "id", auth.ID, "err", err)
+			wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+			return
+		}
+		auth.TokenKey = key
+	}
+
+	if err := v.entityRepo.UpdateAuthByID(wc.Context(), auth); err != nil {
+		logx.Error("failed to update account", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("Account updated", "id", auth.ID, "token_id", auth.TokenId,
+		"locked", auth.Locked, "domains", auth.Domains, "profiles", auth.Profiles, "rotate_key", req.RotateKey)
+
+	resp := accountModel(*auth)
+	if req.RotateKey {
+		resp.TokenKey = auth.TokenKey
+	}
+	wc.JSON(http.StatusOK, &resp)
+}
+
+// AdminAccountDeleteV1 deletes an account without certificates. Deleting the account would
+// cascade to its certificates and drop the revoked ones from CRLs and all of them from OCSP,
+// so while any are left the account is locked and its key replaced instead.
+// Deleting it again after the certificates have expired removes it.
+func (v *API) AdminAccountDeleteV1(wc web.Ctx) {
+	req := client.AccountRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	auth, ok := v.adminFindAccount(wc.Context(), wc, req.TokenID)
+	if !ok {
+		return
+	}
+
+	hasCerts, err := v.entityRepo.SelectCertExistsByOwner(wc.Context(), auth.ID)
+	if err != nil {
+		logx.Error("failed to check account certs", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	if hasCerts {
+		v.adminLockAccount(wc, auth)
+		return
+	}
+
+	if err = v.entityRepo.DeleteAuthByID(wc.Context(), auth.ID); err != nil {
+		logx.Error("failed to delete account", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("Account deleted", "id", auth.ID, "token_id", auth.TokenId)
+
+	resp := accountModel(*auth)
+	resp.Deleted = true
+	wc.JSON(http.StatusOK, &resp)
+}
+
+func (v *API) adminLockAccount(wc web.Ctx, auth *entity.Auth) {
+	key, err := generateTokenKey()
+	if err != nil {
+		logx.Error("failed to replace key", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	auth.Locked = true
+	auth.TokenKey = key
+
+	if err = v.entityRepo.UpdateAuthByID(wc.Context(), auth); err != nil {
+		logx.Error("failed to lock account", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("Account locked instead of deleted, it has certificates", "id", auth.ID, "token_id", auth.TokenId)
+
+	resp := accountModel(*auth)
+	wc.JSON(http.StatusOK, &resp)
+}
+
+// AdminScepChallengeV1 creates a one-time SCEP challenge password for the account,
+// only the hash of the secret is stored.
+func (v *API) AdminScepChallengeV1(wc web.Ctx) {
+	req := client.ScepChallengeRequest{}
+	if !v.adminRequest(wc, &req) {
+		return
+	}
+
+	auth, ok := v.adminFindAccount(wc.Context(), wc, req.TokenID)
+	if !ok {
+		return
+	}
+	if auth.Locked {
+		wc.ErrorJSON(http.StatusForbidden, errInvalidRequest,
+			"request", "validate", "err", "account is locked")
+		return
+	}
+
+	secret, err := generateTokenKey()
+	if err != nil {
+		logx.Error("failed to create scep challenge", "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	model := entity.ScepChallenge{
+		Owner:     auth.ID,
+		Secret:    scepChallengeHash(secret),
+		ExpiresAt: time.Now().Add(v.conf.SCEP.ChallengeTTL),
+	}
+	if err = v.entityRepo.CreateScepChallenge(wc.Context(), &model); err != nil {
+		logx.Error("failed to create scep challenge", "id", auth.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("SCEP challenge created", "id", auth.ID, "token_id", auth.TokenId, "expires_at", model.ExpiresAt)
+
+	wc.JSON(http.StatusOK, &client.ScepChallengeModel{
+		Challenge: secret,
+		ExpiresAt: model.ExpiresAt,
+	})
+}
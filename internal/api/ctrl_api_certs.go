@@ -0,0 +1,137 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.osspkg.com/do"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+)
+
+const (
+	certsDefaultLimit = 100
+	certsMaxLimit     = 1000
+)
+
+func parseCertsFilter(q url.Values) (*entity.CertFilter, error) {
+	filter := &entity.CertFilter{
+		Domain: strings.ToLower(strings.TrimSpace(q.Get(client.CertsQueryDomain))),
+		Limit:  certsDefaultLimit,
+	}
+
+	if val := q.Get(client.CertsQueryRevoked); len(val) > 0 {
+		revoked, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", client.CertsQueryRevoked, err)
+		}
+		filter.Revoked = &revoked
+	}
+
+	for key, target := range map[string]**time.Time{
+		client.CertsQueryExpiresAfter:  &filter.ExpiresAfter,
+		client.CertsQueryExpiresBefore: &filter.ExpiresBefore,
+	} {
+		val := q.Get(key)
+		if len(val) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*target = &t
+	}
+
+	if val := q.Get(client.CertsQueryCursor); len(val) > 0 {
+		if _, err := parseSerial(val); err != nil {
+			return nil, fmt.Errorf("invalid %s", client.CertsQueryCursor)
+		}
+		filter.Cursor = val
+	}
+
+	if val := q.Get(client.CertsQueryLimit); len(val) > 0 {
+		limit, err := strconv.ParseUint(val, 10, 32)
+		if err != nil || limit == 0 || limit > certsMaxLimit {
+			return nil, fmt.Errorf("invalid %s, must be from 1 to %d", client.CertsQueryLimit, certsMaxLimit)
+		}
+		filter.Limit = uint(limit)
+	}
+
+	return filter, nil
+}
+
+func (v *API) CertsV1(wc web.Ctx) {
+	ownerId, ok := wc.GetContextValue(ownerIdCtx).(int64)
+	if !ok || ownerId <= 0 {
+		logx.Error("failed to fetch owner id", "ownerId", ownerId)
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	filter, err := parseCertsFilter(wc.Request().URL.Query())
+	if err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", err.Error())
+		return
+	}
+	filter.Owner = ownerId
+
+	list, err := v.entityRepo.SelectCertByFilter(wc.Context(), *filter)
+	if err != nil {
+		logx.Error("failed to fetch certs by filter", "ownerId", ownerId, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	ids := do.Convert[entity.Cert, string](list, func(value entity.Cert, _ int) string {
+		return value.SerialNumber
+	})
+
+	domains, err := v.entityRepo.SelectCertDomainBySerialNumber(wc.Context(), ids...)
+	if err != nil {
+		logx.Error("failed to fetch cert domains", "ownerId", ownerId, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	domainsBySerial := make(map[string][]string, len(list))
+	for _, d := range domains {
+		domainsBySerial[d.SerialNumber] = append(domainsBySerial[d.SerialNumber], d.Domain)
+	}
+
+	resp := client.CertsModel{
+		Certs: make([]client.CertModel, 0, len(list)),
+	}
+
+	for _, item := range list {
+		resp.Certs = append(resp.Certs, client.CertModel{
+			SerialNumber:  item.SerialNumber,
+			Subject:       item.Subject,
+			FingerPrint:   item.FingerPrint,
+			Domains:       domainsBySerial[item.SerialNumber],
+			Revoked:       item.Revoked,
+			RevokedReason: item.RevokedReason,
+			CreatedAt:     item.CreatedAt,
+			ValidUntil:    item.ValidUntil,
+		})
+	}
+
+	if uint(len(list)) == filter.Limit {
+		resp.NextCursor = list[len(list)-1].SerialNumber
+	}
+
+	wc.JSON(http.StatusOK, &resp)
+}
@@ -13,6 +13,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,8 +27,10 @@ import (
 	"go.osspkg.com/goppy/v2/auth/signature"
 	"go.osspkg.com/goppy/v2/web"
 	"go.osspkg.com/logx"
+	"go.osspkg.com/routine/tick"
 	"go.osspkg.com/validate"
 
+	"go.arwos.org/casper/internal/pkgs/access"
 	"go.arwos.org/casper/internal/pkgs/certs"
 
 	"go.arwos.org/casper/client"
@@ -41,52 +47,184 @@ var (
 	errForbidden      = errors.New("forbidden")
 	errInternalError  = errors.New("internal error")
 	errInvalidRequest = errors.New("invalid request")
+	errNotFound       = errors.New("not found")
 )
 
 func (v *API) addApiHandlers() {
 	v.apiRoute.Use(
 		web.ThrottlingMiddleware(300),
 		v.authzValidate(),
+		v.adminValidate(),
 	)
 	v.apiRoute.Post(client.PathRenewalV1, v.RenewCertV1)
+	v.apiRoute.Post(client.PathRevokeV1, v.RevokeCertV1)
+	v.apiRoute.Get(client.PathCertsV1, v.CertsV1)
+
+	v.addAdminHandlers()
 }
 
+// apiPathPrefix marks routes protected by the HMAC signature, other protocols on the
+// same server (ACME) carry their own authentication.
+const apiPathPrefix = "/api/"
+
 const (
-	userRequestCtx       apiCtx = "renew_cert_request"
-	userAccessDomainsCtx apiCtx = "user_access_domains"
-	ownerIdCtx           apiCtx = "owner_id"
+	userRequestCtx        apiCtx = "renew_cert_request"
+	userAccessDomainsCtx  apiCtx = "user_access_domains"
+	userAccessProfilesCtx apiCtx = "user_access_profiles"
+	ownerIdCtx            apiCtx = "owner_id"
+	userCurrentCertCtx    apiCtx = "user_current_cert"
 )
 
+type signedRequest struct {
+	ID   uuid.UUID
+	Alg  string
+	Hash crypto.Hash
+	Sig  string
+	Body []byte
+	// Data is the signed data: the body, for GET also the method, path and query
+	Data []byte
+	// V2Only rejects v1 signatures, they can not cover more than the body
+	V2Only bool
+}
+
+// decodeSignedRequest reads the signature header and the request body,
+// on failure the error response is already written.
+func decodeSignedRequest(wc web.Ctx) (*signedRequest, bool) {
+	data, err := signature.Decode(wc.Header())
+	if err != nil {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "invalid authorization header")
+		return nil, false
+	}
+
+	id, err := uuid.Parse(data.ID)
+	if err != nil {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "invalid token id", "err", err.Error())
+		return nil, false
+	}
+
+	alg, ok := _sigAlg[data.Alg]
+	if !ok && !client.IsSignatureV2(data.Alg) {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "invalid algorithm", "alg", data.Alg)
+		return nil, false
+	}
+
+	var req []byte
+	if err = wc.BindBytes(&req); err != nil {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "failed read request", "err", err.Error())
+		return nil, false
+	}
+
+	sr := &signedRequest{
+		ID:   id,
+		Alg:  data.Alg,
+		Hash: alg,
+		Sig:  data.Sig,
+		Body: req,
+		Data: req,
+	}
+	if r := wc.Request(); r.Method == http.MethodGet {
+		sr.Data = client.SignedRequestData(r.Method, r.URL.Path, r.URL.RawQuery, req)
+		sr.V2Only = true
+	}
+	return sr, true
+}
+
+// verifySignedRequest checks the signature with the key. A v2 signature must be signed
+// within the clock skew window and carry a nonce never seen by any server instance,
+// v1 signatures are accepted while allowed by the config, except for GET requests.
+// On failure the error response is already written.
+func (v *API) verifySignedRequest(wc web.Ctx, r *signedRequest, key string) bool {
+	if !client.IsSignatureV2(r.Alg) {
+		if r.V2Only {
+			wc.ErrorJSON(http.StatusForbidden, errForbidden,
+				"authorization", "signature v2 is required", "id", r.ID, "alg", r.Alg)
+			return false
+		}
+		if !v.conf.Signature.AllowV1 {
+			wc.ErrorJSON(http.StatusForbidden, errForbidden,
+				"authorization", "signature v1 is disabled", "id", r.ID, "alg", r.Alg)
+			return false
+		}
+		sig := signature.NewCustomSignature(r.ID.String(), key, r.Alg, r.Hash)
+		if !sig.Verify(r.Data, r.Sig) {
+			wc.ErrorJSON(http.StatusForbidden, errForbidden,
+				"authorization", "invalid signature", "id", r.ID, "alg", r.Alg)
+			return false
+		}
+		return true
+	}
+
+	ts, nonce, err := client.VerifySignatureV2(r.ID.String(), key, r.Alg, r.Data, r.Sig)
+	if err != nil {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", err.Error(), "id", r.ID, "alg", r.Alg)
+		return false
+	}
+
+	skew := v.conf.Signature.ClockSkew
+	if now := time.Now(); ts.Before(now.Add(-skew)) || ts.After(now.Add(skew)) {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "signature timestamp is out of the allowed window", "id", r.ID)
+		return false
+	}
+
+	model := entity.RequestNonce{
+		Nonce:     r.ID.String() + ":" + nonce,
+		ExpiresAt: ts.Add(skew),
+	}
+	if err = v.entityRepo.CreateRequestNonce(wc.Context(), &model, entity.ConflictIgnore()); err != nil {
+		logx.Error("failed to save request nonce", "id", r.ID, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return false
+	}
+	if model.ID == 0 {
+		logx.Warn("Replayed request rejected", "id", r.ID, "nonce", nonce)
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "signature nonce already used", "id", r.ID)
+		return false
+	}
+
+	return true
+}
+
+func (v *API) tickerConfigCleanNonce() tick.Config {
+	return tick.Config{
+		Name:     "delete expired request nonces",
+		OnStart:  true,
+		Interval: 10 * time.Minute,
+		Func: func(ctx context.Context, _ time.Time) error {
+			return v.entityRepo.DeleteRequestNonceExpired(ctx)
+		},
+	}
+}
+
 func (v *API) authzValidate() web.Middleware {
 	return func(next func(web.Ctx)) func(web.Ctx) {
 		return func(wc web.Ctx) {
-			data, err := signature.Decode(wc.Header())
-			if err != nil {
-				wc.ErrorJSON(http.StatusForbidden, errForbidden,
-					"authorization", "invalid authorization header")
+			path := wc.Request().URL.Path
+			if !strings.HasPrefix(path, apiPathPrefix) || strings.HasPrefix(path, client.PathAdminPrefix) {
+				next(wc)
 				return
 			}
 
-			id, err := uuid.Parse(data.ID)
-			if err != nil {
-				wc.ErrorJSON(http.StatusForbidden, errForbidden,
-					"authorization", "invalid token id", "err", err.Error())
-				return
+			if path == client.PathRenewalV1 {
+				if crt := v.clientCertificate(wc.Request()); crt != nil {
+					if _, err := signature.Decode(wc.Header()); err != nil {
+						v.mtlsValidate(wc, crt, next)
+						return
+					}
+				}
 			}
 
-			alg, ok := _sigAlg[data.Alg]
+			sr, ok := decodeSignedRequest(wc)
 			if !ok {
-				wc.ErrorJSON(http.StatusForbidden, errForbidden,
-					"authorization", "invalid algorithm", "alg", data.Alg)
-				return
-			}
-
-			var req []byte
-			if err = wc.BindBytes(&req); err != nil {
-				wc.ErrorJSON(http.StatusForbidden, errForbidden,
-					"authorization", "failed read request", "err", err.Error())
 				return
 			}
+			id := sr.ID
 
 			auth, err := v.entityRepo.SelectAuthByTokenId(wc.Context(), id)
 			if err != nil {
@@ -101,15 +239,13 @@ func (v *API) authzValidate() web.Middleware {
 				return
 			}
 
-			sig := signature.NewCustomSignature(auth[0].TokenId.String(), auth[0].TokenKey, data.Alg, alg)
-			if !sig.Verify(req, data.Sig) {
-				wc.ErrorJSON(http.StatusForbidden, errForbidden,
-					"authorization", "invalid signature", "id", data.ID, "alg", data.Alg)
+			if !v.verifySignedRequest(wc, sr, auth[0].TokenKey) {
 				return
 			}
 
-			wc.SetContextValue(userRequestCtx, &req)
+			wc.SetContextValue(userRequestCtx, &sr.Body)
 			wc.SetContextValue(userAccessDomainsCtx, auth[0].Domains)
+			wc.SetContextValue(userAccessProfilesCtx, auth[0].Profiles)
 			wc.SetContextValue(ownerIdCtx, auth[0].ID)
 
 			next(wc)
@@ -117,55 +253,252 @@ func (v *API) authzValidate() web.Middleware {
 	}
 }
 
+// mtlsValidate authenticates renewal without the HMAC signature by a certificate issued by casper,
+// the renewal must request the same names as the presented certificate.
+func (v *API) mtlsValidate(wc web.Ctx, crt *x509.Certificate, next func(web.Ctx)) {
+	auth, err := v.clientCertAuth(wc.Context(), crt)
+	if err != nil {
+		logx.Warn("Client certificate rejected", "serial", crt.SerialNumber, "err", err)
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "invalid client certificate")
+		return
+	}
+
+	var req []byte
+	if err = wc.BindBytes(&req); err != nil {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"authorization", "failed read request", "err", err.Error())
+		return
+	}
+
+	wc.SetContextValue(userRequestCtx, &req)
+	wc.SetContextValue(userAccessDomainsCtx, auth.Domains)
+	wc.SetContextValue(userAccessProfilesCtx, auth.Profiles)
+	wc.SetContextValue(ownerIdCtx, auth.ID)
+	wc.SetContextValue(userCurrentCertCtx, crt)
+
+	next(wc)
+}
+
 func (v *API) validateCRS(csr *x509.CertificateRequest) error {
 	if len(csr.IPAddresses) > 0 {
 		return fmt.Errorf("contain IP Addresses")
 	}
 
-	if len(csr.DNSNames) == 0 {
-		return fmt.Errorf("require DNS Names")
+	if len(csr.DNSNames) == 0 && len(csr.URIs) == 0 && len(csr.EmailAddresses) == 0 {
+		return fmt.Errorf("require DNS Names, URIs or Emails")
 	}
 
 	for _, domain := range csr.DNSNames {
-		if !validate.IsValidDomain(domain) {
+		if !isValidDNSName(domain) {
 			return fmt.Errorf("invalid domain: %s", domain)
 		}
 	}
 
+	for _, email := range csr.EmailAddresses {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email || len(addr.Name) > 0 {
+			return fmt.Errorf("invalid email: %s", email)
+		}
+		_, domain, _ := strings.Cut(email, "@")
+		if !validate.IsValidDomain(strings.ToLower(domain)) {
+			return fmt.Errorf("invalid email domain: %s", email)
+		}
+	}
+
+	for _, uri := range csr.URIs {
+		if err := validateURI(uri); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateURI accepts absolute URIs with a host, SPIFFE IDs follow the SPIFFE-ID specification.
+func validateURI(u *url.URL) error {
+	if len(u.Scheme) == 0 || len(u.Hostname()) == 0 || u.User != nil {
+		return fmt.Errorf("invalid uri: %s", u.String())
+	}
+	if !validate.IsValidDomain(strings.ToLower(u.Hostname())) {
+		return fmt.Errorf("invalid uri host: %s", u.String())
+	}
+	if strings.EqualFold(u.Scheme, spiffeScheme) {
+		return validateSPIFFEID(u)
+	}
+	return nil
+}
+
+const spiffeScheme = "spiffe"
+
+// validateSPIFFEID accepts workload IDs in the canonical form only: a lower case trust domain
+// without port, and a path of non-empty segments of [A-Za-z0-9._-] other than "." and "..".
+// Access grants match the path by prefix, so a path that differs from its clean form is rejected.
+func validateSPIFFEID(u *url.URL) error {
+	if len(u.Port()) > 0 || len(u.RawQuery) > 0 || u.ForceQuery || len(u.Fragment) > 0 ||
+		len(u.RawPath) > 0 || len(u.Opaque) > 0 {
+		return fmt.Errorf("invalid spiffe id: %s", u.String())
+	}
+	if u.Host != strings.ToLower(u.Host) {
+		return fmt.Errorf("invalid spiffe id: trust domain must be lower case: %s", u.String())
+	}
+	if len(u.Path) < 2 || path.Clean(u.Path) != u.Path {
+		return fmt.Errorf("invalid spiffe id: path must be non-empty and canonical: %s", u.String())
+	}
+	for _, segment := range strings.Split(u.Path[1:], "/") {
+		if segment == "." || segment == ".." || strings.IndexFunc(segment, isNotSPIFFEPathChar) >= 0 {
+			return fmt.Errorf("invalid spiffe id: invalid path segment %q: %s", segment, u.String())
+		}
+	}
 	return nil
 }
 
-func (v *API) getRootCertificate(domains []string) (*certs.Certificate, error) {
-	domain := ""
+func isNotSPIFFEPathChar(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
+}
+
+// csrLookupNames returns the names used to select the CA: DNS names, email domains and
+// non-SPIFFE URI hosts, SPIFFE IDs are matched by their trust domains.
+func csrLookupNames(csr *x509.CertificateRequest) (domains, trustDomains []string) {
+	domains = append(domains, csr.DNSNames...)
+	for _, email := range csr.EmailAddresses {
+		_, domain, _ := strings.Cut(email, "@")
+		domains = append(domains, strings.ToLower(domain))
+	}
+	for _, uri := range csr.URIs {
+		if strings.EqualFold(uri.Scheme, spiffeScheme) {
+			trustDomains = append(trustDomains, strings.ToLower(uri.Hostname()))
+		} else {
+			domains = append(domains, strings.ToLower(uri.Hostname()))
+		}
+	}
+	return
+}
+
+// checkCSRAccess matches every SAN of the CSR against the account grants.
+func checkCSRAccess(grants []string, csr *x509.CertificateRequest) ([]string, error) {
+	rules, err := access.Parse(grants)
+	if err != nil {
+		return nil, err
+	}
+
+	denied := rules.Check(csr.DNSNames...)
+	denied = append(denied, rules.CheckURIs(csr.URIs...)...)
+	denied = append(denied, rules.CheckEmails(csr.EmailAddresses...)...)
+	return denied, nil
+}
+
+// csrIdentities returns every SAN of the CSR in the form stored for the certificate.
+func csrIdentities(csr *x509.CertificateRequest) []string {
+	result := make([]string, 0, len(csr.DNSNames)+len(csr.EmailAddresses)+len(csr.URIs))
+	result = append(result, csr.DNSNames...)
+	for _, email := range csr.EmailAddresses {
+		result = append(result, access.NormalizeEmail(email))
+	}
+	for _, uri := range csr.URIs {
+		result = append(result, access.NormalizeURI(uri))
+	}
+	return result
+}
+
+// isValidDNSName accepts a domain or a wildcard with a single leading asterisk label
+// over at least a level 2 domain.
+func isValidDNSName(name string) bool {
+	base := certs.TrimWildcard(name)
+	if strings.Contains(base, "*") {
+		return false
+	}
+	if certs.IsWildcard(name) && !strings.Contains(base, ".") {
+		return false
+	}
+	return validate.IsValidDomain(base)
+}
+
+func (v *API) getRootCertificate(domains []string, trustDomains ...string) (*certs.Certificate, error) {
+	var (
+		ca          *certs.Certificate
+		first, zone string
+	)
+
+	use := func(c *certs.Certificate, name, z string) error {
+		if ca == nil {
+			ca, first, zone = c, name, z
+			return nil
+		}
+		if ca != c {
+			return fmt.Errorf("names resolve to different CA: %s (zone %s) and %s (zone %s)",
+				first, zone, name, z)
+		}
+		return nil
+	}
+
 	for _, name := range domains {
-		level2 := validate.GetDomainLevel(name, 2)
-		if domain == "" {
-			domain = level2
-		} else if domain != level2 {
-			return nil, fmt.Errorf("issuing certificates for different level 2 domains is prohibited")
+		c, z, ok := v.certStore.Match(name)
+		if !ok {
+			return nil, fmt.Errorf("not found CA for domain: %s", name)
+		}
+		if err := use(c, name, z); err != nil {
+			return nil, err
 		}
 	}
 
-	if len(domain) == 0 {
-		return nil, fmt.Errorf("invalid domain level 2")
+	for _, td := range trustDomains {
+		c, ok := v.certStore.MatchTrustDomain(td)
+		if !ok {
+			return nil, fmt.Errorf("not found CA for trust domain: %s", td)
+		}
+		if err := use(c, spiffeScheme+"://"+td, td); err != nil {
+			return nil, err
+		}
 	}
 
-	ca, ok := v.certStore.Get(strings.Trim(domain, "."))
-	if !ok {
-		return nil, fmt.Errorf("not found CA for domain: %s", domain)
+	if ca == nil {
+		return nil, fmt.Errorf("require DNS Names")
+	}
+
+	for _, name := range domains {
+		if err := ca.CheckWildcard(name); err != nil {
+			return nil, err
+		}
 	}
 
 	return ca, nil
 }
 
+// getProfile resolves the requested profile, an account without explicit profiles
+// may use only the default one.
+func (v *API) getProfile(name string, allowed []string) (*certs.Profile, error) {
+	if len(name) == 0 {
+		name = v.certStore.DefaultProfile()
+	}
+
+	profile, ok := v.certStore.Profile(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown profile: %s", name)
+	}
+
+	if len(allowed) == 0 {
+		if profile.Name != v.certStore.DefaultProfile() {
+			return nil, fmt.Errorf("profile is not allowed: %s", name)
+		}
+		return profile, nil
+	}
+
+	if !slices.Contains(allowed, profile.Name) {
+		return nil, fmt.Errorf("profile is not allowed: %s", name)
+	}
+
+	return profile, nil
+}
+
 func (v *API) createAndSignCertificate(
-	ctx context.Context, ca *certs.Certificate, csr *x509.CertificateRequest, model *entity.Cert,
+	ctx context.Context, ca *certs.Certificate, profile *certs.Profile, csr *x509.CertificateRequest, model *entity.Cert,
 ) (*x509.Certificate, error) {
 	if err := v.entityRepo.CreateCert(ctx, model); err != nil {
 		return nil, fmt.Errorf("failed to create new certificate: %w", err)
 	}
 
-	entityCertDomains := do.Convert[string, *entity.CertDomain](csr.DNSNames,
+	entityCertDomains := do.Convert[string, *entity.CertDomain](csrIdentities(csr),
 		func(value string, _ int) *entity.CertDomain {
 			return &entity.CertDomain{
 				Domain:       value,
@@ -177,19 +510,17 @@ func (v *API) createAndSignCertificate(
 		return nil, fmt.Errorf("failed to create domain certificates: %w", err)
 	}
 
-	newCert, err := pki.SignCSR(
-		pki.Config{
-			IssuingCertificateURLs:   ca.ICUs,
-			CertificatePoliciesURLs:  ca.CPSs,
-			CRLDistributionPointURLs: ca.CRLs,
-			OCSPServerURLs:           ca.OCSPs,
-		},
-		*ca.Issuer,
-		*csr,
-		time.Duration(ca.Days)*time.Hour*24,
-		model.SerialNumber,
-	)
+	serial, err := parseSerial(model.SerialNumber)
 	if err != nil {
+		return nil, err
+	}
+
+	newCert, err := certs.SignCSR(ca, profile, csr, serial)
+	if err != nil {
+		// the reserved serial was never signed, its domains go by cascade
+		if e := v.entityRepo.DeleteCertBySerialNumber(ctx, model.SerialNumber); e != nil {
+			err = fmt.Errorf("%w, and failed to delete it: %w", err, e)
+		}
 		return nil, fmt.Errorf("failed to sign new certificate: %w", err)
 	}
 
@@ -219,10 +550,116 @@ func (v *API) createAndSignCertificate(
 	if err = v.entityRepo.UpdateCertBySerialNumber(ctx, model); err != nil {
 		return nil, fmt.Errorf("failed to update certificate: %w", err)
 	}
+	v.refreshOCSP()
 
 	return newCert, nil
 }
 
+// policyError returns the message of the issuer policy violation that failed the issuance.
+func policyError(err error) (string, bool) {
+	var e *certs.PolicyError
+	if errors.As(err, &e) {
+		return e.Error(), true
+	}
+	return "", false
+}
+
+type issueResult struct {
+	Status       client.RenewalStatus
+	SerialNumber string
+	CA           []string
+	Cert         string
+	DNSNames     []string
+	URIs         []string
+	Emails       []string
+}
+
+// issueCertificate signs a new certificate and supersedes the owner's current certificates
+// for the CSR names. It is the common issuance path for every enrollment protocol: SignCSR
+// enforces the issuer policy, a rejected CSR leaves no row behind and the current certificates
+// are revoked only after the new one is signed. A policy violation is returned as *certs.PolicyError.
+func (v *API) issueCertificate(
+	ctx context.Context, ownerId int64, ca *certs.Certificate, profile *certs.Profile,
+	csr *x509.CertificateRequest, force bool,
+) (*issueResult, error) {
+	exists, err := v.entityRepo.SelectCertNonRevokedByDomains(ctx, csrIdentities(csr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cert non revoked by domains: %w", err)
+	}
+
+	entityModel := entity.Cert{
+		Owner:   ownerId,
+		Revoked: true,
+	}
+
+	result := &issueResult{
+		Status: client.RenewalStatusIssued,
+	}
+
+	for _, exist := range exists {
+		if exist.Owner != entityModel.Owner {
+			result.Status = client.RenewalStatusFail
+			return result, nil
+		}
+
+		if !force && exist.ValidUntil.After(time.Now().AddDate(0, 0, -3)) {
+			result.Status = client.RenewalStatusActual
+			return result, nil
+		}
+	}
+
+	newCert, err := v.createAndSignCertificate(ctx, ca, profile, csr, &entityModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new certificate: %w", err)
+	}
+	result.SerialNumber = entityModel.SerialNumber
+
+	if len(exists) > 0 {
+		ids := do.Convert[entity.Cert, string](exists, func(value entity.Cert, _ int) string {
+			return value.SerialNumber
+		})
+
+		if err = v.entityRepo.UpdateCertsAsRevoked(ctx,
+			entityModel.Owner, ids, int64(pki.OCSPRevocationReasonSuperseded),
+		); err != nil {
+			return nil, fmt.Errorf("failed to revoke actual certificates: %w", err)
+		}
+		v.refreshOCSP()
+	}
+
+	if result.CA, err = v.caChainPEM(ca); err != nil {
+		return nil, err
+	}
+
+	crtPem, err := pki.MarshalCrtPEM(*newCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate: %w", err)
+	}
+	result.Cert = string(crtPem)
+	result.DNSNames = newCert.DNSNames
+	result.Emails = newCert.EmailAddresses
+	for _, uri := range newCert.URIs {
+		result.URIs = append(result.URIs, uri.String())
+	}
+
+	return result, nil
+}
+
+func (v *API) caChainPEM(ca *certs.Certificate) ([]string, error) {
+	list := ca.ChainCerts()
+	result := make([]string, 0, len(list))
+
+	for _, crt := range list {
+		pem, err := pki.MarshalCrtPEM(*crt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode chain certificate: %w", err)
+		}
+		result = append(result, string(pem))
+	}
+
+	return result, nil
+}
+
 func (v *API) RenewCertV1(wc web.Ctx) {
 	ownerId, ok := wc.GetContextValue(ownerIdCtx).(int64)
 	if !ok || ownerId <= 0 {
@@ -239,6 +676,8 @@ func (v *API) RenewCertV1(wc web.Ctx) {
 		return
 	}
 
+	userProfiles, _ := wc.GetContextValue(userAccessProfilesCtx).([]string)
+
 	req, ok := wc.GetContextValue(userRequestCtx).(*[]byte)
 	if !ok || req == nil || len(*req) == 0 {
 		logx.Error("failed to fetch renew certificate request")
@@ -266,91 +705,60 @@ func (v *API) RenewCertV1(wc web.Ctx) {
 		return
 	}
 
-	ca, err := v.getRootCertificate(csr.DNSNames)
-	if err != nil {
-		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
-			"request", "validate", "err", err.Error())
+	if current, ok := wc.GetContextValue(userCurrentCertCtx).(*x509.Certificate); ok && current != nil &&
+		!sameIdentities(current, csr) {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"request", "validate", "err", "csr names do not match the client certificate")
 		return
 	}
 
-	exists, err := v.entityRepo.SelectCertNonRevokedByDomains(wc.Context(), csr.DNSNames)
+	denied, err := checkCSRAccess(userDomains, csr)
 	if err != nil {
-		logx.Error("failed to fetch cert non revoked by domains", "domains", csr.DNSNames, "err", err)
+		logx.Error("failed to parse user access domains", "ownerId", ownerId, "err", err)
 		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
 		return
 	}
-
-	entityModel := entity.Cert{
-		Owner:   ownerId,
-		Revoked: true,
-	}
-
-	resp := client.RenewalModel{
-		Status: client.RenewalStatusIssued,
-	}
-
-	if len(exists) > 0 {
-		for _, exist := range exists {
-			if exist.Owner != entityModel.Owner {
-				resp.Status = client.RenewalStatusFail
-				wc.JSON(http.StatusOK, &resp)
-				return
-			}
-
-			if !renewalRequest.Force && exist.ValidUntil.After(time.Now().AddDate(0, 0, -3)) {
-				resp.Status = client.RenewalStatusActual
-				wc.JSON(http.StatusOK, &resp)
-				return
-			}
-		}
-
-		ids := do.Convert[entity.Cert, int64](exists, func(value entity.Cert, _ int) int64 {
-			return value.SerialNumber
-		})
-
-		if err = v.entityRepo.UpdateCertsAsRevoked(wc.Context(),
-			entityModel.Owner, ids, int64(pki.OCSPRevocationReasonSuperseded),
-		); err != nil {
-			logx.Error("failed to revoke actual certificates", "domains", csr.DNSNames, "err", err)
-			wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
-			return
-		}
+	if len(denied) > 0 {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"request", "validate", "denied_domains", strings.Join(denied, "; "))
+		return
 	}
 
-	newCert, err := v.createAndSignCertificate(wc.Context(), ca, csr, &entityModel)
+	lookupDomains, trustDomains := csrLookupNames(csr)
+	ca, err := v.getRootCertificate(lookupDomains, trustDomains...)
 	if err != nil {
-		logx.Error("failed to create new certificate", "domains", csr.DNSNames, "err", err)
-		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", err.Error())
 		return
 	}
 
-	pem, err := pki.MarshalCrtPEM(*ca.Issuer.Crt)
+	profile, err := v.getProfile(renewalRequest.Profile, userProfiles)
 	if err != nil {
-		wc.ErrorJSON(http.StatusInternalServerError, err)
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"request", "validate", "profile", err.Error())
 		return
 	}
-	resp.CA = append(resp.CA, string(pem))
 
-	ski := ca.Issuer.Crt.SubjectKeyId
-	for {
-		caChain, has := ca.GetBySubjectKeyId(ski)
-		if !has {
-			break
-		}
-		pem, err = pki.MarshalCrtPEM(*caChain)
-		if err != nil {
-			wc.ErrorJSON(http.StatusInternalServerError, err)
-			return
-		}
-		resp.CA = append(resp.CA, string(pem))
+	result, err := v.issueCertificate(wc.Context(), ownerId, ca, profile, csr, renewalRequest.Force)
+	if msg, ok := policyError(err); ok {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "issuer_policy", msg)
+		return
 	}
-
-	crtPem, crtErr := pki.MarshalCrtPEM(*newCert)
-	if crtErr != nil {
-		wc.ErrorJSON(http.StatusInternalServerError, crtErr)
+	if err != nil {
+		logx.Error("failed to issue certificate", "names", csrIdentities(csr), "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
 		return
 	}
-	resp.Cert = string(crtPem)
+
+	resp := client.RenewalModel{
+		Status:   result.Status,
+		CA:       result.CA,
+		Cert:     result.Cert,
+		DNSNames: result.DNSNames,
+		URIs:     result.URIs,
+		Emails:   result.Emails,
+	}
 
 	wc.JSON(http.StatusOK, &resp)
 }
@@ -0,0 +1,159 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+// testCAGroup writes a self-signed CA into dir and returns the group issuing for the domains.
+func testCAGroup(t *testing.T, dir, name string, domains, trustDomains []string) certs.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+
+	conf := certs.Config{
+		IssuingCACert:     filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".crt"),
+		IssuingCAKey:      filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".key"),
+		Domains:           domains,
+		TrustDomains:      trustDomains,
+		DefaultExpireDays: 30,
+		Wildcard:          certs.Wildcard{Allow: true, MinLevel: 3},
+	}
+	if err = os.WriteFile(conf.IssuingCACert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err = os.WriteFile(conf.IssuingCAKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return conf
+}
+
+func TestGetRootCertificate(t *testing.T) {
+	dir := t.TempDir()
+	group := &certs.ConfigGroup{Certs: []certs.Config{
+		testCAGroup(t, dir, "CA Example", []string{"example.com"}, []string{"example.com"}),
+		testCAGroup(t, dir, "CA Sub", []string{"a.example.com"}, []string{"sub.example.com"}),
+	}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	v := &API{certStore: store}
+
+	tests := []struct {
+		name         string
+		domains      []string
+		trustDomains []string
+		wantCA       string
+		wantErr      string
+	}{
+		{name: "one zone", domains: []string{"example.com", "www.example.com"}, wantCA: "CA Example"},
+		{name: "longest zone", domains: []string{"x.a.example.com", "*.a.example.com"}, wantCA: "CA Sub"},
+		{name: "trust domain of the same CA", domains: []string{"www.example.com"},
+			trustDomains: []string{"example.com"}, wantCA: "CA Example"},
+		{name: "only trust domain", trustDomains: []string{"sub.example.com"}, wantCA: "CA Sub"},
+		{name: "zones of different CA", domains: []string{"www.example.com", "x.a.example.com"},
+			wantErr: "names resolve to different CA"},
+		{name: "trust domain of other CA", domains: []string{"www.example.com"},
+			trustDomains: []string{"sub.example.com"}, wantErr: "names resolve to different CA"},
+		{name: "unknown zone", domains: []string{"example.org"}, wantErr: "not found CA for domain"},
+		{name: "unknown trust domain", trustDomains: []string{"other.org"}, wantErr: "not found CA for trust domain"},
+		{name: "no names", wantErr: "require DNS Names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca, err := v.getRootCertificate(tt.domains, tt.trustDomains...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getRootCertificate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getRootCertificate() error = %v", err)
+			}
+			if got := ca.Issuer.Crt.Subject.CommonName; got != tt.wantCA {
+				t.Fatalf("getRootCertificate() CA = %s, want %s", got, tt.wantCA)
+			}
+		})
+	}
+}
+
+func TestValidateURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{name: "spiffe workload", uri: "spiffe://example.com/ns/default/sa/web"},
+		{name: "spiffe allowed chars", uri: "spiffe://example.com/a-b_c.d/E9"},
+		{name: "https", uri: "https://example.com/app/"},
+		{name: "spiffe dot dot", uri: "spiffe://example.com/ns/a/../../admin", wantErr: true},
+		{name: "spiffe dot", uri: "spiffe://example.com/ns/./a", wantErr: true},
+		{name: "spiffe trailing dot dot", uri: "spiffe://example.com/ns/..", wantErr: true},
+		{name: "spiffe empty segment", uri: "spiffe://example.com/ns//a", wantErr: true},
+		{name: "spiffe trailing slash", uri: "spiffe://example.com/ns/a/", wantErr: true},
+		{name: "spiffe percent encoded", uri: "spiffe://example.com/ns/a%20b", wantErr: true},
+		{name: "spiffe encoded slash", uri: "spiffe://example.com/ns/a%2Fb", wantErr: true},
+		{name: "spiffe invalid char", uri: "spiffe://example.com/ns/a:b", wantErr: true},
+		{name: "spiffe upper case trust domain", uri: "spiffe://Example.com/ns/a", wantErr: true},
+		{name: "spiffe no path", uri: "spiffe://example.com", wantErr: true},
+		{name: "spiffe root path", uri: "spiffe://example.com/", wantErr: true},
+		{name: "spiffe port", uri: "spiffe://example.com:8443/ns/a", wantErr: true},
+		{name: "spiffe query", uri: "spiffe://example.com/ns/a?x=1", wantErr: true},
+		{name: "spiffe empty query", uri: "spiffe://example.com/ns/a?", wantErr: true},
+		{name: "spiffe fragment", uri: "spiffe://example.com/ns/a#x", wantErr: true},
+		{name: "spiffe user info", uri: "spiffe://user@example.com/ns/a", wantErr: true},
+		{name: "no host", uri: "urn:example:a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.uri)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			if err = validateURI(u); (err != nil) != tt.wantErr {
+				t.Fatalf("validateURI(%s) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.osspkg.com/encrypt/pki"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+)
+
+func (v *API) findCertForRevoke(ctx context.Context, req *client.RevokeRequest) ([]entity.Cert, error) {
+	switch {
+	case len(req.SerialNumber) > 0:
+		if _, err := parseSerial(req.SerialNumber); err != nil {
+			return nil, err
+		}
+		return v.entityRepo.SelectCertBySerialNumber(ctx, req.SerialNumber)
+
+	case len(req.FingerPrint) > 0:
+		fp := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(req.FingerPrint), ":", ""))
+		return v.entityRepo.SelectCertByFingerPrint(ctx, fp)
+
+	case len(req.Cert) > 0:
+		crt, err := pki.UnmarshalCrtPEM([]byte(req.Cert))
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal cert pem: %w", err)
+		}
+		fp, err := (&pki.Certificate{Crt: crt}).FingerPrint(entity.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("calculate fingerprint: %w", err)
+		}
+		return v.entityRepo.SelectCertByFingerPrint(ctx, hex.EncodeToString(fp))
+
+	default:
+		return nil, fmt.Errorf("require serial number, fingerprint or certificate")
+	}
+}
+
+func (v *API) RevokeCertV1(wc web.Ctx) {
+	ownerId, ok := wc.GetContextValue(ownerIdCtx).(int64)
+	if !ok || ownerId <= 0 {
+		logx.Error("failed to fetch owner id", "ownerId", ownerId)
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	req, ok := wc.GetContextValue(userRequestCtx).(*[]byte)
+	if !ok || req == nil || len(*req) == 0 {
+		logx.Error("failed to fetch revoke certificate request")
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	revokeRequest := client.RevokeRequest{}
+	if err := json.Unmarshal(*req, &revokeRequest); err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "unmarshal request", "err", err.Error())
+		return
+	}
+
+	if !slices.Contains(slices.Collect(maps.Values(client.RevocationReasons)), revokeRequest.Reason) {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "reason", revokeRequest.Reason)
+		return
+	}
+
+	certs, err := v.findCertForRevoke(wc.Context(), &revokeRequest)
+	if err != nil {
+		wc.ErrorJSON(http.StatusBadRequest, errInvalidRequest,
+			"request", "validate", "err", err.Error())
+		return
+	}
+
+	if len(certs) != 1 {
+		wc.ErrorJSON(http.StatusNotFound, errNotFound,
+			"request", "certificate not found")
+		return
+	}
+
+	cert := certs[0]
+	if cert.Owner != ownerId {
+		wc.ErrorJSON(http.StatusForbidden, errForbidden,
+			"request", "certificate belongs to another account")
+		return
+	}
+
+	resp := client.RevokeModel{
+		Status:       client.RevokeStatusRevoked,
+		SerialNumber: cert.SerialNumber,
+	}
+
+	if cert.Revoked {
+		resp.Status = client.RevokeStatusAlreadyRevoked
+		wc.JSON(http.StatusOK, &resp)
+		return
+	}
+
+	if err = v.entityRepo.UpdateCertsAsRevoked(wc.Context(),
+		ownerId, []string{cert.SerialNumber}, revokeRequest.Reason,
+	); err != nil {
+		logx.Error("failed to revoke certificate", "serial", cert.SerialNumber, "err", err)
+		wc.ErrorJSON(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	v.refreshOCSP()
+
+	logx.Info("Certificate revoked", "serial", cert.SerialNumber, "owner", ownerId, "reason", revokeRequest.Reason)
+
+	wc.JSON(http.StatusOK, &resp)
+}
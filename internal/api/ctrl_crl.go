@@ -7,32 +7,45 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
-	"go.osspkg.com/encrypt/pki"
 	"go.osspkg.com/goppy/v2/web"
 	"go.osspkg.com/logx"
 	"go.osspkg.com/routine/tick"
 	"go.osspkg.com/syncing"
 
 	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/certs"
 )
 
 const (
 	updateCrlIntervalSec = 6 * 60 * 60
+	// every instance checks the stored CRLs that often, the one claiming the next number rebuilds
+	crlCheckInterval = time.Minute
+	crlCacheTTL      = time.Minute
 )
 
-var crlCache = syncing.NewMap[string, []byte](10)
+// crlCached is the last stored CRL read by this instance.
+type crlCached struct {
+	crl      *entity.Crl
+	loadedAt time.Time
+}
+
+var crlCache = syncing.NewMap[string, crlCached](10)
 
-func (v *API) addCrlHandlers() {
+// addCrlHandlers serves the CRL distribution points put into the issued certificates,
+// not the ones of the issuing CA certificate, they belong to the parent CA.
+func (v *API) addCrlHandlers(routes pkiRoutes) {
 	for _, cert := range v.certStore.List() {
 		issuer := cert.Issuer.Crt.Issuer.String()
 
-		for _, addr := range cert.Issuer.Crt.CRLDistributionPoints {
+		for _, addr := range cert.CRLs {
 			uri, err := url.ParseRequestURI(addr)
 			if err != nil {
 				logx.Error("Failed to parse crl server URI", "issuer", issuer, "url", addr, "err", err)
@@ -47,28 +60,77 @@ func (v *API) addCrlHandlers() {
 
 			logx.Info("Adding crl server URL", "issuer", issuer, "uri", uri.Path)
 
-			v.pkiRoute.Get(uri.Path, func() func(ctx web.Ctx) {
-				keyHash := hex.EncodeToString(keyHashB)
-				cc := fmt.Sprintf("max-age=%d,s-maxage=14400,public,no-transform,must-revalidate", updateCrlIntervalSec)
-
-				return func(ctx web.Ctx) {
-					ctx.Header().Set("Content-Type", "application/pkix-crl")
-					ctx.Header().Set("Cache-Control", cc)
-
-					b, ok := crlCache.Get(keyHash)
-					if !ok {
-						ctx.Error(http.StatusInternalServerError, nil)
-						return
-					}
-
-					ctx.Response().WriteHeader(http.StatusOK)
-					if _, err := ctx.Response().Write(b); err != nil {
-						logx.Error("Failed to write crl", "issuer", issuer, "err", err)
-					}
-				}
-			}())
+			routes.add(http.MethodGet, uri.Path, issuer, v.crlHandler(crlCache, hex.EncodeToString(keyHashB), issuer, true,
+				fmt.Sprintf("max-age=%d,s-maxage=14400,public,no-transform,must-revalidate", updateCrlIntervalSec)))
+		}
+	}
+}
+
+func (v *API) crlHandler(cache *syncing.Map[string, crlCached], keyHash, issuer string, complete bool, cc string) func(ctx web.Ctx) {
+	return func(ctx web.Ctx) {
+		crl, err := v.loadCrl(ctx.Context(), cache, keyHash, complete)
+		if err != nil {
+			logx.Error("Failed to get crl", "issuer", issuer, "err", err)
+			ctx.Error(http.StatusInternalServerError, nil)
+			return
+		}
+		if crl == nil {
+			ctx.Header().Set("Retry-After", fmt.Sprintf("%d", int(crlCheckInterval.Seconds())))
+			ctx.Error(http.StatusServiceUnavailable, nil)
+			return
+		}
+
+		etag := fmt.Sprintf("\"%s-%d\"", keyHash, crl.Number)
+		ctx.Header().Set("Content-Type", "application/pkix-crl")
+		ctx.Header().Set("Cache-Control", cc)
+		ctx.Header().Set("ETag", etag)
+		ctx.Header().Set("Last-Modified", crl.ThisUpdate.UTC().Format(http.TimeFormat))
+
+		if isNotModified(ctx.Request(), etag, crl.ThisUpdate) {
+			ctx.Response().WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		ctx.Response().WriteHeader(http.StatusOK)
+		if _, err = ctx.Response().Write(crl.Data); err != nil {
+			logx.Error("Failed to write crl", "issuer", issuer, "err", err)
+		}
+	}
+}
+
+// loadCrl returns the latest stored CRL, it is read from the database at most once per crlCacheTTL.
+func (v *API) loadCrl(ctx context.Context, cache *syncing.Map[string, crlCached], keyHash string, complete bool) (*entity.Crl, error) {
+	if item, ok := cache.Get(keyHash); ok && time.Since(item.loadedAt) < crlCacheTTL {
+		return item.crl, nil
+	}
+
+	crl, err := v.entityRepo.SelectCrlLast(ctx, keyHash, complete)
+	if err != nil {
+		return nil, err
+	}
+	if crl != nil {
+		cache.Set(keyHash, crlCached{crl: crl, loadedAt: time.Now()})
+	}
+	return crl, nil
+}
+
+// isNotModified checks If-None-Match and, only without it, If-Modified-Since (RFC 9110 13.2.2).
+func isNotModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
 		}
+		return false
 	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(ims)
 }
 
 func (v *API) tickerConfigCleanCrl() tick.Config {
@@ -82,47 +144,93 @@ func (v *API) tickerConfigCleanCrl() tick.Config {
 	}
 }
 
+func (v *API) tickerConfigCleanCrlStore() tick.Config {
+	return tick.Config{
+		Name:     "delete outdated crl",
+		OnStart:  true,
+		Interval: 6 * time.Hour,
+		Func: func(ctx context.Context, _ time.Time) error {
+			return v.entityRepo.DeleteCrlOutdated(ctx)
+		},
+	}
+}
+
 func (v *API) tickerConfigBuildCrl() tick.Config {
 	return tick.Config{
 		Name:     "build revoked certs list",
 		OnStart:  true,
-		Interval: updateCrlIntervalSec * time.Second,
-		Func: func(ctx context.Context, t time.Time) error {
-			number := t.UTC().Unix()
+		Interval: crlCheckInterval,
+		Func:     v.buildCrl,
+	}
+}
+
+// buildCrl stores a new complete CRL of every issuer whose last one is older than the update interval,
+// the number is claimed in the database, so one instance builds it and the others skip.
+func (v *API) buildCrl(ctx context.Context, _ time.Time) error {
+	for _, cert := range v.certStore.List() {
+		issuer := cert.Issuer.Crt.Issuer.String()
+
+		keyHashB, err := cert.Issuer.IssuerKeyHash(entity.Hash)
+		if err != nil {
+			logx.Error("Failed to get issuer key hash", "issuer", issuer, "err", err)
+			continue
+		}
 
-			for _, cert := range v.certStore.List() {
-				number++
+		keyHash := hex.EncodeToString(keyHashB)
 
-				issuer := cert.Issuer.Crt.Issuer.String()
-				logx.Info("Updating CRL", "status", "start", "issuer", issuer)
+		claim, err := v.entityRepo.ClaimCrl(ctx, keyHash, 0, updateCrlIntervalSec*time.Second)
+		if err != nil {
+			logx.Error("Failed to claim crl number", "issuer", issuer, "err", err)
+			continue
+		}
+		if claim == nil {
+			continue
+		}
 
-				keyHashB, err := cert.Issuer.IssuerKeyHash(entity.Hash)
-				if err != nil {
-					logx.Error("Failed to get issuer key hash", "issuer", issuer, "err", err)
-					continue
-				}
+		logx.Info("Updating CRL", "status", "start", "issuer", issuer, "number", claim.Number)
 
-				keyHash := hex.EncodeToString(keyHashB)
+		result, err := v.entityRepo.SelectCertRevoked(ctx, keyHash)
+		if err != nil {
+			logx.Error("Failed to get revoked certs", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
 
-				result, err := v.entityRepo.SelectCertRevoked(ctx, keyHash)
-				if err != nil {
-					logx.Error("Failed to get revoked certs", "issuer", issuer, "err", err)
-					continue
-				}
+		nextUpdate := updateCrlIntervalSec*time.Second + 10*time.Minute
+		b, err := certs.NewCRL(cert.Issuer, claim.Number, nextUpdate, result)
+		if err != nil {
+			logx.Error("Failed to build crl", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
 
-				nextUpdate := updateCrlIntervalSec*time.Second + 10*time.Minute
-				b, err := pki.NewCRL(*cert.Issuer, number, nextUpdate, result)
-				if err != nil {
-					logx.Error("Failed to build crl", "issuer", issuer, "err", err)
-					continue
-				}
+		if err = v.storeCrl(ctx, claim, b); err != nil {
+			logx.Error("Failed to store crl", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
+		crlCache.Del(keyHash)
 
-				crlCache.Set(keyHash, b)
+		logx.Info("Updating CRL", "status", "done", "issuer", issuer, "number", claim.Number)
+	}
 
-				logx.Info("Updating CRL", "status", "done", "issuer", issuer)
-			}
+	return nil
+}
 
-			return nil
-		},
+func (v *API) storeCrl(ctx context.Context, claim *entity.Crl, b []byte) error {
+	crl, err := x509.ParseRevocationList(b)
+	if err != nil {
+		return fmt.Errorf("failed to decode crl: %w", err)
+	}
+	claim.Data = b
+	claim.ThisUpdate = crl.ThisUpdate
+	claim.NextUpdate = crl.NextUpdate
+	return v.entityRepo.UpdateCrlByID(ctx, claim)
+}
+
+// releaseCrl drops the claim of a failed build, so the next check retries without waiting the interval.
+func (v *API) releaseCrl(ctx context.Context, claim *entity.Crl, issuer string) {
+	if err := v.entityRepo.DeleteCrlByID(ctx, claim.ID); err != nil {
+		logx.Error("Failed to release crl number", "issuer", issuer, "number", claim.Number, "err", err)
 	}
 }
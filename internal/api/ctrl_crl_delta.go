@@ -0,0 +1,149 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.osspkg.com/logx"
+	"go.osspkg.com/routine/tick"
+	"go.osspkg.com/syncing"
+
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+var deltaCrlCache = syncing.NewMap[string, crlCached](10)
+
+func (v *API) addDeltaCrlHandlers(routes pkiRoutes) {
+	if v.conf.CRL.DeltaInterval <= 0 {
+		return
+	}
+
+	cc := fmt.Sprintf("max-age=%d,public,no-transform,must-revalidate", int(v.conf.CRL.DeltaInterval.Seconds()))
+
+	for _, cert := range v.certStore.List() {
+		issuer := cert.Issuer.Crt.Issuer.String()
+
+		keyHashB, err := cert.Issuer.IssuerKeyHash(entity.Hash)
+		if err != nil {
+			logx.Error("Failed to get issuer key hash", "issuer", issuer, "err", err)
+			continue
+		}
+
+		for _, addr := range cert.DeltaCRLs {
+			uri, err := url.ParseRequestURI(addr)
+			if err != nil {
+				logx.Error("Failed to parse delta crl URI", "issuer", issuer, "url", addr, "err", err)
+				continue
+			}
+
+			logx.Info("Adding delta crl URL", "issuer", issuer, "uri", uri.Path)
+
+			routes.add(http.MethodGet, uri.Path, issuer, v.crlHandler(deltaCrlCache, hex.EncodeToString(keyHashB), issuer, false, cc))
+		}
+	}
+}
+
+func (v *API) tickerConfigBuildDeltaCrl() tick.Config {
+	return tick.Config{
+		Name:     "build delta revoked certs list",
+		OnStart:  false,
+		Interval: min(crlCheckInterval, v.conf.CRL.DeltaInterval),
+		Func:     v.buildDeltaCrl,
+	}
+}
+
+// buildDeltaCrl stores a delta CRL over the last stored complete CRL of every issuer with delta URLs,
+// the base revocations are read from the stored CRL, so any instance can build it.
+func (v *API) buildDeltaCrl(ctx context.Context, _ time.Time) error {
+	for _, cert := range v.certStore.List() {
+		if len(cert.DeltaCRLs) == 0 {
+			continue
+		}
+
+		issuer := cert.Issuer.Crt.Issuer.String()
+
+		keyHashB, err := cert.Issuer.IssuerKeyHash(entity.Hash)
+		if err != nil {
+			logx.Error("Failed to get issuer key hash", "issuer", issuer, "err", err)
+			continue
+		}
+		keyHash := hex.EncodeToString(keyHashB)
+
+		base, err := v.entityRepo.SelectCrlLast(ctx, keyHash, true)
+		if err != nil {
+			logx.Error("Failed to get base crl", "issuer", issuer, "err", err)
+			continue
+		}
+		if base == nil {
+			continue
+		}
+		serials, err := crlSerials(base.Data)
+		if err != nil {
+			logx.Error("Failed to get base crl", "issuer", issuer, "number", base.Number, "err", err)
+			continue
+		}
+
+		claim, err := v.entityRepo.ClaimCrl(ctx, keyHash, base.Number, v.conf.CRL.DeltaInterval)
+		if err != nil {
+			logx.Error("Failed to claim delta crl number", "issuer", issuer, "err", err)
+			continue
+		}
+		if claim == nil {
+			continue
+		}
+
+		result, err := v.entityRepo.SelectCertRevoked(ctx, keyHash)
+		if err != nil {
+			logx.Error("Failed to get revoked certs", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
+
+		delta := make([]x509.RevocationListEntry, 0, 2)
+		for _, item := range result {
+			if _, ok := serials[item.SerialNumber.String()]; !ok {
+				delta = append(delta, item)
+			}
+		}
+
+		nextUpdate := v.conf.CRL.DeltaInterval + 2*crlCheckInterval
+		b, err := certs.NewDeltaCRL(cert.Issuer, claim.Number, base.Number, nextUpdate, delta)
+		if err != nil {
+			logx.Error("Failed to build delta crl", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
+
+		if err = v.storeCrl(ctx, claim, b); err != nil {
+			logx.Error("Failed to store delta crl", "issuer", issuer, "err", err)
+			v.releaseCrl(ctx, claim, issuer)
+			continue
+		}
+		deltaCrlCache.Del(keyHash)
+	}
+
+	return nil
+}
+
+func crlSerials(b []byte) (map[string]struct{}, error) {
+	crl, err := x509.ParseRevocationList(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode crl: %w", err)
+	}
+	result := make(map[string]struct{}, len(crl.RevokedCertificateEntries))
+	for _, item := range crl.RevokedCertificateEntries {
+		result[item.SerialNumber.String()] = struct{}{}
+	}
+	return result, nil
+}
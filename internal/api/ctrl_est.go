@@ -0,0 +1,325 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.osspkg.com/encrypt/pki"
+	"go.osspkg.com/errors"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/access"
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+const (
+	estPathCACerts        = "/.well-known/est/cacerts"
+	estPathSimpleEnroll   = "/.well-known/est/simpleenroll"
+	estPathSimpleReenroll = "/.well-known/est/simplereenroll"
+	estPathCSRAttrs       = "/.well-known/est/csrattrs"
+
+	estContentTypeCerts = "application/pkcs7-mime; smime-type=certs-only"
+	estMaxRequestSize   = 64 << 10
+)
+
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errConflict     = errors.New("conflict")
+)
+
+func (v *API) addEstHandlers() {
+	v.apiRoute.Get(estPathCACerts, v.EstCACerts)
+	v.apiRoute.Post(estPathSimpleEnroll, v.EstSimpleEnroll)
+	v.apiRoute.Post(estPathSimpleReenroll, v.EstSimpleReenroll)
+	v.apiRoute.Get(estPathCSRAttrs, v.EstCSRAttrs)
+}
+
+// estAuthenticate supports HTTP basic auth with the account token id and key,
+// and TLS client certificates issued by casper. The certificate is returned for client cert auth only.
+func (v *API) estAuthenticate(wc web.Ctx) (*entity.Auth, *x509.Certificate, bool) {
+	auth, crt, code, err := v.estAuth(wc.Request())
+	if err != nil {
+		if code == http.StatusUnauthorized {
+			wc.Response().Header().Set("WWW-Authenticate", `Basic realm="casper est"`)
+		}
+		wc.Error(code, err)
+		return nil, nil, false
+	}
+	return auth, crt, true
+}
+
+// estAuth takes the client certificate of a TLS connection or of the mTLS listener only,
+// a certificate header of anyone else is ignored and basic auth is required.
+func (v *API) estAuth(r *http.Request) (*entity.Auth, *x509.Certificate, int, error) {
+	if crt := v.clientCertificate(r); crt != nil {
+		auth, err := v.clientCertAuth(r.Context(), crt)
+		if err != nil {
+			logx.Warn("EST client certificate rejected", "serial", crt.SerialNumber, "err", err)
+			return nil, nil, http.StatusUnauthorized, errUnauthorized
+		}
+		return auth, crt, http.StatusOK, nil
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil, http.StatusUnauthorized, errUnauthorized
+	}
+
+	id, err := uuid.Parse(user)
+	if err != nil {
+		return nil, nil, http.StatusUnauthorized, errUnauthorized
+	}
+
+	auth, err := v.entityRepo.SelectAuthByTokenId(r.Context(), id)
+	if err != nil {
+		logx.Error("failed to fetch auth by token id", "id", id, "err", err)
+		return nil, nil, http.StatusInternalServerError, errInternalError
+	}
+
+	if len(auth) != 1 || auth[0].Locked || len(auth[0].Domains) == 0 ||
+		subtle.ConstantTimeCompare([]byte(auth[0].TokenKey), []byte(pass)) != 1 {
+		return nil, nil, http.StatusUnauthorized, errUnauthorized
+	}
+
+	return &auth[0], nil, http.StatusOK, nil
+}
+
+// clientCertAuth accepts a certificate signed by one of the configured CA,
+// known to the database, not revoked and owned by an active account.
+func (v *API) clientCertAuth(ctx context.Context, crt *x509.Certificate) (*entity.Auth, error) {
+	now := time.Now()
+	if now.Before(crt.NotBefore) || now.After(crt.NotAfter) {
+		return nil, fmt.Errorf("certificate is expired or not yet valid")
+	}
+
+	idx := slices.IndexFunc(v.certStore.List(), func(ca *certs.Certificate) bool {
+		return crt.CheckSignatureFrom(ca.Issuer.Crt) == nil
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("certificate is not issued by casper")
+	}
+
+	list, err := v.entityRepo.SelectCertBySerialNumber(ctx, crt.SerialNumber.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch certificate: %w", err)
+	}
+	if len(list) != 1 || list[0].Revoked {
+		return nil, fmt.Errorf("certificate is unknown or revoked")
+	}
+
+	fp, err := (&pki.Certificate{Crt: crt}).FingerPrint(entity.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate fingerprint: %w", err)
+	}
+	if list[0].FingerPrint != hex.EncodeToString(fp) {
+		return nil, fmt.Errorf("certificate fingerprint mismatch")
+	}
+
+	auth, err := v.entityRepo.SelectAuthByID(ctx, list[0].Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if len(auth) != 1 || auth[0].Locked || len(auth[0].Domains) == 0 {
+		return nil, fmt.Errorf("account not found or locked")
+	}
+
+	return &auth[0], nil
+}
+
+func (v *API) estWriteCerts(wc web.Ctx, list ...*x509.Certificate) {
+	b, err := certs.MarshalCertsOnlyPKCS7(list...)
+	if err != nil {
+		logx.Error("failed to encode pkcs7", "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	wc.Response().Header().Set("Content-Type", estContentTypeCerts)
+	wc.Response().Header().Set("Content-Transfer-Encoding", "base64")
+	wc.Response().WriteHeader(http.StatusOK)
+	if _, err = wc.Response().Write([]byte(base64.StdEncoding.EncodeToString(b))); err != nil {
+		logx.Error("failed to write est response", "err", err)
+	}
+}
+
+func (v *API) EstCACerts(wc web.Ctx) {
+	var list []*x509.Certificate
+	for _, ca := range v.certStore.List() {
+		for _, crt := range ca.ChainCerts() {
+			if !slices.ContainsFunc(list, func(c *x509.Certificate) bool { return bytes.Equal(c.Raw, crt.Raw) }) {
+				list = append(list, crt)
+			}
+		}
+	}
+
+	v.estWriteCerts(wc, list...)
+}
+
+func (v *API) EstCSRAttrs(wc web.Ctx) {
+	wc.Response().WriteHeader(http.StatusNoContent)
+}
+
+// EstSimpleEnroll with a client certificate is limited to the identities of that certificate,
+// other names of the account need basic auth with the account token.
+func (v *API) EstSimpleEnroll(wc web.Ctx) {
+	auth, crt, ok := v.estAuthenticate(wc)
+	if !ok {
+		return
+	}
+
+	v.estEnroll(wc, auth, crt, false)
+}
+
+// EstSimpleReenroll requires the same identities as the presented certificate when
+// the client authenticates with it (RFC 7030 4.2.2).
+func (v *API) EstSimpleReenroll(wc web.Ctx) {
+	auth, crt, ok := v.estAuthenticate(wc)
+	if !ok {
+		return
+	}
+
+	v.estEnroll(wc, auth, crt, true)
+}
+
+// estEnroll issues the certificate, with client cert auth the CSR must request the identities
+// of the presented certificate: all of them on reenroll, a subset of them on enroll.
+func (v *API) estEnroll(wc web.Ctx, auth *entity.Auth, current *x509.Certificate, reenroll bool) {
+	body, err := io.ReadAll(io.LimitReader(wc.Request().Body, estMaxRequestSize))
+	if err != nil {
+		wc.Error(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		wc.Error(http.StatusBadRequest, fmt.Errorf("invalid base64 csr: %w", err))
+		return
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		wc.Error(http.StatusBadRequest, fmt.Errorf("invalid csr: %w", err))
+		return
+	}
+	if err = csr.CheckSignature(); err != nil {
+		wc.Error(http.StatusBadRequest, fmt.Errorf("invalid csr signature: %w", err))
+		return
+	}
+
+	if err = v.validateCRS(csr); err != nil {
+		wc.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case current == nil:
+	case reenroll && !sameIdentities(current, csr):
+		wc.Error(http.StatusBadRequest, fmt.Errorf("csr names do not match the current certificate"))
+		return
+	case !reenroll && !coveredIdentities(current, csr):
+		wc.Error(http.StatusForbidden, fmt.Errorf("csr names are not in the client certificate, use basic auth"))
+		return
+	}
+
+	denied, err := checkCSRAccess(auth.Domains, csr)
+	if err != nil {
+		logx.Error("failed to parse user access domains", "ownerId", auth.ID, "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	if len(denied) > 0 {
+		wc.Error(http.StatusForbidden, fmt.Errorf("denied: %s", strings.Join(denied, "; ")))
+		return
+	}
+
+	lookupDomains, trustDomains := csrLookupNames(csr)
+	ca, err := v.getRootCertificate(lookupDomains, trustDomains...)
+	if err != nil {
+		wc.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	profile, err := v.getProfile("", auth.Profiles)
+	if err != nil {
+		wc.Error(http.StatusForbidden, err)
+		return
+	}
+
+	result, err := v.issueCertificate(wc.Context(), auth.ID, ca, profile, csr, true)
+	if msg, ok := policyError(err); ok {
+		wc.Error(http.StatusBadRequest, errors.New(msg))
+		return
+	}
+	if err != nil {
+		logx.Error("failed to issue est certificate", "names", csrIdentities(csr), "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	if result.Status != client.RenewalStatusIssued {
+		wc.Error(http.StatusConflict, errConflict)
+		return
+	}
+
+	crt, err := pki.UnmarshalCrtPEM([]byte(result.Cert))
+	if err != nil {
+		logx.Error("failed to decode issued certificate", "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("EST certificate issued", "serial", result.SerialNumber, "owner", auth.ID)
+
+	v.estWriteCerts(wc, crt)
+}
+
+// certIdentities returns every SAN of the certificate in the form stored for the certificate.
+func certIdentities(crt *x509.Certificate) []string {
+	result := make([]string, 0, len(crt.DNSNames)+len(crt.EmailAddresses)+len(crt.URIs))
+	result = append(result, crt.DNSNames...)
+	for _, email := range crt.EmailAddresses {
+		result = append(result, access.NormalizeEmail(email))
+	}
+	for _, uri := range crt.URIs {
+		result = append(result, access.NormalizeURI(uri))
+	}
+	return result
+}
+
+// sameIdentities reports whether the CSR requests exactly the SANs of the certificate.
+func sameIdentities(crt *x509.Certificate, csr *x509.CertificateRequest) bool {
+	want := certIdentities(crt)
+	got := csrIdentities(csr)
+	slices.Sort(want)
+	slices.Sort(got)
+	return slices.Equal(want, got)
+}
+
+// coveredIdentities reports whether every SAN requested by the CSR is a SAN of the certificate.
+func coveredIdentities(crt *x509.Certificate, csr *x509.CertificateRequest) bool {
+	have := certIdentities(crt)
+	for _, name := range csrIdentities(csr) {
+		if !slices.Contains(have, name) {
+			return false
+		}
+	}
+	return true
+}
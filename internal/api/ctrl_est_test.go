@@ -0,0 +1,97 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestEstIdentities(t *testing.T) {
+	crt := &x509.Certificate{
+		DNSNames:       []string{"a.example.com", "b.example.com"},
+		EmailAddresses: []string{"user@Example.com"},
+		URIs:           []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/ns/a"}},
+	}
+
+	tests := []struct {
+		name        string
+		dns         []string
+		emails      []string
+		uris        []string
+		wantSame    bool
+		wantCovered bool
+	}{
+		{name: "same", dns: []string{"b.example.com", "a.example.com"}, emails: []string{"user@example.com"},
+			uris: []string{"spiffe://example.com/ns/a"}, wantSame: true, wantCovered: true},
+		{name: "subset", dns: []string{"a.example.com"}, wantCovered: true},
+		{name: "only uri", uris: []string{"spiffe://example.com/ns/a"}, wantCovered: true},
+		{name: "other dns name", dns: []string{"a.example.com", "c.example.com"}},
+		{name: "other uri", dns: []string{"a.example.com"}, uris: []string{"spiffe://example.com/ns/b"}},
+		{name: "other email", emails: []string{"admin@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csr := &x509.CertificateRequest{DNSNames: tt.dns, EmailAddresses: tt.emails}
+			for _, raw := range tt.uris {
+				u, err := url.Parse(raw)
+				if err != nil {
+					t.Fatalf("url.Parse() error = %v", err)
+				}
+				csr.URIs = append(csr.URIs, u)
+			}
+
+			if got := sameIdentities(crt, csr); got != tt.wantSame {
+				t.Fatalf("sameIdentities() = %v, want %v", got, tt.wantSame)
+			}
+			if got := coveredIdentities(crt, csr); got != tt.wantCovered {
+				t.Fatalf("coveredIdentities() = %v, want %v", got, tt.wantCovered)
+			}
+		})
+	}
+}
+
+func TestEstAuthForwardedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	group := testCAGroup(t, dir, "CA Issuing", []string{"example.com"}, nil)
+	crt := mustParse(t, testLeaf(t, group, "host.example.com", x509.ExtKeyUsageClientAuth))
+
+	secret, err := mtlsProxySecret(MTLSConfig{})
+	if err != nil {
+		t.Fatalf("mtlsProxySecret() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		api  *API
+		auth string
+	}{
+		{name: "mtls disabled", api: &API{}},
+		{name: "mtls disabled with a secret", api: &API{}, auth: string(secret)},
+		{name: "header without secret", api: &API{proxySecret: secret}},
+		{name: "header with wrong secret", api: &API{proxySecret: secret}, auth: "guess"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{estPathSimpleEnroll, estPathSimpleReenroll} {
+				r := httptest.NewRequest(http.MethodPost, path, nil)
+				r.RemoteAddr = "127.0.0.1:4000"
+				r.Header.Set(clientCertHeader, encodeClientCert(crt))
+				if len(tt.auth) > 0 {
+					r.Header.Set(clientCertAuthHeader, tt.auth)
+				}
+
+				auth, got, code, err := tt.api.estAuth(r)
+				if err == nil || code != http.StatusUnauthorized || auth != nil || got != nil {
+					t.Fatalf("%s: estAuth() = %v, %v, %d, %v, want unauthorized", path, auth, got, code, err)
+				}
+			}
+		})
+	}
+}
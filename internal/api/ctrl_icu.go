@@ -14,11 +14,11 @@ import (
 	"go.osspkg.com/logx"
 )
 
-func (v *API) addIcuHandlers() {
+func (v *API) addIcuHandlers(routes pkiRoutes) {
 	for _, cert := range v.certStore.List() {
 		issuer := cert.Issuer.Crt.Issuer.String()
 
-		for _, addr := range cert.Issuer.Crt.IssuingCertificateURL {
+		for _, addr := range cert.ICUs {
 			uri, err := url.ParseRequestURI(addr)
 			if err != nil {
 				logx.Error("Failed to parse issuing server URI", "issuer", issuer, "url", addr, "err", err)
@@ -27,7 +27,7 @@ func (v *API) addIcuHandlers() {
 
 			logx.Info("Adding issuing server URL", "issuer", issuer, "url", uri.Path)
 
-			v.pkiRoute.Get(uri.Path, func() func(ctx web.Ctx) {
+			routes.add(http.MethodGet, uri.Path, issuer, func() func(ctx web.Ctx) {
 				der := pki.MarshalCrtDER(*cert.Issuer.Crt)
 				issuer := issuer
 
@@ -7,37 +7,48 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"go.osspkg.com/encrypt/pki"
 	"go.osspkg.com/goppy/v2/web"
 	"go.osspkg.com/logx"
+	"golang.org/x/crypto/ocsp"
 
 	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/certs"
 )
 
-func (v *API) addOCSPHandlers() {
+const ocspMaxRequestSize = 16 << 10
+
+func (v *API) addOCSPHandlers(routes pkiRoutes) {
 	for _, cert := range v.certStore.List() {
+		if len(cert.OCSPs) == 0 {
+			continue
+		}
+
 		issuer := cert.Issuer.Crt.Issuer.String()
 
-		srv := &pki.OCSPServer{
-			CA: *cert.Issuer,
-			Resolver: &ocspStatusResolve{
-				repo:   v.entityRepo,
-				cert:   *cert.Issuer,
-				issuer: issuer,
-			},
-			UpdateInterval: 59 * time.Minute,
-			OnError: func(err error) {
-				logx.Error("Failed OCSP server request", "issuer", issuer, "err", err)
-			},
+		resp, err := ocspResponderFor(cert, issuer, v.conf.OCSP)
+		if err != nil {
+			logx.Error("Failed to create OCSP responder", "issuer", issuer, "err", err)
+			continue
+		}
+		resolver := &ocspStatusResolve{
+			repo:   v.entityRepo,
+			cert:   *cert.Issuer,
+			issuer: issuer,
 		}
 
-		for _, addr := range cert.Issuer.Crt.OCSPServer {
+		for _, addr := range cert.OCSPs {
 			uri, err := url.ParseRequestURI(addr)
 			if err != nil {
 				logx.Error("Failed to parse OCSP server URI", "issuer", issuer, "url", addr, "err", err)
@@ -46,13 +57,104 @@ func (v *API) addOCSPHandlers() {
 
 			logx.Info("Adding OCSP server URL", "issuer", issuer, "url", uri.Path)
 
-			v.pkiRoute.Post(uri.Path, func(ctx web.Ctx) {
-				srv.HTTPHandler(ctx.Response(), ctx.Request())
-			})
+			prefix := strings.TrimSuffix(uri.Path, "/") + "/"
+			routes.add(http.MethodPost, uri.Path, issuer, v.ocspHandler(resp, resolver, ""))
+			// RFC 6960 A.1: GET appends the base64 encoded request to the URL, "#" matches the rest of the path
+			routes.add(http.MethodGet, prefix+"#", issuer, v.ocspHandler(resp, resolver, prefix))
 		}
 	}
 }
 
+// ocspHandler answers from the pre-signed responses, the database is used only
+// until the first load of the issuer statuses. Empty prefix reads the request from the POST body.
+func (v *API) ocspHandler(resp *ocspResponder, resolver *ocspStatusResolve, prefix string) func(ctx web.Ctx) {
+	return func(ctx web.Ctx) {
+		v.serveOCSP(ctx.Response(), ctx.Request(), resp, resolver, prefix)
+	}
+}
+
+func (v *API) serveOCSP(w http.ResponseWriter, r *http.Request, resp *ocspResponder, resolver *ocspStatusResolve, prefix string) {
+	var (
+		der  []byte
+		err  error
+		name = resp.issuerName()
+	)
+	if prefix == "" {
+		der, err = io.ReadAll(io.LimitReader(r.Body, ocspMaxRequestSize))
+	} else {
+		der, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(r.URL.Path, prefix))
+	}
+	if err != nil {
+		writeOCSP(w, r, name, ocsp.MalformedRequestErrorResponse, nil)
+		return
+	}
+
+	req, err := ocsp.ParseRequest(der)
+	if err != nil {
+		writeOCSP(w, r, name, ocsp.MalformedRequestErrorResponse, nil)
+		return
+	}
+
+	nonce, err := certs.OCSPRequestNonce(der)
+	if err != nil {
+		writeOCSP(w, r, name, ocsp.MalformedRequestErrorResponse, nil)
+		return
+	}
+	if v.conf.OCSP.PreferCached {
+		nonce = nil
+	}
+
+	signed, err := resp.Response(r.Context(), req, resolver, nonce)
+	switch {
+	case errors.Is(err, errOCSPUnauthorized):
+		writeOCSP(w, r, name, ocsp.UnauthorizedErrorResponse, nil)
+		return
+	case errors.Is(err, errOCSPTryLater):
+		writeOCSP(w, r, name, ocsp.TryLaterErrorResponse, nil)
+		return
+	case err != nil:
+		logx.Error("Failed OCSP server request", "issuer", name, "serial", req.SerialNumber, "err", err)
+		writeOCSP(w, r, name, ocsp.InternalErrorErrorResponse, nil)
+		return
+	}
+
+	// a response with a nonce is signed for the request and must not be cached
+	if prefix == "" || nonce != nil {
+		writeOCSP(w, r, name, signed.der, nil)
+		return
+	}
+	writeOCSP(w, r, name, signed.der, signed)
+}
+
+// writeOCSP sends the response, a signed one of a GET request gets the caching headers of RFC 5019 6.2.
+func writeOCSP(w http.ResponseWriter, r *http.Request, issuer string, b []byte, signed *ocspSigned) {
+	w.Header().Set("Content-Type", "application/ocsp-response")
+
+	if signed != nil {
+		maxAge := int(time.Until(signed.nextUpdate).Seconds())
+		if maxAge < 0 {
+			maxAge = 0
+		}
+		sum := sha256.Sum256(signed.der)
+		etag := "\"" + hex.EncodeToString(sum[:16]) + "\""
+
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d,public,no-transform,must-revalidate", maxAge))
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", signed.thisUpdate.UTC().Format(http.TimeFormat))
+		w.Header().Set("Expires", signed.nextUpdate.UTC().Format(http.TimeFormat))
+
+		if isNotModified(r, etag, signed.thisUpdate) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logx.Error("Failed to write OCSP response", "issuer", issuer, "err", err)
+	}
+}
+
 type ocspStatusResolve struct {
 	repo   *entity.Repo
 	cert   pki.Certificate
@@ -60,11 +162,11 @@ type ocspStatusResolve struct {
 }
 
 func (v *ocspStatusResolve) OCSPStatusResolve(ctx context.Context, r *pki.OCSPRequest) (*pki.OCSPResponse, error) {
-	id := r.SerialNumber.Int64()
+	id := r.SerialNumber.String()
 
 	data, err := v.repo.SelectCertBySerialNumber(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch status (%d): %w", id, err)
+		return nil, fmt.Errorf("failed to fetch status (%s): %w", id, err)
 	}
 
 	if len(data) == 0 {
@@ -76,7 +178,7 @@ func (v *ocspStatusResolve) OCSPStatusResolve(ctx context.Context, r *pki.OCSPRe
 		reqVal, reqErr := v.cert.IssuerKeyHash(r.HashAlgorithm)
 
 		if caErr != nil || reqErr != nil {
-			return nil, fmt.Errorf("failed create issuer key hash (%d): %w", id, err)
+			return nil, fmt.Errorf("failed create issuer key hash (%s): %w", id, err)
 		}
 
 		if data[0].IssuerKeyHash != hex.EncodeToString(dbVal) ||
@@ -91,7 +193,7 @@ func (v *ocspStatusResolve) OCSPStatusResolve(ctx context.Context, r *pki.OCSPRe
 
 		if caErr != nil || reqErr != nil {
 			logx.Error("Failed to get issuer name hash", "issuer", v.issuer, "err", errors.Join(caErr, reqErr))
-			return nil, fmt.Errorf("failed create issuer name hash (%d): %w", id, err)
+			return nil, fmt.Errorf("failed create issuer name hash (%s): %w", id, err)
 		}
 
 		if data[0].IssuerNameHash != hex.EncodeToString(dbVal) ||
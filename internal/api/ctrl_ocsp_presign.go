@@ -0,0 +1,513 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+	"go.osspkg.com/logx"
+	"go.osspkg.com/syncing"
+	"golang.org/x/crypto/ocsp"
+
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+const (
+	// statuses changed since the last sync are read that often, all of them once per full interval
+	ocspSyncInterval     = time.Minute
+	ocspFullSyncInterval = time.Hour
+	// RFC 5019 2.1.1: the lightweight profile uses SHA-1 for the CertID
+	ocspPresignHash = crypto.SHA1
+)
+
+// ocspResponders keeps the responders by issuer key hash, so a reload keeps the signed responses.
+var ocspResponders = syncing.NewMap[string, *ocspResponder](10)
+
+var (
+	// errOCSPUnauthorized is the answer for a CertID of another issuer (RFC 5019 2.2.3)
+	errOCSPUnauthorized = errors.New("ocsp request is not for the issuer")
+	// errOCSPTryLater is the answer when the live signatures are over the limit
+	errOCSPTryLater = errors.New("ocsp live signing limit is reached")
+)
+
+type ocspStatus struct {
+	status    int
+	revokedAt time.Time
+	reason    int
+}
+
+type ocspSigned struct {
+	der        []byte
+	thisUpdate time.Time
+	nextUpdate time.Time
+}
+
+type ocspEntry struct {
+	ocspStatus
+	signed map[crypto.Hash]*ocspSigned
+}
+
+// ocspResponder holds the statuses of live certs of an issuer with their signed responses.
+// Until the first load is done, requests are resolved with the database.
+// Responses which can not be pre-signed, for unknown serials and nonces, are signed live
+// within conf.LiveSignRate.
+type ocspResponder struct {
+	keyHash string
+
+	mux      sync.RWMutex
+	name     string
+	nameHash string
+	conf     OCSPConfig
+	window   certs.OCSPWindow
+	issuer   *pki.Certificate
+	delegate *pki.Certificate
+	entries  map[string]*ocspEntry
+	loaded   bool
+	fullAt   time.Time
+	syncedAt time.Time
+
+	rotateMux sync.Mutex
+
+	liveMux    sync.Mutex
+	liveTokens float64
+	liveAt     time.Time
+}
+
+func ocspResponderFor(cert *certs.Certificate, name string, conf OCSPConfig) (*ocspResponder, error) {
+	issuer := cert.Issuer
+
+	keyHash, err := issuer.IssuerKeyHash(entity.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issuer key hash: %w", err)
+	}
+	nameHash, err := issuer.IssuerNameHash(entity.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issuer name hash: %w", err)
+	}
+
+	key := hex.EncodeToString(keyHash)
+	if resp, ok := ocspResponders.Get(key); ok {
+		resp.update(cert, name, hex.EncodeToString(nameHash), conf)
+		return resp, nil
+	}
+
+	resp := &ocspResponder{
+		name:     name,
+		keyHash:  key,
+		nameHash: hex.EncodeToString(nameHash),
+		conf:     conf,
+		window:   cert.OCSP,
+		issuer:   issuer,
+		entries:  make(map[string]*ocspEntry),
+	}
+	ocspResponders.Set(key, resp)
+	return resp, nil
+}
+
+// update applies the reloaded issuer config. The delegated certificate is dropped when the issuer
+// certificate or the delegation changes, the signed responses when anything they carry changes,
+// and a new issuer name hash loads the statuses again.
+func (r *ocspResponder) update(cert *certs.Certificate, name, nameHash string, conf OCSPConfig) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	sameIssuer := bytes.Equal(r.issuer.Crt.Raw, cert.Issuer.Crt.Raw)
+	if !sameIssuer || r.conf.Delegated != conf.Delegated || r.conf.ResponderValidity != conf.ResponderValidity {
+		r.delegate = nil
+	}
+	if !sameIssuer || r.conf.Delegated != conf.Delegated || r.window != cert.OCSP {
+		for _, entry := range r.entries {
+			entry.signed = make(map[crypto.Hash]*ocspSigned, 1)
+		}
+	}
+	if r.nameHash != nameHash {
+		r.entries = make(map[string]*ocspEntry)
+		r.loaded = false
+	}
+
+	r.name = name
+	r.nameHash = nameHash
+	r.conf = conf
+	r.window = cert.OCSP
+	r.issuer = cert.Issuer
+}
+
+func (r *ocspResponder) issuerName() string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.name
+}
+
+// allowLive takes a token of the live signing bucket, it holds a second of LiveSignRate.
+func (r *ocspResponder) allowLive() bool {
+	r.mux.RLock()
+	limit := float64(r.conf.LiveSignRate)
+	r.mux.RUnlock()
+	if limit <= 0 {
+		return true
+	}
+
+	r.liveMux.Lock()
+	defer r.liveMux.Unlock()
+
+	now := time.Now()
+	if r.liveAt.IsZero() {
+		r.liveTokens = limit
+	} else {
+		r.liveTokens = min(limit, r.liveTokens+now.Sub(r.liveAt).Seconds()*limit)
+	}
+	r.liveAt = now
+
+	if r.liveTokens < 1 {
+		return false
+	}
+	r.liveTokens--
+	return true
+}
+
+// Response returns the signed response of the request, the serials missing in the loaded statuses are unknown.
+// A response with the nonce is signed for the request, otherwise the pre-signed one is used.
+// Live signatures over the limit fail with errOCSPTryLater, a nonce over the limit is ignored
+// as RFC 8954 allows. A request for another issuer fails with errOCSPUnauthorized.
+func (r *ocspResponder) Response(
+	ctx context.Context, req *ocsp.Request, resolver *ocspStatusResolve, nonce *pkix.Extension,
+) (*ocspSigned, error) {
+	serial := req.SerialNumber
+	status := ocspStatus{status: ocsp.Unknown}
+
+	ok, err := r.matchIssuer(req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errOCSPUnauthorized
+	}
+
+	r.mux.RLock()
+	loaded := r.loaded
+	entry, found := r.entries[serial.String()]
+	var signed *ocspSigned
+	if found {
+		status = entry.ocspStatus
+		signed = entry.signed[req.HashAlgorithm]
+	}
+	r.mux.RUnlock()
+
+	if !loaded {
+		if !r.allowLive() {
+			return nil, errOCSPTryLater
+		}
+		result, err := resolver.OCSPStatusResolve(ctx, &pki.OCSPRequest{
+			HashAlgorithm:  req.HashAlgorithm,
+			IssuerNameHash: req.IssuerNameHash,
+			IssuerKeyHash:  req.IssuerKeyHash,
+			SerialNumber:   req.SerialNumber,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return r.sign(serial, ocspStatus{
+			status:    int(result.Status),
+			revokedAt: result.RevokedAt,
+			reason:    int(result.RevocationReason),
+		}, req.HashAlgorithm, nonce)
+	}
+
+	if !found {
+		if !r.allowLive() {
+			return nil, errOCSPTryLater
+		}
+		return r.sign(serial, status, req.HashAlgorithm, nonce)
+	}
+	if nonce != nil && r.allowLive() {
+		return r.sign(serial, status, req.HashAlgorithm, nonce)
+	}
+	if signed != nil && time.Now().Before(signed.nextUpdate) {
+		return signed, nil
+	}
+
+	if signed, err = r.sign(serial, status, req.HashAlgorithm, nil); err != nil {
+		return nil, err
+	}
+	r.store(serial, entry, req.HashAlgorithm, signed)
+	return signed, nil
+}
+
+func (r *ocspResponder) matchIssuer(req *ocsp.Request) (bool, error) {
+	r.mux.RLock()
+	issuer := r.issuer
+	r.mux.RUnlock()
+
+	keyHash, err := issuer.IssuerKeyHash(req.HashAlgorithm)
+	if err != nil {
+		return false, fmt.Errorf("failed to create issuer key hash: %w", err)
+	}
+	nameHash, err := issuer.IssuerNameHash(req.HashAlgorithm)
+	if err != nil {
+		return false, fmt.Errorf("failed to create issuer name hash: %w", err)
+	}
+	return bytes.Equal(keyHash, req.IssuerKeyHash) && bytes.Equal(nameHash, req.IssuerNameHash), nil
+}
+
+// responder returns the certificate signing the responses. The delegated one is issued again
+// when a third of its validity is left, without delegation or if it can not be issued the CA signs.
+func (r *ocspResponder) responder() *pki.Certificate {
+	fresh := func(issuer, delegate *pki.Certificate, conf OCSPConfig) bool {
+		if delegate == nil {
+			return false
+		}
+		// the delegated certificate is cut to the CA expiry, a new one would be the same
+		return time.Now().Before(delegate.Crt.NotAfter.Add(-conf.ResponderValidity/3)) ||
+			!delegate.Crt.NotAfter.Before(issuer.Crt.NotAfter)
+	}
+
+	r.mux.RLock()
+	issuer, delegate, conf := r.issuer, r.delegate, r.conf
+	r.mux.RUnlock()
+
+	if !conf.Delegated {
+		return issuer
+	}
+	if fresh(issuer, delegate, conf) {
+		return delegate
+	}
+
+	r.rotateMux.Lock()
+	defer r.rotateMux.Unlock()
+
+	r.mux.RLock()
+	issuer, delegate, conf = r.issuer, r.delegate, r.conf
+	r.mux.RUnlock()
+	if fresh(issuer, delegate, conf) {
+		return delegate
+	}
+
+	crt, err := certs.NewOCSPResponder(issuer, conf.ResponderValidity)
+	if err != nil {
+		logx.Error("Failed to issue OCSP responder certificate", "issuer", r.issuerName(), "err", err)
+		if delegate != nil && time.Now().Before(delegate.Crt.NotAfter) {
+			return delegate
+		}
+		return issuer
+	}
+	logx.Info("Issued OCSP responder certificate", "issuer", r.issuerName(),
+		"serial", crt.Crt.SerialNumber, "not_after", crt.Crt.NotAfter)
+
+	r.mux.Lock()
+	r.delegate = crt
+	r.mux.Unlock()
+	return crt
+}
+
+func (r *ocspResponder) sign(serial *big.Int, status ocspStatus, hash crypto.Hash, nonce *pkix.Extension) (*ocspSigned, error) {
+	r.mux.RLock()
+	issuer, window := r.issuer, r.window
+	r.mux.RUnlock()
+	responder := r.responder()
+
+	now := time.Now()
+	tmpl := ocsp.Response{
+		Status:       status.status,
+		SerialNumber: serial,
+		ThisUpdate:   now.Add(-window.Backdate),
+		NextUpdate:   now.Add(window.Validity),
+		IssuerHash:   hash,
+	}
+	if status.status == ocsp.Revoked {
+		tmpl.RevokedAt = status.revokedAt
+		tmpl.RevocationReason = status.reason
+	}
+	if responder != issuer {
+		// RFC 6960 4.2.2.2: clients get the delegated certificate with the response
+		tmpl.Certificate = responder.Crt
+		if tmpl.NextUpdate.After(responder.Crt.NotAfter) {
+			tmpl.NextUpdate = responder.Crt.NotAfter
+		}
+	}
+
+	if nonce != nil {
+		tmpl.ExtraExtensions = []pkix.Extension{*nonce}
+	}
+
+	der, err := certs.CreateOCSPResponse(issuer.Crt, responder.Crt, tmpl, responder.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign OCSP response (%s): %w", serial, err)
+	}
+	return &ocspSigned{der: der, thisUpdate: tmpl.ThisUpdate, nextUpdate: tmpl.NextUpdate}, nil
+}
+
+// store keeps the signed response if the entry has not been replaced by a sync meanwhile.
+func (r *ocspResponder) store(serial *big.Int, entry *ocspEntry, hash crypto.Hash, signed *ocspSigned) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.entries[serial.String()] == entry {
+		entry.signed[hash] = signed
+	}
+}
+
+// sync reads the statuses changed since the last sync, or all of them once per full interval.
+func (r *ocspResponder) sync(ctx context.Context, repo *entity.Repo) error {
+	r.mux.RLock()
+	full := !r.loaded || time.Since(r.fullAt) > ocspFullSyncInterval
+	// overlap covers transactions committed after the last sync with an earlier updated_at
+	since := r.syncedAt.Add(-ocspSyncInterval)
+	r.mux.RUnlock()
+
+	if full {
+		since = time.Time{}
+	}
+
+	start := time.Now()
+	list, err := repo.SelectCertStatusByIssuer(ctx, r.keyHash, since)
+	if err != nil {
+		return fmt.Errorf("failed to get cert statuses: %w", err)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	entries := r.entries
+	if full {
+		entries = make(map[string]*ocspEntry, len(list))
+	}
+	for _, item := range list {
+		if item.IssuerNameHash != r.nameHash {
+			continue
+		}
+		status := ocspStatus{status: ocsp.Good}
+		if item.Revoked {
+			status = ocspStatus{status: ocsp.Revoked, revokedAt: item.UpdatedAt, reason: int(item.RevokedReason)}
+		}
+		if prev, ok := r.entries[item.SerialNumber]; ok && prev.ocspStatus == status {
+			entries[item.SerialNumber] = prev
+			continue
+		}
+		entries[item.SerialNumber] = &ocspEntry{ocspStatus: status, signed: make(map[crypto.Hash]*ocspSigned, 1)}
+	}
+
+	r.entries = entries
+	r.syncedAt = start
+	if full {
+		r.fullAt = start
+		r.loaded = true
+	}
+	return nil
+}
+
+// presign signs the responses which are missing or close to the next update,
+// every entry has a SHA-1 response and keeps the ones of other hashes asked by clients.
+func (r *ocspResponder) presign() (int, error) {
+	type job struct {
+		serial *big.Int
+		entry  *ocspEntry
+		hash   crypto.Hash
+	}
+
+	r.mux.RLock()
+	jobs := make([]job, 0, 10)
+	// a pre-signed response is signed again when less than half of its validity is left
+	deadline := time.Now().Add(r.window.Validity / 2)
+	for key, entry := range r.entries {
+		serial, ok := new(big.Int).SetString(key, 10)
+		if !ok {
+			continue
+		}
+		if _, ok = entry.signed[ocspPresignHash]; !ok {
+			jobs = append(jobs, job{serial: serial, entry: entry, hash: ocspPresignHash})
+		}
+		for hash, signed := range entry.signed {
+			if signed.nextUpdate.Before(deadline) {
+				jobs = append(jobs, job{serial: serial, entry: entry, hash: hash})
+			}
+		}
+	}
+	r.mux.RUnlock()
+
+	for _, item := range jobs {
+		signed, err := r.sign(item.serial, item.entry.ocspStatus, item.hash, nil)
+		if err != nil {
+			return 0, err
+		}
+		r.store(item.serial, item.entry, item.hash, signed)
+	}
+	return len(jobs), nil
+}
+
+// refreshOCSP asks the pre-signer to sync the statuses now, it is called after a revocation or an issue.
+func (v *API) refreshOCSP() {
+	select {
+	case v.ocspRefresh <- struct{}{}:
+	default:
+	}
+}
+
+func (v *API) runOCSPPresigner(ctx context.Context) {
+	tik := time.NewTicker(ocspSyncInterval)
+	defer tik.Stop()
+
+	for {
+		v.presignOCSP(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tik.C:
+		case <-v.ocspRefresh:
+		}
+	}
+}
+
+func (v *API) presignOCSP(ctx context.Context) {
+	active := make(map[string]struct{}, 2)
+
+	for _, cert := range v.certStore.List() {
+		issuer := cert.Issuer.Crt.Issuer.String()
+
+		keyHashB, err := cert.Issuer.IssuerKeyHash(entity.Hash)
+		if err != nil {
+			logx.Error("Failed to get issuer key hash", "issuer", issuer, "err", err)
+			continue
+		}
+		keyHash := hex.EncodeToString(keyHashB)
+		active[keyHash] = struct{}{}
+
+		resp, ok := ocspResponders.Get(keyHash)
+		if !ok {
+			continue
+		}
+		resp.responder()
+		if err = resp.sync(ctx, v.entityRepo); err != nil {
+			logx.Error("Failed to sync OCSP statuses", "issuer", issuer, "err", err)
+			continue
+		}
+		count, err := resp.presign()
+		if err != nil {
+			logx.Error("Failed to pre-sign OCSP responses", "issuer", issuer, "err", err)
+			continue
+		}
+		if count > 0 {
+			logx.Info("Pre-signed OCSP responses", "issuer", issuer, "count", count)
+		}
+	}
+
+	for _, keyHash := range ocspResponders.Keys() {
+		if _, ok := active[keyHash]; !ok {
+			ocspResponders.Del(keyHash)
+		}
+	}
+}
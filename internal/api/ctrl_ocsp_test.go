@@ -0,0 +1,316 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+var testOIDOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+// testOCSPRequestASN1 and testOCSPResponseASN1 decode as much of RFC 6960 as the tests need:
+// request extensions are not written by the ocsp package, response extensions are not read by it.
+type testOCSPRequestASN1 struct {
+	TBSRequest struct {
+		RequestList []asn1.RawValue
+		Extensions  []pkix.Extension `asn1:"explicit,tag:2,optional"`
+	}
+}
+
+type testOCSPResponseASN1 struct {
+	Status   asn1.Enumerated
+	Response struct {
+		ResponseType asn1.ObjectIdentifier
+		Response     []byte
+	} `asn1:"explicit,tag:0,optional"`
+}
+
+type testOCSPBasicResponseASN1 struct {
+	TBSResponseData struct {
+		RawResponderID     asn1.RawValue
+		ProducedAt         time.Time `asn1:"generalized"`
+		Responses          []asn1.RawValue
+		ResponseExtensions []pkix.Extension `asn1:"optional,explicit,tag:1"`
+	}
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+func testOCSPResponseNonce(t *testing.T, der []byte) []byte {
+	t.Helper()
+
+	var (
+		resp  testOCSPResponseASN1
+		basic testOCSPBasicResponseASN1
+	)
+	if _, err := asn1.Unmarshal(der, &resp); err != nil {
+		t.Fatalf("failed to decode ocsp response: %v", err)
+	}
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil {
+		t.Fatalf("failed to decode basic ocsp response: %v", err)
+	}
+	for _, ext := range basic.TBSResponseData.ResponseExtensions {
+		if ext.Id.Equal(testOIDOCSPNonce) {
+			var nonce []byte
+			if _, err := asn1.Unmarshal(ext.Value, &nonce); err != nil {
+				t.Fatalf("failed to decode nonce: %v", err)
+			}
+			return nonce
+		}
+	}
+	return nil
+}
+
+func testOCSPRequestWithNonce(t *testing.T, plain, nonce []byte) []byte {
+	t.Helper()
+
+	var req testOCSPRequestASN1
+	if _, err := asn1.Unmarshal(plain, &req); err != nil {
+		t.Fatalf("failed to decode ocsp request: %v", err)
+	}
+	value, err := asn1.Marshal(nonce)
+	if err != nil {
+		t.Fatalf("failed to encode nonce: %v", err)
+	}
+	req.TBSRequest.Extensions = []pkix.Extension{{Id: testOIDOCSPNonce, Value: value}}
+	der, err := asn1.Marshal(req)
+	if err != nil {
+		t.Fatalf("failed to encode ocsp request: %v", err)
+	}
+	return der
+}
+
+func TestServeOCSPNonce(t *testing.T) {
+	dir := t.TempDir()
+	group := &certs.ConfigGroup{Certs: []certs.Config{
+		testCAGroup(t, dir, "CA Example", []string{"example.com"}, nil),
+	}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	issuer := store.List()[0].Issuer
+	crt := issuer.Crt
+
+	plain, err := ocsp.CreateRequest(crt, crt, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		t.Fatalf("failed to create ocsp request: %v", err)
+	}
+	withNonce := func(nonce []byte) []byte { return testOCSPRequestWithNonce(t, plain, nonce) }
+	nonce := []byte("0123456789abcdef")
+	largest := bytes.Repeat([]byte{1}, 32)
+
+	const prefix = "/ocsp/ca/"
+
+	tests := []struct {
+		name         string
+		method       string
+		request      []byte
+		preferCached bool
+		wantMalform  bool
+		wantNonce    []byte
+		wantCached   bool
+	}{
+		{name: "post echoes nonce", method: http.MethodPost, request: withNonce(nonce), wantNonce: nonce},
+		{name: "get echoes nonce without caching", method: http.MethodGet, request: withNonce(nonce), wantNonce: nonce},
+		{name: "get without nonce is cached", method: http.MethodGet, request: plain, wantCached: true},
+		{name: "post without nonce", method: http.MethodPost, request: plain},
+		{name: "largest nonce", method: http.MethodPost, request: withNonce(largest), wantNonce: largest},
+		{name: "oversize nonce", method: http.MethodPost, request: withNonce(append(largest, 1)), wantMalform: true},
+		{name: "empty nonce", method: http.MethodGet, request: withNonce([]byte{}), wantMalform: true},
+		{name: "prefer cached ignores nonce", method: http.MethodGet, request: withNonce(nonce), preferCached: true, wantCached: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &API{conf: &ConfigGroup{OCSP: OCSPConfig{PreferCached: tt.preferCached}}}
+			resp := &ocspResponder{
+				name:    "CA Example",
+				window:  certs.OCSPWindow{Validity: time.Hour},
+				issuer:  issuer,
+				entries: map[string]*ocspEntry{crt.SerialNumber.String(): {signed: map[crypto.Hash]*ocspSigned{}}},
+				loaded:  true,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, prefix+base64.StdEncoding.EncodeToString(tt.request), nil)
+			p := prefix
+			if tt.method == http.MethodPost {
+				r = httptest.NewRequest(http.MethodPost, "/ocsp/ca", bytes.NewReader(tt.request))
+				p = ""
+			}
+			w := httptest.NewRecorder()
+			v.serveOCSP(w, r, resp, nil, p)
+			checkOCSPResponse(t, w, crt.SerialNumber.String(), tt.wantMalform, tt.wantNonce, tt.wantCached)
+		})
+	}
+}
+
+func checkOCSPResponse(t *testing.T, w *httptest.ResponseRecorder, serial string, malform bool, nonce []byte, cached bool) {
+	t.Helper()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if malform {
+		if !bytes.Equal(w.Body.Bytes(), ocsp.MalformedRequestErrorResponse) {
+			t.Fatalf("response is not malformedRequest")
+		}
+		return
+	}
+
+	resp, err := ocsp.ParseResponse(w.Body.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("failed to verify ocsp response: %v", err)
+	}
+	if resp.Status != ocsp.Good || resp.SerialNumber.String() != serial {
+		t.Fatalf("ocsp response status %d serial %s, want good %s", resp.Status, resp.SerialNumber, serial)
+	}
+	if got := testOCSPResponseNonce(t, w.Body.Bytes()); !bytes.Equal(got, nonce) {
+		t.Fatalf("response nonce = %x, want %x", got, nonce)
+	}
+	if got := w.Header().Get("Cache-Control"); (len(got) > 0) != cached {
+		t.Fatalf("Cache-Control = %q, want cached %v", got, cached)
+	}
+	if got := w.Header().Get("ETag"); (len(got) > 0) != cached {
+		t.Fatalf("ETag = %q, want cached %v", got, cached)
+	}
+}
+
+func TestServeOCSPLiveLimit(t *testing.T) {
+	dir := t.TempDir()
+	group := &certs.ConfigGroup{Certs: []certs.Config{
+		testCAGroup(t, dir, "CA Example", []string{"example.com"}, nil),
+		testCAGroup(t, dir, "CA Other", []string{"example.org"}, nil),
+	}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	issuer, other := store.List()[0].Issuer, store.List()[1].Issuer
+	if issuer.Crt.Subject.CommonName != "CA Example" {
+		issuer, other = other, issuer
+	}
+
+	known, err := ocsp.CreateRequest(issuer.Crt, issuer.Crt, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		t.Fatalf("failed to create ocsp request: %v", err)
+	}
+	foreign, err := ocsp.CreateRequest(other.Crt, other.Crt, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		t.Fatalf("failed to create ocsp request: %v", err)
+	}
+
+	v := &API{conf: &ConfigGroup{}}
+	resp := &ocspResponder{
+		name:    "CA Example",
+		conf:    OCSPConfig{LiveSignRate: 2},
+		window:  certs.OCSPWindow{Validity: time.Hour},
+		issuer:  issuer,
+		entries: map[string]*ocspEntry{},
+		loaded:  true,
+	}
+	serve := func(der []byte) []byte {
+		r := httptest.NewRequest(http.MethodPost, "/ocsp/ca", bytes.NewReader(der))
+		w := httptest.NewRecorder()
+		v.serveOCSP(w, r, resp, nil, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		return w.Body.Bytes()
+	}
+
+	if got := serve(foreign); !bytes.Equal(got, ocsp.UnauthorizedErrorResponse) {
+		t.Fatalf("request of other issuer is not answered with unauthorized")
+	}
+	// the burst of the limit is signed live, the rest gets tryLater without a signature
+	for i := 0; i < 2; i++ {
+		parsed, err := ocsp.ParseResponse(serve(known), nil)
+		if err != nil {
+			t.Fatalf("failed to verify ocsp response %d: %v", i, err)
+		}
+		if parsed.Status != ocsp.Unknown {
+			t.Fatalf("ocsp response %d status = %d, want unknown", i, parsed.Status)
+		}
+	}
+	if got := serve(known); !bytes.Equal(got, ocsp.TryLaterErrorResponse) {
+		t.Fatalf("request over the live limit is not answered with tryLater")
+	}
+
+	// a known serial over the limit is answered with the pre-signed response without the nonce
+	resp.mux.Lock()
+	resp.entries[issuer.Crt.SerialNumber.String()] = &ocspEntry{
+		ocspStatus: ocspStatus{status: ocsp.Good},
+		signed:     map[crypto.Hash]*ocspSigned{},
+	}
+	resp.mux.Unlock()
+	withNonce := testOCSPRequestWithNonce(t, known, []byte("0123456789abcdef"))
+
+	r := httptest.NewRequest(http.MethodPost, "/ocsp/ca", bytes.NewReader(withNonce))
+	w := httptest.NewRecorder()
+	v.serveOCSP(w, r, resp, nil, "")
+	checkOCSPResponse(t, w, issuer.Crt.SerialNumber.String(), false, nil, false)
+}
+
+func TestOCSPResponderForReload(t *testing.T) {
+	dir := t.TempDir()
+	group := &certs.ConfigGroup{Certs: []certs.Config{
+		testCAGroup(t, dir, "CA Example", []string{"example.com"}, nil),
+	}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	cert := store.List()[0]
+
+	first, err := ocspResponderFor(cert, "CA Example", OCSPConfig{Delegated: true, ResponderValidity: time.Hour})
+	if err != nil {
+		t.Fatalf("ocspResponderFor() error = %v", err)
+	}
+	defer ocspResponders.Del(first.keyHash)
+
+	first.mux.Lock()
+	first.entries["1"] = &ocspEntry{
+		ocspStatus: ocspStatus{status: ocsp.Good},
+		signed:     map[crypto.Hash]*ocspSigned{crypto.SHA1: {der: []byte{1}}},
+	}
+	first.mux.Unlock()
+
+	reloaded := *cert
+	reloaded.OCSP = certs.OCSPWindow{Validity: 2 * time.Hour}
+	conf := OCSPConfig{Delegated: false, ResponderValidity: 2 * time.Hour, LiveSignRate: 5}
+	second, err := ocspResponderFor(&reloaded, "CA Renamed", conf)
+	if err != nil {
+		t.Fatalf("ocspResponderFor() error = %v", err)
+	}
+
+	if second != first {
+		t.Fatalf("ocspResponderFor() created a new responder for the same issuer")
+	}
+	if second.issuerName() != "CA Renamed" || second.conf != conf || second.window != reloaded.OCSP {
+		t.Fatalf("responder keeps name %q conf %+v window %+v after reload", second.name, second.conf, second.window)
+	}
+	if len(second.entries["1"].signed) != 0 {
+		t.Fatalf("responses signed with the old window are kept")
+	}
+	if second.responder() != cert.Issuer {
+		t.Fatalf("responder() signs with a delegated certificate after delegation is turned off")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+// pkiRoutes maps "METHOD path" of the CRL, OCSP and ICU endpoints to the handlers
+// of the current CA set. The router can not drop a route, so every path is registered
+// once with a dispatcher and a path removed by a reload answers 404.
+type pkiRoutes map[string]func(web.Ctx)
+
+// add keeps the first handler of a path. The store refuses a path shared by issuers of
+// another name or key, so a repeated path comes from a CA certified again with the same key
+// and either handler serves the same CRL and OCSP data.
+func (r pkiRoutes) add(method, path, issuer string, call func(web.Ctx)) {
+	key := method + " " + path
+	if _, ok := r[key]; ok {
+		logx.Warn("PKI route is served by another issuer", "issuer", issuer, "route", key)
+		return
+	}
+	r[key] = call
+}
+
+func (v *API) applyPkiRoutes() {
+	routes := make(pkiRoutes)
+	v.addCrlHandlers(routes)
+	v.addDeltaCrlHandlers(routes)
+	v.addIcuHandlers(routes)
+	v.addOCSPHandlers(routes)
+
+	v.pkiMux.Lock()
+	defer v.pkiMux.Unlock()
+
+	v.pkiTable.Store(&routes)
+
+	for key := range routes {
+		if _, ok := v.pkiRegistered[key]; ok {
+			continue
+		}
+		method, path, _ := strings.Cut(key, " ")
+		switch method {
+		case http.MethodGet:
+			v.pkiRoute.Get(path, v.pkiDispatch(key))
+		case http.MethodPost:
+			v.pkiRoute.Post(path, v.pkiDispatch(key))
+		}
+		v.pkiRegistered[key] = struct{}{}
+	}
+
+	for key := range v.pkiRegistered {
+		if _, ok := routes[key]; !ok {
+			logx.Info("PKI route is disabled", "route", key)
+		}
+	}
+}
+
+func (v *API) pkiDispatch(key string) func(web.Ctx) {
+	return func(ctx web.Ctx) {
+		call, ok := (*v.pkiTable.Load())[key]
+		if !ok {
+			ctx.Error(http.StatusNotFound, nil)
+			return
+		}
+		call(ctx)
+	}
+}
+
+// watchReload rebuilds the CA set on SIGHUP and, if enabled, when the config file
+// or a file it references changes. A config failing validation keeps the current set.
+func (v *API) watchReload(ctx context.Context) {
+	path, ok := certs.ServerConfigFile()
+	if !ok {
+		logx.Warn("Hot reload of certs is disabled, the server is started without --config")
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	conf := v.certStore.Config().Reload
+	var watch <-chan time.Time
+	if conf.Watch {
+		tik := time.NewTicker(conf.Interval)
+		defer tik.Stop()
+		watch = tik.C
+	}
+
+	version := certs.FilesVersion(path, v.certStore.Config())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logx.Info("Reloading certs", "reason", "signal", "config", path)
+		case <-watch:
+			if certs.FilesVersion(path, v.certStore.Config()) == version {
+				continue
+			}
+			logx.Info("Reloading certs", "reason", "files changed", "config", path)
+		}
+
+		if err := v.reloadCerts(ctx, path); err != nil {
+			logx.Error("Failed to reload certs, keep the current set", "config", path, "err", err)
+		} else {
+			logx.Info("Reloading certs", "status", "done", "issuers", len(v.certStore.List()))
+		}
+		version = certs.FilesVersion(path, v.certStore.Config())
+	}
+}
+
+func (v *API) reloadCerts(ctx context.Context, path string) error {
+	conf, err := certs.LoadConfigGroup(path)
+	if err != nil {
+		return err
+	}
+	if err = v.certStore.Reload(conf); err != nil {
+		return err
+	}
+	if v.conf.SCEP.Enabled {
+		if err = scepCheckIssuers(v.certStore.List()); err != nil {
+			logx.Error("SCEP requests to the reloaded issuers will fail", "err", err)
+		}
+	}
+	v.applyPkiRoutes()
+	return v.buildCrl(ctx, time.Now())
+}
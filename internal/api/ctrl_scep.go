@@ -0,0 +1,338 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+	"go.osspkg.com/goppy/v2/web"
+	"go.osspkg.com/logx"
+	"go.osspkg.com/routine/tick"
+
+	"go.arwos.org/casper/client"
+	"go.arwos.org/casper/internal/entity"
+	"go.arwos.org/casper/internal/pkgs/certs"
+	"go.arwos.org/casper/internal/pkgs/scep"
+)
+
+const (
+	scepPath = "/scep"
+
+	scepOperationGetCACaps    = "GetCACaps"
+	scepOperationGetCACert    = "GetCACert"
+	scepOperationPKIOperation = "PKIOperation"
+	scepContentTypeCACert     = "application/x-x509-ca-cert"
+	scepContentTypeCARACert   = "application/x-x509-ca-ra-cert"
+	scepContentTypePKIMessage = "application/x-pki-message"
+	scepContentTypeCaps       = "text/plain"
+)
+
+func (v *API) addScepHandlers() {
+	v.apiRoute.Get(scepPath, v.ScepGet)
+	v.apiRoute.Post(scepPath, v.ScepPost)
+}
+
+func (v *API) tickerConfigCleanScep() tick.Config {
+	return tick.Config{
+		Name:     "delete expired scep challenges",
+		OnStart:  true,
+		Interval: time.Hour,
+		Func: func(ctx context.Context, _ time.Time) error {
+			return v.entityRepo.DeleteScepChallengeExpired(ctx)
+		},
+	}
+}
+
+// scepCheckIssuers requires the active issuers to decrypt, SCEP requests are encrypted to them.
+func scepCheckIssuers(list []*certs.Certificate) error {
+	for _, ca := range list {
+		if ca.IsActive() && !certs.CanDecrypt(ca.Issuer.Key) {
+			return fmt.Errorf("scep requires the issuer %q to decrypt, use the file signer with an RSA key",
+				ca.Issuer.Crt.Subject.String())
+		}
+	}
+	return nil
+}
+
+func scepChallengeHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (v *API) scepWrite(wc web.Ctx, contentType string, b []byte) {
+	wc.Response().Header().Set("Content-Type", contentType)
+	wc.Response().WriteHeader(http.StatusOK)
+	if _, err := wc.Response().Write(b); err != nil {
+		logx.Error("failed to write scep response", "err", err)
+	}
+}
+
+func (v *API) ScepGet(wc web.Ctx) {
+	query := wc.Request().URL.Query()
+
+	switch query.Get("operation") {
+	case scepOperationGetCACaps:
+		v.scepWrite(wc, scepContentTypeCaps, []byte(strings.Join(scep.Capabilities, "\n")))
+
+	case scepOperationGetCACert:
+		v.scepCACert(wc, query.Get("message"))
+
+	case scepOperationPKIOperation:
+		der, err := scepGetMessage(wc.Request().URL.RawQuery)
+		if err != nil {
+			wc.Error(http.StatusBadRequest, err)
+			return
+		}
+		v.scepOperation(wc, der)
+
+	default:
+		wc.Error(http.StatusBadRequest, errInvalidRequest)
+	}
+}
+
+// scepGetMessage decodes the base64 message of a GET PKIOperation. Clients often leave
+// "+" of the base64 alphabet unescaped, so the raw value is unescaped as a path, which
+// keeps "+", form decoding of the query would turn it into a space.
+func scepGetMessage(rawQuery string) ([]byte, error) {
+	for _, param := range strings.Split(rawQuery, "&") {
+		key, value, _ := strings.Cut(param, "=")
+		if key != "message" {
+			continue
+		}
+		msg, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message escaping: %w", err)
+		}
+		der, err := base64.StdEncoding.DecodeString(msg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 message: %w", err)
+		}
+		return der, nil
+	}
+	return nil, fmt.Errorf("missing message")
+}
+
+func (v *API) ScepPost(wc web.Ctx) {
+	if wc.Request().URL.Query().Get("operation") != scepOperationPKIOperation {
+		wc.Error(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	der, err := io.ReadAll(io.LimitReader(wc.Request().Body, estMaxRequestSize))
+	if err != nil {
+		wc.Error(http.StatusBadRequest, errInvalidRequest)
+		return
+	}
+
+	v.scepOperation(wc, der)
+}
+
+// scepCACert selects the CA by the optional message parameter as a domain of the CA,
+// the first active CA is used otherwise.
+func (v *API) scepCACert(wc web.Ctx, message string) {
+	var ca *certs.Certificate
+	if len(message) > 0 {
+		ca, _, _ = v.certStore.Match(message)
+	} else {
+		list := v.certStore.List()
+		if idx := slices.IndexFunc(list, (*certs.Certificate).IsActive); idx >= 0 {
+			ca = list[idx]
+		}
+	}
+	if ca == nil {
+		wc.Error(http.StatusNotFound, errNotFound)
+		return
+	}
+
+	chain := ca.ChainCerts()
+	if len(chain) == 1 {
+		v.scepWrite(wc, scepContentTypeCACert, chain[0].Raw)
+		return
+	}
+
+	b, err := certs.MarshalCertsOnlyPKCS7(chain...)
+	if err != nil {
+		logx.Error("failed to encode pkcs7", "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	v.scepWrite(wc, scepContentTypeCARACert, b)
+}
+
+// scepOperation handles PKCSReq and RenewalReq. Request errors after the message is
+// decoded are returned as a signed CertRep with the failure status.
+func (v *API) scepOperation(wc web.Ctx, der []byte) {
+	msg, err := scep.ParsePKIMessage(der)
+	if err != nil {
+		wc.Error(http.StatusBadRequest, fmt.Errorf("invalid pki message: %w", err))
+		return
+	}
+
+	list := v.certStore.List()
+	idx := slices.IndexFunc(list, func(ca *certs.Certificate) bool {
+		return msg.IsAddressedTo(ca.Issuer.Crt)
+	})
+	if idx < 0 {
+		wc.Error(http.StatusBadRequest, fmt.Errorf("pki message is not addressed to casper"))
+		return
+	}
+	ca := list[idx]
+
+	fail := func(info scep.FailInfo, reason string, kv ...any) {
+		logx.Warn("SCEP request rejected", append([]any{
+			"transaction", msg.TransactionID, "reason", reason}, kv...)...)
+
+		b, err := msg.Failure(ca.Issuer.Crt, ca.Issuer.Key, info)
+		if err != nil {
+			logx.Error("failed to encode scep failure", "err", err)
+			wc.Error(http.StatusInternalServerError, errInternalError)
+			return
+		}
+		v.scepWrite(wc, scepContentTypePKIMessage, b)
+	}
+
+	csr, err := msg.DecryptCSR(ca.Issuer.Crt, ca.Issuer.Key)
+	if err != nil {
+		fail(scep.FailInfoBadMessageCheck, "decrypt", "err", err)
+		return
+	}
+	if err = csr.CheckSignature(); err != nil {
+		fail(scep.FailInfoBadMessageCheck, "csr signature", "err", err)
+		return
+	}
+	if err = v.validateCRS(csr); err != nil {
+		fail(scep.FailInfoBadRequest, "validate", "err", err)
+		return
+	}
+
+	var auth *entity.Auth
+	switch msg.MessageType {
+	case scep.MessageTypePKCSReq:
+		// the self-signed signer certificate proves possession of the requested key
+		if pub, ok := msg.Signer.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(csr.PublicKey) {
+			fail(scep.FailInfoBadMessageCheck, "signer key does not match csr")
+			return
+		}
+		if auth, err = v.scepChallengeAuth(wc, csr); err != nil {
+			fail(scep.FailInfoBadRequest, "challenge", "err", err)
+			return
+		}
+
+	case scep.MessageTypeRenewalReq:
+		if auth, err = v.clientCertAuth(wc.Context(), msg.Signer); err != nil {
+			fail(scep.FailInfoBadCertID, "renewal certificate", "serial", msg.Signer.SerialNumber, "err", err)
+			return
+		}
+		if !sameIdentities(msg.Signer, csr) {
+			fail(scep.FailInfoBadRequest, "csr names do not match the current certificate")
+			return
+		}
+
+	default:
+		fail(scep.FailInfoBadRequest, "unsupported message type", "type", msg.MessageType)
+		return
+	}
+
+	denied, err := checkCSRAccess(auth.Domains, csr)
+	if err != nil {
+		logx.Error("failed to parse user access domains", "ownerId", auth.ID, "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	if len(denied) > 0 {
+		fail(scep.FailInfoBadRequest, "denied", "denied", denied)
+		return
+	}
+
+	lookupDomains, trustDomains := csrLookupNames(csr)
+	root, err := v.getRootCertificate(lookupDomains, trustDomains...)
+	if err != nil || root != ca {
+		fail(scep.FailInfoBadRequest, "csr names are not served by the ca", "err", err)
+		return
+	}
+
+	profile, err := v.getProfile("", auth.Profiles)
+	if err != nil {
+		fail(scep.FailInfoBadRequest, "profile", "err", err)
+		return
+	}
+
+	result, err := v.issueCertificate(wc.Context(), auth.ID, ca, profile, csr, true)
+	if msg, ok := policyError(err); ok {
+		fail(scep.FailInfoBadRequest, "issuer policy", "err", msg)
+		return
+	}
+	if err != nil {
+		logx.Error("failed to issue scep certificate", "names", csrIdentities(csr), "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+	if result.Status != client.RenewalStatusIssued {
+		fail(scep.FailInfoBadRequest, "names are owned by another account")
+		return
+	}
+
+	crt, err := pki.UnmarshalCrtPEM([]byte(result.Cert))
+	if err != nil {
+		logx.Error("failed to decode issued certificate", "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	b, err := msg.Success(ca.Issuer.Crt, ca.Issuer.Key, crt)
+	if err != nil {
+		logx.Error("failed to encode scep response", "err", err)
+		wc.Error(http.StatusInternalServerError, errInternalError)
+		return
+	}
+
+	logx.Info("SCEP certificate issued", "serial", result.SerialNumber, "owner", auth.ID,
+		"transaction", msg.TransactionID)
+
+	v.scepWrite(wc, scepContentTypePKIMessage, b)
+}
+
+// scepChallengeAuth consumes the challenge password of the CSR and returns its account.
+func (v *API) scepChallengeAuth(wc web.Ctx, csr *x509.CertificateRequest) (*entity.Auth, error) {
+	password, err := scep.ChallengePassword(csr)
+	if err != nil {
+		return nil, err
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("challenge password is required")
+	}
+
+	owner, err := v.entityRepo.ConsumeScepChallenge(wc.Context(), scepChallengeHash(password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	if owner == 0 {
+		return nil, fmt.Errorf("challenge password is unknown or expired")
+	}
+
+	auth, err := v.entityRepo.SelectAuthByID(wc.Context(), owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if len(auth) != 1 || auth[0].Locked || len(auth[0].Domains) == 0 {
+		return nil, fmt.Errorf("account not found or locked")
+	}
+
+	return &auth[0], nil
+}
@@ -0,0 +1,61 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+func TestScepCheckIssuers(t *testing.T) {
+	group := &certs.ConfigGroup{Certs: []certs.Config{
+		testCAGroup(t, t.TempDir(), "CA EC", []string{"example.com"}, nil),
+	}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	if err = scepCheckIssuers(store.List()); err == nil {
+		t.Fatalf("scepCheckIssuers() error = nil, want error for an EC issuer")
+	}
+	if err = scepCheckIssuers(nil); err != nil {
+		t.Fatalf("scepCheckIssuers() error = %v, want nil without issuers", err)
+	}
+}
+
+func TestScepGetMessage(t *testing.T) {
+	// 0xfb 0xff encodes to "+/8=" with the "+" that form decoding turns into a space
+	der := []byte{0xfb, 0xff, 0x30, 0x01}
+
+	tests := []struct {
+		name     string
+		rawQuery string
+		want     []byte
+		wantErr  bool
+	}{
+		{name: "unescaped plus", rawQuery: "operation=PKIOperation&message=+/8wAQ==", want: der},
+		{name: "escaped", rawQuery: "operation=PKIOperation&message=%2B%2F8wAQ%3D%3D", want: der},
+		{name: "message first", rawQuery: "message=+/8wAQ==&operation=PKIOperation", want: der},
+		{name: "missing", rawQuery: "operation=PKIOperation", wantErr: true},
+		{name: "invalid escaping", rawQuery: "operation=PKIOperation&message=%zz", wantErr: true},
+		{name: "invalid base64", rawQuery: "operation=PKIOperation&message=+/8wAQ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scepGetMessage(tt.rawQuery)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scepGetMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("scepGetMessage() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
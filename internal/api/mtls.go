@@ -0,0 +1,211 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	clientCertHeader     = "X-Client-Cert"
+	clientCertAuthHeader = "X-Client-Cert-Auth"
+
+	mtlsProxySecretMinSize = 32
+)
+
+// mtlsProxySecret returns the secret the listener passes to the upstream with the certificate,
+// a random one unless an external TLS proxy shares the configured one.
+func mtlsProxySecret(c MTLSConfig) ([]byte, error) {
+	if len(c.ProxySecret) > 0 {
+		if len(c.ProxySecret) < mtlsProxySecretMinSize {
+			return nil, fmt.Errorf("mtls proxy secret must be at least %d characters", mtlsProxySecretMinSize)
+		}
+		return []byte(c.ProxySecret), nil
+	}
+	b := make([]byte, mtlsProxySecretMinSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate mtls proxy secret: %w", err)
+	}
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func parseTrustedProxies(list []string) ([]netip.Prefix, error) {
+	result := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", s, err)
+			}
+			result = append(result, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", s, err)
+		}
+		result = append(result, prefix.Masked())
+	}
+	return result, nil
+}
+
+// clientCertificate returns the TLS client certificate of the request: the one of the TLS
+// connection, or the one passed by the mTLS listener or the proxy holding the proxy secret.
+// The header carries a public certificate only, so it is ignored without mTLS and without
+// the secret. The caller still checks the certificate with clientCertAuth.
+func (v *API) clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates[0]
+	}
+
+	value := r.Header.Get(clientCertHeader)
+	if len(value) == 0 || len(v.proxySecret) == 0 {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(clientCertAuthHeader)), v.proxySecret) != 1 {
+		return nil
+	}
+	if len(v.trustedProxies) > 0 && !v.isTrustedProxy(r.RemoteAddr) {
+		return nil
+	}
+	crt, err := decodeClientCert(value)
+	if err != nil {
+		return nil
+	}
+	return crt
+}
+
+func (v *API) isTrustedProxy(remoteAddr string) bool {
+	addr, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := addr.Addr().Unmap()
+	for _, prefix := range v.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeClientCert(crt *x509.Certificate) string {
+	return url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw})))
+}
+
+func decodeClientCert(value string) (*x509.Certificate, error) {
+	raw, err := url.QueryUnescape(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unescape client certificate: %w", err)
+	}
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("failed to decode client certificate pem")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// newMTLSServer creates the TLS listener in front of the main server.
+func (v *API) newMTLSServer() (*http.Server, error) {
+	c := v.conf.MTLS
+
+	crt, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mtls server certificate: %w", err)
+	}
+	upstream, err := url.Parse(c.Upstream)
+	if err != nil || len(upstream.Scheme) == 0 || len(upstream.Host) == 0 {
+		return nil, fmt.Errorf("invalid mtls upstream %q", c.Upstream)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+			r.Out.Host = r.In.Host
+			r.Out.Header.Del(clientCertHeader)
+			r.Out.Header.Del(clientCertAuthHeader)
+			if r.In.TLS != nil && len(r.In.TLS.PeerCertificates) > 0 {
+				r.Out.Header.Set(clientCertHeader, encodeClientCert(r.In.TLS.PeerCertificates[0]))
+				r.Out.Header.Set(clientCertAuthHeader, string(v.proxySecret))
+			}
+		},
+	}
+
+	return &http.Server{
+		Addr:              c.Addr,
+		Handler:           proxy,
+		TLSConfig:         v.mtlsTLSConfig(crt),
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+// mtlsTLSConfig requests a client certificate and verifies it if given against the issuers of
+// the current CA set. It works like tls.VerifyClientCertIfGiven without the client_auth
+// extended key usage: a host renews its server certificate by presenting it.
+func (v *API) mtlsTLSConfig(crt tls.Certificate) *tls.Config {
+	base := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{crt},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool := x509.NewCertPool()
+		for _, ca := range v.certStore.List() {
+			pool.AddCert(ca.Issuer.Crt)
+		}
+
+		conf := base.Clone()
+		conf.GetConfigForClient = nil
+		conf.ClientCAs = pool
+		conf.ClientAuth = tls.RequestClientCert
+		conf.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyClientCert(pool, rawCerts)
+		}
+		return conf, nil
+	}
+	return base
+}
+
+func verifyClientCert(pool *x509.CertPool, rawCerts [][]byte) error {
+	if len(rawCerts) == 0 {
+		return nil
+	}
+
+	list := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		crt, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		list = append(list, crt)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, crt := range list[1:] {
+		intermediates.AddCert(crt)
+	}
+	_, err := list[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify client certificate: %w", err)
+	}
+	return nil
+}
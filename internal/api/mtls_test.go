@@ -0,0 +1,270 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+// testLeaf issues a certificate for the name by the CA of the group.
+func testLeaf(t *testing.T, group certs.Config, name string, eku x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+
+	ca, err := tls.LoadX509KeyPair(group.IssuingCACert, group.IssuingCAKey)
+	if err != nil {
+		t.Fatalf("failed to load CA: %v", err)
+	}
+	caCrt, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse CA: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{eku},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, caCrt, key.Public(), ca.PrivateKey.(crypto.Signer))
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writeTLSPair(t *testing.T, dir string, pair tls.Certificate) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(pair.PrivateKey)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	crtPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err = os.WriteFile(crtPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pair.Certificate[0]}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return crtPath, keyPath
+}
+
+func TestMTLSListener(t *testing.T) {
+	dir := t.TempDir()
+	issuing := testCAGroup(t, dir, "CA Issuing", []string{"example.com"}, nil)
+	foreign := testCAGroup(t, dir, "CA Foreign", []string{"example.org"}, nil)
+	serverCA := testCAGroup(t, dir, "CA Server", []string{"example.net"}, nil)
+
+	group := &certs.ConfigGroup{Certs: []certs.Config{issuing}}
+	group.Default()
+	store, err := certs.NewStore(group)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	secret, err := mtlsProxySecret(MTLSConfig{})
+	if err != nil {
+		t.Fatalf("mtlsProxySecret() error = %v", err)
+	}
+	v := &API{certStore: store, proxySecret: secret}
+
+	seen := make(chan *x509.Certificate, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen <- v.clientCertificate(r)
+	}))
+	defer upstream.Close()
+
+	v.conf = &ConfigGroup{MTLS: MTLSConfig{Enabled: true, Addr: "127.0.0.1:0", Upstream: upstream.URL}}
+	v.conf.MTLS.Cert, v.conf.MTLS.Key = writeTLSPair(t, dir,
+		testLeaf(t, serverCA, "casper.example.net", x509.ExtKeyUsageServerAuth))
+	v.mtls, err = v.newMTLSServer()
+	if err != nil {
+		t.Fatalf("newMTLSServer() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", v.mtls.Addr)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = v.mtls.ServeTLS(ln, "", "") }()
+	defer func() { _ = v.Down() }()
+
+	roots := x509.NewCertPool()
+	serverCAPair, err := tls.LoadX509KeyPair(serverCA.IssuingCACert, serverCA.IssuingCAKey)
+	if err != nil {
+		t.Fatalf("failed to load server CA: %v", err)
+	}
+	serverCACrt, _ := x509.ParseCertificate(serverCAPair.Certificate[0])
+	roots.AddCert(serverCACrt)
+
+	// a host renews its server certificate by presenting it, client_auth is not required
+	hostCert := testLeaf(t, issuing, "host.example.com", x509.ExtKeyUsageServerAuth)
+	foreignCert := testLeaf(t, foreign, "host.example.org", x509.ExtKeyUsageClientAuth)
+
+	tests := []struct {
+		name     string
+		cert     *tls.Certificate
+		header   string
+		wantErr  bool
+		wantCert *tls.Certificate
+	}{
+		{name: "issued certificate", cert: &hostCert, wantCert: &hostCert},
+		{name: "no certificate", cert: nil},
+		{name: "header is not forwarded", header: encodeClientCert(mustParse(t, hostCert))},
+		{name: "header is replaced", cert: &hostCert, header: encodeClientCert(mustParse(t, foreignCert)),
+			wantCert: &hostCert},
+		{name: "certificate of other CA", cert: &foreignCert, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &tls.Config{RootCAs: roots, ServerName: "casper.example.net"}
+			if tt.cert != nil {
+				// sent even if the issuer is not in the CA list of the server
+				conf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return tt.cert, nil
+				}
+			}
+			cli := &http.Client{Transport: &http.Transport{TLSClientConfig: conf}, Timeout: 5 * time.Second}
+
+			req, err := http.NewRequest(http.MethodGet, "https://"+ln.Addr().String()+"/", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest() error = %v", err)
+			}
+			if len(tt.header) > 0 {
+				req.Header.Set(clientCertHeader, tt.header)
+				req.Header.Set(clientCertAuthHeader, string(secret))
+			}
+
+			resp, err := cli.Do(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			_ = resp.Body.Close()
+
+			got := <-seen
+			switch {
+			case tt.wantCert == nil && got != nil:
+				t.Fatalf("upstream got certificate %s, want none", got.Subject)
+			case tt.wantCert != nil && (got == nil || !got.Equal(mustParse(t, *tt.wantCert))):
+				t.Fatalf("upstream got certificate %v, want %s", got, mustParse(t, *tt.wantCert).Subject)
+			}
+		})
+	}
+}
+
+func TestClientCertificateProxy(t *testing.T) {
+	dir := t.TempDir()
+	group := testCAGroup(t, dir, "CA Issuing", []string{"example.com"}, nil)
+	crt := mustParse(t, testLeaf(t, group, "host.example.com", x509.ExtKeyUsageClientAuth))
+
+	secret, err := mtlsProxySecret(MTLSConfig{})
+	if err != nil {
+		t.Fatalf("mtlsProxySecret() error = %v", err)
+	}
+	trusted, err := parseTrustedProxies([]string{"10.0.0.0/8", "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("parseTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		api    *API
+		remote string
+		header string
+		auth   string
+		want   bool
+	}{
+		{name: "listener with secret", api: &API{proxySecret: secret}, remote: "127.0.0.1:4000",
+			header: encodeClientCert(crt), auth: string(secret), want: true},
+		{name: "trusted network with secret", api: &API{proxySecret: secret, trustedProxies: trusted},
+			remote: "10.1.2.3:4000", header: encodeClientCert(crt), auth: string(secret), want: true},
+		{name: "header only", api: &API{proxySecret: secret}, remote: "127.0.0.1:4000",
+			header: encodeClientCert(crt)},
+		{name: "wrong secret", api: &API{proxySecret: secret}, remote: "127.0.0.1:4000",
+			header: encodeClientCert(crt), auth: "guess"},
+		{name: "untrusted address with secret", api: &API{proxySecret: secret, trustedProxies: trusted},
+			remote: "192.0.2.1:4000", header: encodeClientCert(crt), auth: string(secret)},
+		{name: "mtls disabled", api: &API{}, remote: "127.0.0.1:4000", header: encodeClientCert(crt)},
+		{name: "mtls disabled with a secret", api: &API{}, remote: "127.0.0.1:4000",
+			header: encodeClientCert(crt), auth: string(secret)},
+		{name: "no header", api: &API{proxySecret: secret}, remote: "127.0.0.1:4000", auth: string(secret)},
+		{name: "invalid header", api: &API{proxySecret: secret}, remote: "127.0.0.1:4000",
+			header: "not a certificate", auth: string(secret)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if len(tt.header) > 0 {
+				r.Header.Set(clientCertHeader, tt.header)
+			}
+			if len(tt.auth) > 0 {
+				r.Header.Set(clientCertAuthHeader, tt.auth)
+			}
+			got := tt.api.clientCertificate(r)
+			if (got != nil) != tt.want {
+				t.Fatalf("clientCertificate() = %v, want %v", got != nil, tt.want)
+			}
+			if got != nil && !got.Equal(crt) {
+				t.Fatalf("clientCertificate() returned other certificate %s", got.Subject)
+			}
+		})
+	}
+}
+
+func TestMTLSProxySecret(t *testing.T) {
+	if _, err := mtlsProxySecret(MTLSConfig{ProxySecret: "short"}); err == nil {
+		t.Fatalf("mtlsProxySecret() accepted a short secret")
+	}
+	a, err := mtlsProxySecret(MTLSConfig{})
+	if err != nil {
+		t.Fatalf("mtlsProxySecret() error = %v", err)
+	}
+	b, err := mtlsProxySecret(MTLSConfig{})
+	if err != nil {
+		t.Fatalf("mtlsProxySecret() error = %v", err)
+	}
+	if len(a) < mtlsProxySecretMinSize || string(a) == string(b) {
+		t.Fatalf("mtlsProxySecret() = %q and %q, want random secrets", a, b)
+	}
+}
+
+func mustParse(t *testing.T, pair tls.Certificate) *x509.Certificate {
+	t.Helper()
+
+	crt, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return crt
+}
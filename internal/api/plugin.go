@@ -8,5 +8,6 @@ package api
 import "go.osspkg.com/goppy/v2/plugins"
 
 var Plugin = plugins.Kind{
+	Config: &ConfigGroup{},
 	Inject: NewAPI,
 }
@@ -0,0 +1,223 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.osspkg.com/console"
+	"go.osspkg.com/errors"
+	"go.osspkg.com/events"
+	web "go.osspkg.com/goppy/v2/web/client"
+
+	"go.arwos.org/casper/client"
+)
+
+const accountActions = "create, list, show, lock, unlock, set-domains, set-profiles, rotate-key, scep-challenge, delete"
+
+func Account() console.CommandGetter {
+	return console.NewCommand(func(setter console.CommandSetter) {
+		setter.Setup("account", "manage accounts via admin API: <action> [token-id], actions: "+accountActions)
+		setter.Flag(func(f console.FlagsSetter) {
+			f.StringVar("address", "", "Casper server address")
+			f.StringVar("admin-id", "", "Admin authentication ID")
+			f.StringVar("admin-key", "", "Admin authentication Key")
+			f.StringVar("domains", "", "Comma-separated domain rules: example.com, *.example.com, !deny.example.com")
+			f.StringVar("profiles", "", "Comma-separated list of allowed certificate profiles")
+			f.StringVar("format", "table", "Output format (table or json)")
+		})
+		setter.ExecFunc(func(args []string, _address, _adminId, _adminKey, _domains, _profiles, _format string) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go events.OnStopSignal(cancel)
+
+			console.FatalIfErr(
+				manageAccount(ctx, args, _address, _adminId, _adminKey, _domains, _profiles, _format),
+				"failed manage account",
+			)
+		})
+	})
+}
+
+func manageAccount(
+	ctx context.Context, args []string, _address, _adminId, _adminKey, _domains, _profiles, _format string,
+) error {
+	if len(args) == 0 {
+		return fmt.Errorf("require action, can use %s", accountActions)
+	}
+	action := args[0]
+
+	var tokenId string
+	if action != "create" && action != "list" {
+		if len(args) < 2 || len(strings.TrimSpace(args[1])) == 0 {
+			return fmt.Errorf("action %s require token id", action)
+		}
+		tokenId = strings.TrimSpace(args[1])
+	}
+
+	domains := splitList(_domains)
+	profiles := splitList(_profiles)
+
+	cli, err := client.NewAdmin(client.Config{
+		Address: _address,
+		Proxy:   "env",
+		AuthID:  _adminId,
+		AuthKey: _adminKey,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "init Casper client")
+	}
+
+	locked, unlocked := true, false
+
+	var out *client.AccountModel
+	switch action {
+	case "create":
+		if len(domains) == 0 {
+			return fmt.Errorf("action create require --domains")
+		}
+		out, err = cli.AccountCreateV1(ctx, client.AccountRequest{Domains: domains, Profiles: profiles})
+
+	case "list":
+		var result []client.AccountModel
+		result, err = listAccounts(ctx, cli)
+		if err != nil {
+			return printAdminError(err, action)
+		}
+		return printAccounts(result, _format)
+
+	case "show":
+		out, err = cli.AccountShowV1(ctx, tokenId)
+
+	case "lock":
+		out, err = cli.AccountUpdateV1(ctx, client.AccountRequest{TokenID: tokenId, Locked: &locked})
+
+	case "unlock":
+		out, err = cli.AccountUpdateV1(ctx, client.AccountRequest{TokenID: tokenId, Locked: &unlocked})
+
+	case "set-domains":
+		if len(domains) == 0 {
+			return fmt.Errorf("action set-domains require --domains")
+		}
+		out, err = cli.AccountUpdateV1(ctx, client.AccountRequest{TokenID: tokenId, Domains: domains})
+
+	case "set-profiles":
+		if len(profiles) == 0 {
+			return fmt.Errorf("action set-profiles require --profiles")
+		}
+		out, err = cli.AccountUpdateV1(ctx, client.AccountRequest{TokenID: tokenId, Profiles: profiles})
+
+	case "rotate-key":
+		out, err = cli.AccountUpdateV1(ctx, client.AccountRequest{TokenID: tokenId, RotateKey: true})
+
+	case "scep-challenge":
+		var challenge *client.ScepChallengeModel
+		if challenge, err = cli.ScepChallengeV1(ctx, tokenId); err != nil {
+			return printAdminError(err, action)
+		}
+		return printScepChallenge(challenge, _format)
+
+	case "delete":
+		if out, err = cli.AccountDeleteV1(ctx, tokenId); err != nil {
+			return printAdminError(err, action)
+		}
+		if !out.Deleted {
+			fmt.Println("[WARN] account", tokenId, "has certificates, it is locked and its key replaced,",
+				"delete it again after they expire")
+			return nil
+		}
+		fmt.Println("[INFO] account", tokenId, "deleted")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown action: %s, can use %s", action, accountActions)
+	}
+
+	if err != nil {
+		return printAdminError(err, action)
+	}
+
+	return printAccounts([]client.AccountModel{*out}, _format)
+}
+
+func listAccounts(ctx context.Context, cli client.AdminClient) ([]client.AccountModel, error) {
+	req := client.AccountsRequest{}
+	result := make([]client.AccountModel, 0, 100)
+	for {
+		out, err := cli.AccountListV1(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out.Accounts...)
+		if out.NextCursor == 0 {
+			return result, nil
+		}
+		req.Cursor = out.NextCursor
+	}
+}
+
+func printAdminError(err error, action string) error {
+	var httpErr *web.HTTPError
+	if errors.As(err, &httpErr) {
+		console.Errorf("response %s:\n%s", action, httpErr.Raw.String())
+	}
+	return errors.Wrapf(err, "failed %s", action)
+}
+
+func printScepChallenge(challenge *client.ScepChallengeModel, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(challenge)
+
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHALLENGE\tEXPIRES")
+		fmt.Fprintf(w, "%s\t%s\n", challenge.Challenge, challenge.ExpiresAt.Format(time.DateTime))
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown format: %s, can use table, json", format)
+	}
+}
+
+func printAccounts(result []client.AccountModel, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOKEN ID\tTOKEN KEY\tDOMAINS\tPROFILES\tLOCKED\tCREATED\tUPDATED")
+		for _, item := range result {
+			key := item.TokenKey
+			if len(key) == 0 {
+				key = "-"
+			}
+			profiles := strings.Join(item.Profiles, ",")
+			if len(profiles) == 0 {
+				profiles = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\n",
+				item.TokenID, key, strings.Join(item.Domains, ","), profiles, item.Locked,
+				item.CreatedAt.Format(time.DateTime), item.UpdatedAt.Format(time.DateTime))
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown format: %s, can use table, json", format)
+	}
+}
@@ -6,15 +6,22 @@
 package cmds
 
 import (
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync/atomic"
 
+	"go.osspkg.com/encrypt/pki"
 	"go.osspkg.com/errors"
 	"go.osspkg.com/ioutils/fs"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
 )
 
 var sslCertGroup = atomic.Int64{}
@@ -32,6 +39,47 @@ var _algorithms = map[string]x509.SignatureAlgorithm{
 	"ecdsa512": x509.ECDSAWithSHA512,
 }
 
+// newCSR creates the key and CSR, requests with URI or email SANs are built here
+// because pki.NewCSR supports only DNS names.
+func newCSR(alg x509.SignatureAlgorithm, domains []string, uris []*url.URL, emails []string) (*pki.Request, error) {
+	if len(uris) == 0 && len(emails) == 0 {
+		return pki.NewCSR(alg, domains...)
+	}
+
+	key, err := certs.NewKey(alg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "generate private key")
+	}
+
+	var cn string
+	switch {
+	case len(domains) > 0:
+		cn = domains[0]
+	case len(emails) > 0:
+		cn = emails[0]
+	default:
+		cn = uris[0].String()
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		SignatureAlgorithm: alg,
+		Subject:            pkix.Name{CommonName: cn},
+		DNSNames:           domains,
+		URIs:               uris,
+		EmailAddresses:     emails,
+	}, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create certificate request")
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode certificate request")
+	}
+
+	return &pki.Request{Csr: csr, Key: key}, nil
+}
+
 func setLinuxAccess(dir, filename string) (err error) {
 	if file := fmt.Sprintf("%s/%s.crt", dir, filename); fs.FileExist(file) {
 		err = errors.Wrap(err,
@@ -48,6 +96,16 @@ func setLinuxAccess(dir, filename string) (err error) {
 	return
 }
 
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 func errWrap(err error, domain string) {
 	if err == nil {
 		return
@@ -6,6 +6,7 @@
 package cmds
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"strings"
@@ -15,6 +16,8 @@ import (
 	"go.osspkg.com/do"
 	"go.osspkg.com/encrypt/pki"
 	"go.osspkg.com/ioutils/fs"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
 )
 
 func GenerateCA() console.CommandGetter {
@@ -35,11 +38,25 @@ func GenerateCA() console.CommandGetter {
 			f.StringVar("ca-cert", "", "Path for CA certificate for signing")
 			f.StringVar("ca-key", "", "Path for CA key for signing")
 			f.Bool("no-auto-permission", "Turn off setup auto permission")
+			f.Bool("encrypt-key", "Save the private key as encrypted PKCS#8 (PBKDF2, AES-256-CBC)")
+			f.StringVar("passphrase-file", "", "File with the passphrase for --encrypt-key, prompt if empty")
+			f.StringVar("passphrase-env", "", "Env variable with the passphrase for --encrypt-key")
+			f.StringVar("ca-passphrase-file", "", "File with the passphrase of an encrypted CA key, prompt if empty")
+			f.StringVar("permitted-dns", "", "Name constraints: permitted DNS subtrees of the intermediate, comma separated")
+			f.StringVar("excluded-dns", "", "Name constraints: excluded DNS subtrees of the intermediate")
+			f.StringVar("permitted-uri", "", "Name constraints: permitted URI hosts, e.g. SPIFFE trust domains")
+			f.StringVar("excluded-uri", "", "Name constraints: excluded URI hosts")
+			f.StringVar("permitted-email", "", "Name constraints: permitted email mailboxes or domains")
+			f.StringVar("excluded-email", "", "Name constraints: excluded email mailboxes or domains")
+			f.IntVar("path-len", -1, "Max path length of the intermediate, -1 is unlimited")
 		})
 		setter.ExecFunc(func(_ []string,
 			_cn, _org, _country, _ocsp, _cps, _icu, _crl, _alg string, _deadline int64,
 			_output, _filename string,
-			_caCertPath, _caKeyPath string, _noAutoPermission bool,
+			_caCertPath, _caKeyPath string, _noAutoPermission, _encryptKey bool,
+			_passphraseFile, _passphraseEnv, _caPassphraseFile string,
+			_permittedDNS, _excludedDNS, _permittedURI, _excludedURI, _permittedEmail, _excludedEmail string,
+			_pathLen int64,
 		) {
 			console.FatalIfErr(os.MkdirAll(_output, 0600), "Could not create output directory")
 
@@ -64,19 +81,36 @@ func GenerateCA() console.CommandGetter {
 			rootCA := pki.Certificate{}
 			if _caCertPath != "" && _caKeyPath != "" {
 				console.FatalIfErr(rootCA.LoadCert(_caCertPath), "failed decode CA certificate")
-				console.FatalIfErr(rootCA.LoadKey(_caKeyPath), "failed decode CA private key")
+				key, err := certs.LoadPrivateKey(_caKeyPath, certs.Passphrase{File: _caPassphraseFile, Prompt: true})
+				console.FatalIfErr(err, "failed decode CA private key")
+				rootCA.Key = key
 				if !rootCA.IsValidPair() {
 					console.Fatalf("invalid CA certificate")
 				}
 			}
 
+			constraints := certs.CAConstraints{
+				PermittedDNS:   splitList(strings.ToLower(_permittedDNS)),
+				ExcludedDNS:    splitList(strings.ToLower(_excludedDNS)),
+				PermittedURI:   splitList(strings.ToLower(_permittedURI)),
+				ExcludedURI:    splitList(strings.ToLower(_excludedURI)),
+				PermittedEmail: splitList(_permittedEmail),
+				ExcludedEmail:  splitList(_excludedEmail),
+				MaxPathLen:     int(_pathLen),
+			}
+
 			var (
 				err  error
 				cert *pki.Certificate
 			)
-			if !rootCA.IsValidPair() {
+			switch {
+			case !rootCA.IsValidPair() && !constraints.IsEmpty():
+				console.Fatalf("name constraints and path length require --ca-cert and --ca-key of the parent CA")
+			case !rootCA.IsValidPair():
 				cert, err = pki.NewCA(cfg, validityPeriod, time.Now().Unix(), 1)
-			} else {
+			case !constraints.IsEmpty():
+				cert, err = certs.NewIntermediateCA(cfg, rootCA, validityPeriod, time.Now().Unix(), constraints)
+			default:
 				cert, err = pki.NewIntermediateCA(cfg, rootCA, validityPeriod, time.Now().Unix())
 			}
 			console.FatalIfErr(err, "failed to create CA")
@@ -85,9 +119,16 @@ func GenerateCA() console.CommandGetter {
 			console.FatalIfErr(
 				cert.SaveCert(fmt.Sprintf("%s/%s.crt", _output, certName)),
 				"failed save CA certificate")
-			console.FatalIfErr(
-				cert.SaveKey(fmt.Sprintf("%s/%s.key", _output, certName)),
-				"failed save CA private key")
+			keyFileName := fmt.Sprintf("%s/%s.key", _output, certName)
+			if _encryptKey {
+				passphrase, err := newKeyPassphrase(_passphraseFile, _passphraseEnv)
+				console.FatalIfErr(err, "failed to read passphrase")
+				b, err := certs.MarshalEncryptedKeyPEM(cert.Key, passphrase)
+				console.FatalIfErr(err, "failed encrypt CA private key")
+				console.FatalIfErr(os.WriteFile(keyFileName, b, 0600), "failed save CA private key")
+			} else {
+				console.FatalIfErr(cert.SaveKey(keyFileName), "failed save CA private key")
+			}
 
 			if !_noAutoPermission {
 				console.FatalIfErr(setLinuxAccess(_output, certName), "failed set linux access")
@@ -95,3 +136,24 @@ func GenerateCA() console.CommandGetter {
 		})
 	})
 }
+
+// newKeyPassphrase reads the passphrase from the file or the env variable,
+// otherwise prompts twice to avoid typos.
+func newKeyPassphrase(file, env string) ([]byte, error) {
+	if len(file) > 0 || len(env) > 0 {
+		return certs.Passphrase{File: file, Env: env}.Resolve("CA key")
+	}
+
+	first, err := certs.PromptPassphrase("New passphrase for CA key: ")
+	if err != nil {
+		return nil, err
+	}
+	second, err := certs.PromptPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(first, second) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	return first, nil
+}
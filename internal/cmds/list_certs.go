@@ -0,0 +1,119 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.osspkg.com/console"
+	"go.osspkg.com/errors"
+	"go.osspkg.com/events"
+	web "go.osspkg.com/goppy/v2/web/client"
+
+	"go.arwos.org/casper/client"
+)
+
+func ListCerts() console.CommandGetter {
+	return console.NewCommand(func(setter console.CommandSetter) {
+		setter.Setup("list", "list issued certificates")
+		setter.Flag(func(f console.FlagsSetter) {
+			f.StringVar("address", "", "Casper server address")
+			f.StringVar("auth-id", "", "Authentication ID")
+			f.StringVar("auth-key", "", "Authentication Key")
+			f.StringVar("domain", "", "Filter by domain")
+			f.StringVar("revoked", "", "Filter by revoked flag (true or false)")
+			f.IntVar("expires-in", 0, "Filter certificates expiring within N days")
+			f.StringVar("format", "table", "Output format (table or json)")
+		})
+		setter.ExecFunc(func(_ []string,
+			_address, _authId, _authKey, _domain, _revoked string, _expiresIn int64, _format string,
+		) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go events.OnStopSignal(cancel)
+
+			console.FatalIfErr(
+				listCertificates(ctx, _address, _authId, _authKey, _domain, _revoked, _expiresIn, _format),
+				"failed list certificates",
+			)
+		})
+	})
+}
+
+func listCertificates(
+	ctx context.Context, _address, _authId, _authKey, _domain, _revoked string, _expiresIn int64, _format string,
+) error {
+	req := client.CertsRequest{
+		Domain: strings.ToLower(strings.TrimSpace(_domain)),
+	}
+
+	if len(_revoked) > 0 {
+		revoked, err := strconv.ParseBool(_revoked)
+		if err != nil {
+			return errors.Wrapf(err, "invalid revoked flag")
+		}
+		req.Revoked = &revoked
+	}
+
+	if _expiresIn > 0 {
+		req.ExpiresBefore = time.Now().AddDate(0, 0, int(_expiresIn))
+	}
+
+	cli, err := client.New(client.Config{
+		Address: _address,
+		Proxy:   "env",
+		AuthID:  _authId,
+		AuthKey: _authKey,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "init Casper client")
+	}
+
+	result := make([]client.CertModel, 0, 100)
+	for {
+		out, err := cli.CertsV1(ctx, req)
+		if err != nil {
+			var httpErr *web.HTTPError
+			if errors.As(err, &httpErr) {
+				console.Errorf("response list:\n%s", httpErr.Raw.String())
+			}
+			return errors.Wrapf(err, "failed list")
+		}
+		result = append(result, out.Certs...)
+		if len(out.NextCursor) == 0 {
+			break
+		}
+		req.Cursor = out.NextCursor
+	}
+
+	switch _format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERIAL\tDOMAINS\tREVOKED\tCREATED\tEXPIRES")
+		for _, item := range result {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n",
+				item.SerialNumber, strings.Join(item.Domains, ","), item.Revoked,
+				item.CreatedAt.Format(time.DateTime), item.ValidUntil.Format(time.DateTime))
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown format: %s, can use table, json", _format)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -39,9 +40,16 @@ func RenewalCert() console.CommandGetter {
 			f.StringVar("alg", "ecdsa256", "Signature Algorithm")
 			f.StringVar("output", fs.CurrentDir(), "Path for save certs")
 			f.StringVar("filename", "cert", "Filename for save certs")
+			f.StringVar("profile", "", "Certificate profile, server default if empty")
+			f.StringVar("uris", "", "URI SANs for renewal certificate, e.g. spiffe://trust-domain/ns/x/sa/y")
+			f.StringVar("emails", "", "Email SANs for renewal certificate")
+			f.StringVar("tls-cert", "", "Authenticate with a certificate issued by Casper instead of auth id/key")
+			f.StringVar("tls-key", "", "Private key of the certificate for authentication")
+			f.StringVar("tls-root-ca", "", "Root CA of the Casper server, system roots if empty")
 		})
 		setter.ExecFunc(func(_ []string,
-			_force, _noAutoPermission bool, _domains, _address, _authId, _authKey, _alg, _output, _filename string,
+			_force, _noAutoPermission bool, _domains, _address, _authId, _authKey, _alg, _output, _filename,
+			_profile, _uris, _emails, _tlsCert, _tlsKey, _tlsRootCA string,
 		) {
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -50,7 +58,8 @@ func RenewalCert() console.CommandGetter {
 			go events.OnStopSignal(cancel)
 
 			errWrap(
-				renewalCertificate(ctx, _force, _noAutoPermission, _domains, _address, _authId, _authKey, _alg, _output, _filename),
+				renewalCertificate(ctx, _force, _noAutoPermission, _domains, _address, _authId, _authKey,
+					_alg, _output, _filename, _profile, _uris, _emails, _tlsCert, _tlsKey, _tlsRootCA),
 				_domains,
 			)
 		})
@@ -65,11 +74,18 @@ type (
 		AuthID    string        `yaml:"auth_id"`
 		AuthToken string        `yaml:"auth_token"`
 		Algorithm string        `yaml:"algorithm"`
+		// MTLS renews with the stored certificate of the request when it exists,
+		// the auth token is used for the first issue only.
+		MTLS      bool          `yaml:"mtls"`
+		TLSRootCA string        `yaml:"tls_root_ca"`
 		Requests  []AutoRequest `yaml:"requests"`
 	}
 	AutoRequest struct {
 		Domains  []string `yaml:"domains"`
 		Filename string   `yaml:"filename"`
+		Profile  string   `yaml:"profile"`
+		URIs     []string `yaml:"uris"`
+		Emails   []string `yaml:"emails"`
 	}
 )
 
@@ -97,10 +113,16 @@ func RenewalCertAuto() console.CommandGetter {
 						Func: func(ctx context.Context, _ time.Time) error {
 							for _, request := range cfg.Requests {
 								domains := strings.Join(request.Domains, ",")
+								tlsCert, tlsKey := "", ""
+								if cfg.MTLS {
+									tlsCert, tlsKey = storedCertificate(cfg.StorePath, request.Filename)
+								}
 								errWrap(
 									renewalCertificate(ctx, false, false, domains,
 										cfg.ApiHost, cfg.AuthID, cfg.AuthToken,
-										cfg.Algorithm, cfg.StorePath, request.Filename),
+										cfg.Algorithm, cfg.StorePath, request.Filename, request.Profile,
+										strings.Join(request.URIs, ","), strings.Join(request.Emails, ","),
+										tlsCert, tlsKey, cfg.TLSRootCA),
 									domains,
 								)
 							}
@@ -114,33 +136,63 @@ func RenewalCertAuto() console.CommandGetter {
 	})
 }
 
+// storedCertificate returns the certificate and key files saved by a previous renewal,
+// empty paths if any of them does not exist yet.
+func storedCertificate(dir, filename string) (string, string) {
+	certName := strings.ToLower(strings.TrimSpace(filename))
+	certFileName := fmt.Sprintf("%s/%s.crt", dir, certName)
+	keyFileName := fmt.Sprintf("%s/%s.key", dir, certName)
+	if !fs.FileExist(certFileName) || !fs.FileExist(keyFileName) {
+		return "", ""
+	}
+	return certFileName, keyFileName
+}
+
 func renewalCertificate(
 	ctx context.Context, _force, _noAutoPermission bool,
-	_domains, _address, _authId, _authKey, _alg, _output, _filename string,
+	_domains, _address, _authId, _authKey, _alg, _output, _filename, _profile, _uris, _emails string,
+	_tlsCert, _tlsKey, _tlsRootCA string,
 ) error {
 	alg, ok := _algorithms[_alg]
 	if !ok {
 		return fmt.Errorf("unknown algorithm: %s, can use %s", _alg, strings.Join(do.Keys(_algorithms), ", "))
 	}
 
-	domains := do.TreatValue[string](strings.Split(_domains, ","), strings.TrimSpace, strings.ToLower)
+	domains := splitList(strings.ToLower(_domains))
+	emails := splitList(_emails)
+
+	uris := make([]*url.URL, 0, 2)
+	for _, item := range splitList(_uris) {
+		uri, err := url.Parse(item)
+		if err != nil {
+			return errors.Wrapf(err, "invalid uri '%s'", item)
+		}
+		uris = append(uris, uri)
+	}
+
+	if len(domains)+len(emails)+len(uris) == 0 {
+		return fmt.Errorf("require domains, uris or emails")
+	}
 
 	cli, err := client.New(client.Config{
-		Address: _address,
-		Proxy:   "env",
-		AuthID:  _authId,
-		AuthKey: _authKey,
+		Address:   _address,
+		Proxy:     "env",
+		AuthID:    _authId,
+		AuthKey:   _authKey,
+		TLSCert:   _tlsCert,
+		TLSKey:    _tlsKey,
+		TLSRootCA: _tlsRootCA,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "init Casper client")
 	}
 
-	csr, err := pki.NewCSR(alg, domains...)
+	csr, err := newCSR(alg, domains, uris, emails)
 	if err != nil {
 		return errors.Wrapf(err, "create CSR")
 	}
 
-	out, err := cli.RenewalV1(ctx, _force, *csr.Csr)
+	out, err := cli.RenewalProfileV1(ctx, _force, strings.TrimSpace(_profile), *csr.Csr)
 	if err != nil {
 		var httpErr *web.HTTPError
 		if errors.As(err, &httpErr) {
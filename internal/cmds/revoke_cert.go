@@ -0,0 +1,155 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.osspkg.com/console"
+	"go.osspkg.com/do"
+	"go.osspkg.com/errors"
+	"go.osspkg.com/events"
+	web "go.osspkg.com/goppy/v2/web/client"
+
+	"go.arwos.org/casper/client"
+)
+
+func RevokeCert() console.CommandGetter {
+	return console.NewCommand(func(setter console.CommandSetter) {
+		setter.Setup("revoke", "revoke certificate")
+		setter.Flag(func(f console.FlagsSetter) {
+			f.StringVar("address", "", "Casper server address")
+			f.StringVar("auth-id", "", "Authentication ID")
+			f.StringVar("auth-key", "", "Authentication Key")
+			f.StringVar("serial", "", "Serial number of certificate, hex as 0x..., with colons or serial=... of openssl")
+			f.StringVar("serial-format", "", "Format of a serial without prefix: dec (casper list) or hex (openssl)")
+			f.StringVar("fingerprint", "", "Fingerprint of certificate")
+			f.StringVar("cert", "", "Path to certificate PEM")
+			f.StringVar("reason", "unspecified", "Revocation reason (RFC 5280 name or code)")
+		})
+		setter.ExecFunc(func(_ []string,
+			_address, _authId, _authKey, _serial, _serialFormat, _fingerprint, _cert, _reason string,
+		) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go events.OnStopSignal(cancel)
+
+			console.FatalIfErr(
+				revokeCertificate(ctx, _address, _authId, _authKey, _serial, _serialFormat, _fingerprint, _cert, _reason),
+				"failed revoke certificate",
+			)
+		})
+	})
+}
+
+func revokeCertificate(
+	ctx context.Context, _address, _authId, _authKey, _serial, _serialFormat, _fingerprint, _cert, _reason string,
+) error {
+	req := client.RevokeRequest{
+		FingerPrint: strings.TrimSpace(_fingerprint),
+	}
+
+	reason, ok := client.RevocationReasons[_reason]
+	if !ok {
+		code, err := strconv.ParseInt(_reason, 10, 64)
+		if err != nil {
+			return fmt.Errorf("unknown reason: %s, can use %s",
+				_reason, strings.Join(do.Keys(client.RevocationReasons), ", "))
+		}
+		reason = code
+	}
+	req.Reason = reason
+
+	if serial := strings.TrimSpace(_serial); len(serial) > 0 {
+		sn, err := parseSerial(serial, _serialFormat)
+		if err != nil {
+			return err
+		}
+		req.SerialNumber = sn
+	}
+
+	if len(_cert) > 0 {
+		b, err := os.ReadFile(_cert)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read certificate '%s'", _cert)
+		}
+		req.Cert = string(b)
+	}
+
+	cli, err := client.New(client.Config{
+		Address: _address,
+		Proxy:   "env",
+		AuthID:  _authId,
+		AuthKey: _authKey,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "init Casper client")
+	}
+
+	out, err := cli.RevokeV1(ctx, req)
+	if err != nil {
+		var httpErr *web.HTTPError
+		if errors.As(err, &httpErr) {
+			console.Errorf("response revoke:\n%s", httpErr.Raw.String())
+		}
+		return errors.Wrapf(err, "failed revoke")
+	}
+
+	switch out.Status {
+	case client.RevokeStatusAlreadyRevoked:
+		fmt.Println("[INFO] certificate", out.SerialNumber, "is already revoked")
+	case client.RevokeStatusRevoked:
+		fmt.Println("[INFO] certificate", out.SerialNumber, "revoked")
+	}
+
+	return nil
+}
+
+const (
+	serialFormatDec = "dec"
+	serialFormatHex = "hex"
+)
+
+// parseSerial converts the serial number to the decimal form of the API. openssl prints
+// the serial in hex and casper in decimal, a serial of digits only reads either way,
+// so without a prefix, colons or hex letters it needs the format.
+func parseSerial(s, format string) (string, error) {
+	raw := s
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		s, format = s[2:], serialFormatHex
+	case strings.HasPrefix(s, "serial="):
+		s, format = strings.TrimPrefix(s, "serial="), serialFormatHex
+	case strings.Contains(s, ":"):
+		s, format = strings.ReplaceAll(s, ":", ""), serialFormatHex
+	case len(format) == 0 && strings.ContainsAny(s, "abcdefABCDEF"):
+		format = serialFormatHex
+	}
+
+	var base int
+	switch format {
+	case serialFormatDec:
+		base = 10
+	case serialFormatHex:
+		base = 16
+	case "":
+		return "", fmt.Errorf("ambiguous serial number: %s, set --serial-format dec or hex, or use the 0x prefix", raw)
+	default:
+		return "", fmt.Errorf("unknown serial format: %s, can use %s, %s", format, serialFormatDec, serialFormatHex)
+	}
+
+	sn, ok := new(big.Int).SetString(s, base)
+	if !ok || sn.Sign() <= 0 {
+		return "", fmt.Errorf("invalid serial number: %s", raw)
+	}
+	return sn.String(), nil
+}
@@ -0,0 +1,41 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package cmds
+
+import "testing"
+
+func TestParseSerial(t *testing.T) {
+	tests := []struct {
+		name    string
+		serial  string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{name: "digits without format", serial: "1234", wantErr: true},
+		{name: "digits as decimal", serial: "1234", format: "dec", want: "1234"},
+		{name: "digits as hex", serial: "1234", format: "hex", want: "4660"},
+		{name: "0x prefix", serial: "0x1234", want: "4660"},
+		{name: "openssl output", serial: "serial=1234", want: "4660"},
+		{name: "colons", serial: "12:34", want: "4660"},
+		{name: "hex letters", serial: "0A1B", want: "2587"},
+		{name: "hex letters as decimal", serial: "0A1B", format: "dec", wantErr: true},
+		{name: "unknown format", serial: "1234", format: "oct", wantErr: true},
+		{name: "zero", serial: "0x0", wantErr: true},
+		{name: "garbage", serial: "0xzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSerial(tt.serial, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSerial(%q, %q) error = %v, wantErr %v", tt.serial, tt.format, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseSerial(%q, %q) = %s, want %s", tt.serial, tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package cmds
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+
+	"go.osspkg.com/console"
+	"go.osspkg.com/events"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+func Signer() console.CommandGetter {
+	return console.NewCommand(func(setter console.CommandSetter) {
+		setter.Setup("signer", "serve a CA key or a PKCS#11 token key over a unix socket for the socket signer of the server")
+		setter.Flag(func(f console.FlagsSetter) {
+			f.StringVar("key", "", "Path for CA key, unused with --pkcs11-module")
+			f.StringVar("socket", "/run/casper/signer.sock", "Unix socket path")
+			f.StringVar("key-id", "", "Key ID expected in requests, issuing_ca_signer.key_id of the server")
+			f.StringVar("passphrase-file", "", "File with the passphrase of an encrypted CA key, prompt if empty")
+			f.StringVar("pkcs11-module", "", "Path for PKCS#11 module, the key is used from the token instead of --key")
+			f.StringVar("pkcs11-module-args", "", "Parameters of C_Initialize, e.g. configdir='sql:/etc/pki/nssdb' for NSS")
+			f.StringVar("pkcs11-token", "", "Label of the PKCS#11 token")
+			f.StringVar("pkcs11-key-label", "", "Label of the private key on the token")
+			f.StringVar("pkcs11-pin-file", "", "File with the user PIN of the token, prompt if empty")
+		})
+		setter.ExecFunc(func(_ []string, _key, _socket, _keyID, _passphraseFile,
+			_p11Module, _p11ModuleArgs, _p11Token, _p11KeyLabel, _p11PinFile string) {
+			var (
+				key crypto.Signer
+				err error
+			)
+			if len(_p11Module) > 0 {
+				pin, err := certs.Passphrase{File: _p11PinFile, Prompt: true}.Resolve("token " + _p11Token)
+				console.FatalIfErr(err, "failed to read PIN of the token")
+
+				p11, err := certs.OpenPKCS11Key(certs.PKCS11Config{
+					Module:     _p11Module,
+					ModuleArgs: _p11ModuleArgs,
+					Token:      _p11Token,
+					KeyLabel:   _p11KeyLabel,
+					Pin:        string(pin),
+				})
+				console.FatalIfErr(err, "failed to open PKCS#11 key")
+				defer p11.Close()
+				key = p11
+			} else {
+				if len(_key) == 0 {
+					console.Fatalf("--key or --pkcs11-module is required")
+				}
+				key, err = certs.LoadPrivateKey(_key, certs.Passphrase{File: _passphraseFile, Prompt: true})
+				console.FatalIfErr(err, "failed decode CA private key")
+			}
+
+			if err = os.Remove(_socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				console.Fatalf("failed to remove stale socket %s: %s", _socket, err)
+			}
+			l, err := net.Listen("unix", _socket)
+			console.FatalIfErr(err, "failed to listen %s", _socket)
+			defer os.Remove(_socket)
+			console.FatalIfErr(os.Chmod(_socket, 0600), "failed to set socket permission")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go events.OnStopSignal(cancel)
+
+			srv := &certs.SignerServer{
+				Keys: map[string]crypto.Signer{_keyID: key},
+				OnError: func(err error) {
+					console.Errorf("%s", err)
+				},
+			}
+			console.Infof("signer listen %s", _socket)
+			console.FatalIfErr(srv.Serve(ctx, l), "signer stopped")
+		})
+	})
+}
@@ -20,6 +20,7 @@ type Auth struct {
 	TokenId   uuid.UUID // col=token_id index=unq
 	TokenKey  string    // col=token_key len=128
 	Domains   []string  // col=domains
+	Profiles  []string  // col=profiles
 	Locked    bool      // col=locked
 	CreatedAt time.Time // col=created_at auto=c:time.Now()
 	UpdatedAt time.Time // col=updated_at auto=u:time.Now()
@@ -27,7 +28,7 @@ type Auth struct {
 
 //gen:orm table=cert_info
 type Cert struct {
-	SerialNumber   int64     // col=id index=pk
+	SerialNumber   string    // col=id index=pk
 	Owner          int64     // col=owner index=fk:auth.id
 	Subject        string    // col=subject
 	FingerPrint    string    // col=fingerprint
@@ -42,6 +43,85 @@ type Cert struct {
 
 //gen:orm table=cert_domain
 type CertDomain struct {
-	SerialNumber int64  // col=cert_id index=fk:cert_info.id
+	SerialNumber string // col=cert_id index=fk:cert_info.id
 	Domain       string // col=domain index=idx
 }
+
+//gen:orm table=acme_account
+type AcmeAccount struct {
+	ID         int64     // col=id index=pk
+	Owner      int64     // col=owner index=fk:auth.id
+	Thumbprint string    // col=thumbprint index=unq
+	JWK        string    // col=jwk
+	Contacts   []string  // col=contacts
+	Status     string    // col=status
+	CreatedAt  time.Time // col=created_at auto=c:time.Now()
+	UpdatedAt  time.Time // col=updated_at auto=u:time.Now()
+}
+
+//gen:orm table=acme_order
+type AcmeOrder struct {
+	ID           int64     // col=id index=pk
+	OrderId      string    // col=order_id index=unq
+	AccountId    int64     // col=account_id index=fk:acme_account.id
+	Status       string    // col=status
+	Identifiers  []string  // col=identifiers
+	Error        string    // col=error
+	SerialNumber string    // col=serial_number
+	CertPEM      []byte    // col=cert_pem
+	ExpiresAt    time.Time // col=expires_at index=idx
+	CreatedAt    time.Time // col=created_at auto=c:time.Now()
+	UpdatedAt    time.Time // col=updated_at auto=u:time.Now()
+}
+
+//gen:orm table=acme_authz
+type AcmeAuthz struct {
+	ID         int64     // col=id index=pk
+	AuthzId    string    // col=authz_id index=unq
+	OrderId    string    // col=order_id index=fk:acme_order.order_id
+	AccountId  int64     // col=account_id
+	Identifier string    // col=identifier
+	Wildcard   bool      // col=wildcard
+	Status     string    // col=status
+	ExpiresAt  time.Time // col=expires_at
+}
+
+//gen:orm table=acme_challenge
+type AcmeChallenge struct {
+	ID          int64     // col=id index=pk
+	ChallengeId string    // col=challenge_id index=unq
+	AuthzId     string    // col=authz_id index=fk:acme_authz.authz_id
+	Type        string    // col=type
+	Token       string    // col=token
+	Status      string    // col=status
+	Error       string    // col=error
+	ValidatedAt time.Time // col=validated_at
+}
+
+//gen:orm table=scep_challenge
+type ScepChallenge struct {
+	ID        int64     // col=id index=pk
+	Owner     int64     // col=owner index=fk:auth.id
+	Secret    string    // col=secret index=unq
+	ExpiresAt time.Time // col=expires_at
+	CreatedAt time.Time // col=created_at auto=c:time.Now()
+}
+
+//gen:orm table=request_nonce
+type RequestNonce struct {
+	ID        int64     // col=id index=pk
+	Nonce     string    // col=nonce index=unq
+	ExpiresAt time.Time // col=expires_at
+}
+
+//gen:orm table=crl
+type Crl struct {
+	ID            int64     // col=id index=pk
+	IssuerKeyHash string    // col=issuer_key_hash index=idx
+	Number        int64     // col=number
+	BaseNumber    int64     // col=base_number
+	Data          []byte    // col=data
+	ThisUpdate    time.Time // col=this_update
+	NextUpdate    time.Time // col=next_update
+	CreatedAt     time.Time // col=created_at auto=c:time.Now()
+}
@@ -52,7 +52,7 @@ func (v *Repo) CreateCert(ctx context.Context, m *Cert, opts ...CreateOption) er
 
 const sqlSelectCursorCert = `SELECT "id", "owner", "subject", "fingerprint", "issuer_key_hash", "issuer_name_hash", "revoked", "revoked_reason", "created_at", "valid_until", "updated_at" FROM "cert_info" WHERE "id">$1 ORDER BY "id" LIMIT $2;`
 
-func (v *Repo) SelectCertCursor(ctx context.Context, from int64, lim uint) ([]Cert, error) {
+func (v *Repo) SelectCertCursor(ctx context.Context, from string, lim uint) ([]Cert, error) {
 	result := make([]Cert, 0, lim)
 	err := v.Sync().Query(ctx, "cert_info_read_all", func(q orm.Querier) {
 		q.SQL(sqlSelectCursorCert, from, lim)
@@ -73,7 +73,7 @@ func (v *Repo) SelectCertCursor(ctx context.Context, from int64, lim uint) ([]Ce
 
 const sqlSelectCertBySerialNumber = `SELECT "id", "owner", "subject", "fingerprint", "issuer_key_hash", "issuer_name_hash", "revoked", "revoked_reason", "created_at", "valid_until", "updated_at" FROM "cert_info" WHERE "id"=ANY($1);`
 
-func (v *Repo) SelectCertBySerialNumber(ctx context.Context, args ...int64) ([]Cert, error) {
+func (v *Repo) SelectCertBySerialNumber(ctx context.Context, args ...string) ([]Cert, error) {
 	if len(args) == 0 {
 		return nil, nil
 	}
@@ -361,7 +361,7 @@ func (v *Repo) UpdateCertBySerialNumber(ctx context.Context, ms ...*Cert) error
 
 const sqlDeleteCertBySerialNumber = `DELETE FROM "cert_info" WHERE "id"=ANY($1);`
 
-func (v *Repo) DeleteCertBySerialNumber(ctx context.Context, ms ...int64) error {
+func (v *Repo) DeleteCertBySerialNumber(ctx context.Context, ms ...string) error {
 	if len(ms) == 0 {
 		return nil
 	}
@@ -43,7 +43,7 @@ func (v *Repo) CreateCertDomain(ctx context.Context, m *CertDomain, opts ...Crea
 
 const sqlSelectCertDomainBySerialNumber = `SELECT "cert_id", "domain" FROM "cert_domain" WHERE "cert_id"=ANY($1);`
 
-func (v *Repo) SelectCertDomainBySerialNumber(ctx context.Context, args ...int64) ([]CertDomain, error) {
+func (v *Repo) SelectCertDomainBySerialNumber(ctx context.Context, args ...string) ([]CertDomain, error) {
 	if len(args) == 0 {
 		return nil, nil
 	}
@@ -133,7 +133,7 @@ func (v *Repo) UpdateCertDomainByDomain(ctx context.Context, ms ...*CertDomain)
 
 const sqlDeleteCertDomainBySerialNumber = `DELETE FROM "cert_domain" WHERE "cert_id"=ANY($1);`
 
-func (v *Repo) DeleteCertDomainBySerialNumber(ctx context.Context, ms ...int64) error {
+func (v *Repo) DeleteCertDomainBySerialNumber(ctx context.Context, ms ...string) error {
 	if len(ms) == 0 {
 		return nil
 	}
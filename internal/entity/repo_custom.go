@@ -7,8 +7,11 @@ package entity
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"time"
 
-	"go.osspkg.com/encrypt/pki"
 	"go.osspkg.com/goppy/v2/orm"
 )
 
@@ -51,7 +54,7 @@ const sqlUpdateCertsAsRevoked = `
 			WHERE "id" = ANY($1) AND "revoked" = false AND "owner" = $2;
 `
 
-func (v *Repo) UpdateCertsAsRevoked(ctx context.Context, ownerId int64, ids []int64, reason int64) error {
+func (v *Repo) UpdateCertsAsRevoked(ctx context.Context, ownerId int64, ids []string, reason int64) error {
 	return v.Master().Tx(ctx, "certs_update_as_revoked", func(tx orm.Tx) {
 		tx.Exec(func(e orm.Executor) {
 			e.SQL(sqlUpdateCertsAsRevoked, ids, ownerId, reason)
@@ -59,6 +62,24 @@ func (v *Repo) UpdateCertsAsRevoked(ctx context.Context, ownerId int64, ids []in
 	})
 }
 
+const sqlSelectCertExistsByOwner = `SELECT EXISTS (SELECT 1 FROM "cert_info" WHERE "owner" = $1);`
+
+// SelectCertExistsByOwner reports whether the owner has certificates that are not cleaned up yet,
+// expired ones are deleted by DeleteCertExpiredByValidUntil.
+func (v *Repo) SelectCertExistsByOwner(ctx context.Context, ownerId int64) (bool, error) {
+	var exists bool
+	err := v.Sync().Query(ctx, "certs_read_exists_by_owner", func(q orm.Querier) {
+		q.SQL(sqlSelectCertExistsByOwner, ownerId)
+		q.Bind(func(bind orm.Scanner) error {
+			return bind.Scan(&exists)
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
 const sqlDeleteCertExpiredByValidUntil = `DELETE FROM "cert_info" WHERE "valid_until" < now();`
 
 func (v *Repo) DeleteCertExpiredByValidUntil(ctx context.Context) error {
@@ -75,13 +96,47 @@ const sqlSelectCertRevoked = `
 		WHERE "issuer_key_hash" = $1 AND "revoked" = true AND "valid_until" >= now();
 `
 
-func (v *Repo) SelectCertRevoked(ctx context.Context, issuerKeyHash string) ([]pki.RevocationEntity, error) {
-	result := make([]pki.RevocationEntity, 0, 2)
+func (v *Repo) SelectCertRevoked(ctx context.Context, issuerKeyHash string) ([]x509.RevocationListEntry, error) {
+	result := make([]x509.RevocationListEntry, 0, 2)
 	err := v.Sync().Query(ctx, "certs_read_revoked", func(q orm.Querier) {
 		q.SQL(sqlSelectCertRevoked, issuerKeyHash)
 		q.Bind(func(bind orm.Scanner) error {
-			m := pki.RevocationEntity{}
-			if e := bind.Scan(&m.SerialNumber, &m.RevocationTime); e != nil {
+			var serial string
+			m := x509.RevocationListEntry{}
+			if e := bind.Scan(&serial, &m.RevocationTime); e != nil {
+				return e
+			}
+			sn, ok := new(big.Int).SetString(serial, 10)
+			if !ok {
+				return fmt.Errorf("invalid serial number: %s", serial)
+			}
+			m.SerialNumber = sn
+			result = append(result, m)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+const sqlSelectCertStatusByIssuer = `
+		SELECT "id", "issuer_name_hash", "revoked", "revoked_reason", "valid_until", "updated_at" 
+		FROM "cert_info" 
+		WHERE "issuer_key_hash" = $1 AND "valid_until" > now() AND "updated_at" >= $2;
+`
+
+// SelectCertStatusByIssuer returns the status of live certs of the issuer changed since the time,
+// zero time returns all of them.
+func (v *Repo) SelectCertStatusByIssuer(ctx context.Context, issuerKeyHash string, since time.Time) ([]Cert, error) {
+	result := make([]Cert, 0, 2)
+	err := v.Sync().Query(ctx, "certs_read_status_by_issuer", func(q orm.Querier) {
+		q.SQL(sqlSelectCertStatusByIssuer, issuerKeyHash, since)
+		q.Bind(func(bind orm.Scanner) error {
+			m := Cert{IssuerKeyHash: issuerKeyHash}
+			if e := bind.Scan(&m.SerialNumber, &m.IssuerNameHash, &m.Revoked, &m.RevokedReason,
+				&m.ValidUntil, &m.UpdatedAt); e != nil {
 				return e
 			}
 			result = append(result, m)
@@ -93,3 +148,235 @@ func (v *Repo) SelectCertRevoked(ctx context.Context, issuerKeyHash string) ([]p
 	}
 	return result, nil
 }
+
+type CertFilter struct {
+	Owner         int64
+	Domain        string
+	Revoked       *bool
+	ExpiresAfter  *time.Time
+	ExpiresBefore *time.Time
+	Cursor        string
+	Limit         uint
+}
+
+const sqlSelectCertByFilter = `
+		SELECT ci."id", ci."owner", ci."subject", ci."fingerprint", ci."issuer_key_hash", 
+				ci."issuer_name_hash", ci."revoked", ci."revoked_reason", 
+				ci."created_at", ci."valid_until", ci."updated_at" 
+		FROM "cert_info" ci
+		WHERE ci."owner" = $1 AND ($2::TEXT = '' OR ci."id" > $2::NUMERIC)
+			AND ($3::TEXT = '' OR EXISTS (
+				SELECT 1 FROM "cert_domain" cd WHERE cd."cert_id" = ci."id" AND cd."domain" = $3
+			))
+			AND ($4::BOOLEAN IS NULL OR ci."revoked" = $4)
+			AND ($5::TIMESTAMPTZ IS NULL OR ci."valid_until" >= $5)
+			AND ($6::TIMESTAMPTZ IS NULL OR ci."valid_until" <= $6)
+		ORDER BY ci."id"
+		LIMIT $7;
+`
+
+func (v *Repo) SelectCertByFilter(ctx context.Context, f CertFilter) ([]Cert, error) {
+	result := make([]Cert, 0, f.Limit)
+	err := v.Sync().Query(ctx, "certs_read_by_filter", func(q orm.Querier) {
+		q.SQL(sqlSelectCertByFilter, f.Owner, f.Cursor, f.Domain, f.Revoked, f.ExpiresAfter, f.ExpiresBefore, f.Limit)
+		q.Bind(func(bind orm.Scanner) error {
+			m := Cert{}
+			if e := bind.Scan(&m.SerialNumber, &m.Owner, &m.Subject, &m.FingerPrint,
+				&m.IssuerKeyHash, &m.IssuerNameHash, &m.Revoked, &m.RevokedReason,
+				&m.CreatedAt, &m.ValidUntil, &m.UpdatedAt); e != nil {
+				return e
+			}
+			result = append(result, m)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+const sqlConsumeScepChallenge = `
+		DELETE FROM "scep_challenge" 
+		WHERE "secret" = $1 AND "expires_at" > now() 
+		RETURNING "owner";
+`
+
+// ConsumeScepChallenge deletes the secret and returns its owner, zero if the secret is unknown or expired.
+func (v *Repo) ConsumeScepChallenge(ctx context.Context, secret string) (int64, error) {
+	var owner int64
+	err := v.Master().Query(ctx, "scep_challenge_consume", func(q orm.Querier) {
+		q.SQL(sqlConsumeScepChallenge, secret)
+		q.Bind(func(bind orm.Scanner) error {
+			return bind.Scan(&owner)
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return owner, nil
+}
+
+const sqlDeleteScepChallengeExpired = `DELETE FROM "scep_challenge" WHERE "expires_at" < now();`
+
+func (v *Repo) DeleteScepChallengeExpired(ctx context.Context) error {
+	return v.Master().Tx(ctx, "scep_challenge_delete_expired", func(tx orm.Tx) {
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlDeleteScepChallengeExpired)
+		})
+	})
+}
+
+const sqlDeleteRequestNonceExpired = `DELETE FROM "request_nonce" WHERE "expires_at" < now();`
+
+func (v *Repo) DeleteRequestNonceExpired(ctx context.Context) error {
+	return v.Master().Tx(ctx, "request_nonce_delete_expired", func(tx orm.Tx) {
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlDeleteRequestNonceExpired)
+		})
+	})
+}
+
+// The first number of an issuer continues the unix time numbers of CRLs kept in memory before,
+// a claim is skipped if a CRL of the same kind was claimed less than min age ago.
+const sqlClaimCrl = `
+		INSERT INTO "crl" ("issuer_key_hash", "number", "base_number", "data", "this_update", "next_update", "created_at")
+		SELECT $1::TEXT, COALESCE(MAX("number"), EXTRACT(EPOCH FROM now())::BIGINT) + 1, $2::BIGINT, ''::BYTEA, now(), now(), now()
+		FROM "crl" 
+		WHERE "issuer_key_hash" = $1::TEXT
+		HAVING NOT EXISTS (
+			SELECT 1 FROM "crl" 
+			WHERE "issuer_key_hash" = $1::TEXT AND ("base_number" = 0) = ($2::BIGINT = 0) 
+				AND "created_at" > now() - make_interval(secs => $3::DOUBLE PRECISION)
+		)
+		ON CONFLICT ("issuer_key_hash", "number") DO NOTHING
+		RETURNING "id", "issuer_key_hash", "number", "base_number", "created_at";
+`
+
+// ClaimCrl reserves the next CRL number of the issuer, a complete CRL has zero base number.
+// It returns nil if another instance has claimed a CRL of the same kind less than minAge ago.
+func (v *Repo) ClaimCrl(ctx context.Context, issuerKeyHash string, baseNumber int64, minAge time.Duration) (*Crl, error) {
+	var result *Crl
+	err := v.Master().Query(ctx, "crl_claim", func(q orm.Querier) {
+		q.SQL(sqlClaimCrl, issuerKeyHash, baseNumber, minAge.Seconds())
+		q.Bind(func(bind orm.Scanner) error {
+			m := Crl{}
+			if e := bind.Scan(&m.ID, &m.IssuerKeyHash, &m.Number, &m.BaseNumber, &m.CreatedAt); e != nil {
+				return e
+			}
+			result = &m
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+const sqlSelectCrlLast = `
+		SELECT "id", "issuer_key_hash", "number", "base_number", "data", "this_update", "next_update", "created_at"
+		FROM "crl"
+		WHERE "issuer_key_hash" = $1::TEXT AND ("base_number" = 0) = $2::BOOLEAN AND octet_length("data") > 0
+		ORDER BY "number" DESC
+		LIMIT 1;
+`
+
+// SelectCrlLast returns the latest built complete or delta CRL of the issuer, nil if there is none.
+func (v *Repo) SelectCrlLast(ctx context.Context, issuerKeyHash string, complete bool) (*Crl, error) {
+	var result *Crl
+	err := v.Sync().Query(ctx, "crl_read_last", func(q orm.Querier) {
+		q.SQL(sqlSelectCrlLast, issuerKeyHash, complete)
+		q.Bind(func(bind orm.Scanner) error {
+			m := Crl{}
+			if e := bind.Scan(&m.ID, &m.IssuerKeyHash, &m.Number, &m.BaseNumber, &m.Data,
+				&m.ThisUpdate, &m.NextUpdate, &m.CreatedAt); e != nil {
+				return e
+			}
+			result = &m
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Expired CRLs are deleted except the latest complete one of every issuer,
+// claims of crashed builds are deleted after a day.
+const sqlDeleteCrlOutdated = `
+		DELETE FROM "crl" c
+		WHERE (c."next_update" < now() OR (octet_length(c."data") = 0 AND c."created_at" < now() - INTERVAL '1 day'))
+			AND c."number" < (
+				SELECT COALESCE(MAX(l."number"), 0) FROM "crl" l 
+				WHERE l."issuer_key_hash" = c."issuer_key_hash" AND l."base_number" = 0 AND octet_length(l."data") > 0
+			);
+`
+
+func (v *Repo) DeleteCrlOutdated(ctx context.Context) error {
+	return v.Master().Tx(ctx, "crl_delete_outdated", func(tx orm.Tx) {
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlDeleteCrlOutdated)
+		})
+	})
+}
+
+const sqlClaimAcmeOrder = `
+		UPDATE "acme_order" SET "status" = $3, "updated_at" = now()
+		WHERE "order_id" = $1 AND "status" = ANY($2)
+		RETURNING "id";
+`
+
+// ClaimAcmeOrder moves the order to the status if the current one is listed in from,
+// false means another request has changed the order first.
+func (v *Repo) ClaimAcmeOrder(ctx context.Context, orderId string, from []string, to string) (bool, error) {
+	var claimed bool
+	err := v.Master().Query(ctx, "acme_order_claim", func(q orm.Querier) {
+		q.SQL(sqlClaimAcmeOrder, orderId, from, to)
+		q.Bind(func(bind orm.Scanner) error {
+			var id int64
+			claimed = true
+			return bind.Scan(&id)
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+const (
+	sqlUpdateAcmeChallengeResult = `
+		UPDATE "acme_challenge" SET "status" = $2, "error" = $3, "validated_at" = $4
+		WHERE "challenge_id" = $1 AND "status" = 'pending';
+`
+	sqlUpdateAcmeAuthzResult = `
+		UPDATE "acme_authz" SET "status" = $2
+		WHERE "authz_id" = (SELECT "authz_id" FROM "acme_challenge" WHERE "challenge_id" = $1) AND "status" = 'pending';
+`
+)
+
+// UpdateAcmeChallengeResult stores the validation result of a pending challenge
+// and moves its pending authorization to the same final status.
+func (v *Repo) UpdateAcmeChallengeResult(ctx context.Context, challengeId, status, errMsg string, validatedAt time.Time) error {
+	return v.Master().Tx(ctx, "acme_challenge_update_result", func(tx orm.Tx) {
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlUpdateAcmeChallengeResult, challengeId, status, errMsg, validatedAt)
+		})
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlUpdateAcmeAuthzResult, challengeId, status)
+		})
+	})
+}
+
+const sqlDeleteAcmeOrderExpired = `DELETE FROM "acme_order" WHERE "expires_at" < now();`
+
+// DeleteAcmeOrderExpired deletes expired orders, their authorizations and challenges go by cascade.
+func (v *Repo) DeleteAcmeOrderExpired(ctx context.Context) error {
+	return v.Master().Tx(ctx, "acme_order_delete_expired", func(tx orm.Tx) {
+		tx.Exec(func(e orm.Executor) {
+			e.SQL(sqlDeleteAcmeOrderExpired)
+		})
+	})
+}
@@ -0,0 +1,175 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package access
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.osspkg.com/validate"
+)
+
+const (
+	denyPrefix     = "!"
+	wildcardPrefix = "*."
+)
+
+// Rules is a parsed list of account domain grants. Supported entries:
+//
+//	example.com     - exact name only
+//	*.example.com   - any name below example.com, but not example.com itself
+//	!a.example.com  - deny an exact name
+//	!*.a.example.com - deny any name below a.example.com
+//
+// URI and email entries are described in identity.go.
+//
+// Deny entries always win over grants. A wildcard certificate name is never implied by a
+// suffix grant of a parent zone: *.a.example.com needs its own *.a.example.com entry.
+type Rules struct {
+	exact      map[string]struct{}
+	suffix     []string
+	deny       map[string]struct{}
+	denySuffix []string
+
+	uris       patterns
+	denyURIs   patterns
+	emails     patterns
+	denyEmails patterns
+}
+
+// NormalizeRule lowercases the entry and validates its syntax.
+func NormalizeRule(entry string) (string, error) {
+	entry = strings.TrimSpace(entry)
+
+	switch {
+	case isURIRule(entry):
+		return normalizeURIRule(entry)
+	case isEmailRule(entry):
+		return normalizeEmailRule(entry)
+	}
+
+	entry = strings.TrimSuffix(strings.ToLower(entry), ".")
+
+	name := strings.TrimPrefix(entry, denyPrefix)
+	name = strings.TrimPrefix(name, wildcardPrefix)
+
+	if !validate.IsValidDomain(name) {
+		return "", fmt.Errorf("invalid domain rule: %s", entry)
+	}
+
+	return entry, nil
+}
+
+func Parse(entries []string) (*Rules, error) {
+	r := &Rules{
+		exact:      make(map[string]struct{}, len(entries)),
+		deny:       make(map[string]struct{}, len(entries)),
+		uris:       newPatterns(),
+		denyURIs:   newPatterns(),
+		emails:     newPatterns(),
+		denyEmails: newPatterns(),
+	}
+
+	for _, entry := range entries {
+		entry, err := NormalizeRule(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		deny := strings.HasPrefix(entry, denyPrefix)
+		entry = strings.TrimPrefix(entry, denyPrefix)
+
+		switch {
+		case isURIRule(entry) && deny:
+			r.denyURIs.add(entry)
+		case isURIRule(entry):
+			r.uris.add(entry)
+		case isEmailRule(entry) && deny:
+			r.denyEmails.add(entry)
+		case isEmailRule(entry):
+			r.emails.add(entry)
+		case deny && strings.HasPrefix(entry, wildcardPrefix):
+			r.denySuffix = append(r.denySuffix, strings.TrimPrefix(entry, wildcardPrefix))
+		case deny:
+			r.deny[entry] = struct{}{}
+		case strings.HasPrefix(entry, wildcardPrefix):
+			r.suffix = append(r.suffix, strings.TrimPrefix(entry, wildcardPrefix))
+		default:
+			r.exact[entry] = struct{}{}
+		}
+	}
+
+	return r, nil
+}
+
+// Check returns a reason for every rejected name, an empty result means all names are allowed.
+// A requested wildcard name (*.zone) is treated as every name one label below the zone.
+func (r *Rules) Check(names ...string) []string {
+	var result []string
+	for _, name := range names {
+		if err := r.check(name); err != nil {
+			result = append(result, err.Error())
+		}
+	}
+	return result
+}
+
+func (r *Rules) check(name string) error {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+
+	if base, ok := strings.CutPrefix(name, wildcardPrefix); ok {
+		return r.checkWildcard(name, base)
+	}
+
+	if _, ok := r.deny[name]; ok {
+		return fmt.Errorf("%s: denied by rule %s%s", name, denyPrefix, name)
+	}
+	for _, zone := range r.denySuffix {
+		if isBelow(name, zone) {
+			return fmt.Errorf("%s: denied by rule %s%s%s", name, denyPrefix, wildcardPrefix, zone)
+		}
+	}
+
+	if _, ok := r.exact[name]; ok {
+		return nil
+	}
+	for _, zone := range r.suffix {
+		if isBelow(name, zone) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: not granted to account", name)
+}
+
+func (r *Rules) checkWildcard(name, base string) error {
+	for deny := range r.deny {
+		if parent(deny) == base {
+			return fmt.Errorf("%s: covers denied name %s", name, deny)
+		}
+	}
+	for _, zone := range r.denySuffix {
+		if base == zone || isBelow(base, zone) {
+			return fmt.Errorf("%s: denied by rule %s%s%s", name, denyPrefix, wildcardPrefix, zone)
+		}
+	}
+
+	if slices.Contains(r.suffix, base) {
+		return nil
+	}
+
+	return fmt.Errorf("%s: wildcard is not granted to account", name)
+}
+
+func isBelow(name, zone string) bool {
+	return strings.HasSuffix(name, "."+zone)
+}
+
+func parent(name string) string {
+	_, p, _ := strings.Cut(name, ".")
+	return p
+}
@@ -0,0 +1,113 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package access
+
+import (
+	"testing"
+)
+
+func TestNormalizeRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		want    string
+		wantErr bool
+	}{
+		{name: "exact", entry: " Example.COM. ", want: "example.com"},
+		{name: "wildcard", entry: "*.Example.com", want: "*.example.com"},
+		{name: "deny", entry: "!A.example.com", want: "!a.example.com"},
+		{name: "deny wildcard", entry: "!*.a.example.com", want: "!*.a.example.com"},
+		{name: "uri", entry: "SPIFFE://TD/ns/A/*", want: "spiffe://td/ns/A/*"},
+		{name: "email", entry: "User@Example.COM", want: "User@example.com"},
+		{name: "email domain", entry: "!*@example.com", want: "!*@example.com"},
+		{name: "inner wildcard", entry: "a.*.example.com", wantErr: true},
+		{name: "invalid domain", entry: "exa mple.com", wantErr: true},
+		{name: "uri without host", entry: "spiffe:///ns/a", wantErr: true},
+		{name: "partial email wildcard", entry: "us*@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRule(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NormalizeRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+	rules, err := Parse([]string{
+		"example.com",
+		"*.example.com",
+		"!deny.example.com",
+		"!*.secure.example.com",
+		"*.a.example.com",
+		"*.secure.example.com",
+		"*.b.example.com",
+		"!x.b.example.com",
+		"other.org",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		domain string
+		want   bool
+	}{
+		{name: "exact", domain: "example.com", want: true},
+		{name: "exact case and trailing dot", domain: "Other.ORG.", want: true},
+		{name: "exact does not cover subdomains", domain: "www.other.org", want: false},
+		{name: "below zone", domain: "www.example.com", want: true},
+		{name: "deep below zone", domain: "a.b.c.example.com", want: true},
+		{name: "zone itself needs own grant", domain: "a.example.com", want: true},
+		{name: "not granted", domain: "example.org", want: false},
+		{name: "suffix without dot", domain: "badexample.com", want: false},
+		{name: "deny wins over zone grant", domain: "deny.example.com", want: false},
+		{name: "deny zone wins over zone grant", domain: "www.secure.example.com", want: false},
+		{name: "deny zone keeps zone itself", domain: "secure.example.com", want: true},
+		{name: "wildcard granted", domain: "*.a.example.com", want: true},
+		{name: "wildcard of zone with denied name", domain: "*.example.com", want: false},
+		{name: "wildcard not implied by parent zone", domain: "*.c.example.com", want: false},
+		{name: "wildcard covers denied name", domain: "*.b.example.com", want: false},
+		{name: "wildcard in denied zone", domain: "*.secure.example.com", want: false},
+		{name: "wildcard below denied zone", domain: "*.x.secure.example.com", want: false},
+		{name: "wildcard not granted", domain: "*.other.org", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Check(tt.domain)
+			if (len(got) == 0) != tt.want {
+				t.Fatalf("Check(%s) = %v, want allowed %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesCheckReasons(t *testing.T) {
+	rules, err := Parse([]string{"*.example.com", "!deny.example.com"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := rules.Check("a.example.com", "deny.example.com", "example.org")
+	want := []string{
+		"deny.example.com: denied by rule !deny.example.com",
+		"example.org: not granted to account",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Check() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Check()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package access
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.osspkg.com/validate"
+)
+
+// URI and email grants:
+//
+//	spiffe://td/ns/x/sa/y   - exact URI
+//	spiffe://td/ns/x/*      - any URI starting with spiffe://td/ns/x/
+//	user@example.com        - exact mailbox
+//	*@example.com           - any mailbox of example.com
+//
+// Both forms accept the deny prefix.
+
+type patterns struct {
+	exact  map[string]struct{}
+	prefix []string
+	suffix []string
+}
+
+func newPatterns() patterns {
+	return patterns{exact: make(map[string]struct{})}
+}
+
+func (p *patterns) add(entry string) {
+	switch {
+	case strings.HasSuffix(entry, "*"):
+		p.prefix = append(p.prefix, strings.TrimSuffix(entry, "*"))
+	case strings.HasPrefix(entry, "*"):
+		p.suffix = append(p.suffix, strings.TrimPrefix(entry, "*"))
+	default:
+		p.exact[entry] = struct{}{}
+	}
+}
+
+func (p *patterns) match(value string) (string, bool) {
+	if _, ok := p.exact[value]; ok {
+		return value, true
+	}
+	for _, prefix := range p.prefix {
+		if strings.HasPrefix(value, prefix) {
+			return prefix + "*", true
+		}
+	}
+	for _, suffix := range p.suffix {
+		if strings.HasSuffix(value, suffix) {
+			return "*" + suffix, true
+		}
+	}
+	return "", false
+}
+
+func isURIRule(entry string) bool {
+	return strings.Contains(entry, "://")
+}
+
+func isEmailRule(entry string) bool {
+	return !isURIRule(entry) && strings.Contains(entry, "@")
+}
+
+func normalizeURIRule(entry string) (string, error) {
+	deny := strings.HasPrefix(entry, denyPrefix)
+	raw := strings.TrimPrefix(entry, denyPrefix)
+
+	wild := strings.HasSuffix(raw, "/*")
+	u, err := url.Parse(strings.TrimSuffix(raw, "*"))
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 || strings.Contains(u.Host, "*") {
+		return "", fmt.Errorf("invalid uri rule: %s", entry)
+	}
+
+	result := NormalizeURI(u)
+	if wild {
+		result += "*"
+	}
+	if deny {
+		result = denyPrefix + result
+	}
+	return result, nil
+}
+
+func normalizeEmailRule(entry string) (string, error) {
+	deny := strings.HasPrefix(entry, denyPrefix)
+	raw := strings.TrimPrefix(entry, denyPrefix)
+
+	local, domain, ok := strings.Cut(raw, "@")
+	if !ok || len(local) == 0 || strings.Contains(domain, "@") || !validate.IsValidDomain(strings.ToLower(domain)) ||
+		(strings.Contains(local, "*") && local != "*") {
+		return "", fmt.Errorf("invalid email rule: %s", entry)
+	}
+
+	result := NormalizeEmail(raw)
+	if deny {
+		result = denyPrefix + result
+	}
+	return result, nil
+}
+
+// NormalizeURI lowercases the scheme and the host, the path is kept as is.
+func NormalizeURI(u *url.URL) string {
+	c := *u
+	c.Scheme = strings.ToLower(c.Scheme)
+	c.Host = strings.ToLower(c.Host)
+	return c.String()
+}
+
+// NormalizeEmail lowercases the domain part, the local part is case-sensitive.
+func NormalizeEmail(email string) string {
+	local, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok {
+		return email
+	}
+	return local + "@" + strings.ToLower(domain)
+}
+
+// CheckURIs returns a reason for every rejected URI. Prefix grants compare the path as is,
+// so a path with dot segments is rejected instead of being matched.
+func (r *Rules) CheckURIs(uris ...*url.URL) []string {
+	var result []string
+	for _, u := range uris {
+		value := NormalizeURI(u)
+		if hasDotSegment(u.Path) {
+			result = append(result, fmt.Sprintf("%s: path has dot segments", value))
+			continue
+		}
+		if rule, ok := r.denyURIs.match(value); ok {
+			result = append(result, fmt.Sprintf("%s: denied by rule %s%s", value, denyPrefix, rule))
+			continue
+		}
+		if _, ok := r.uris.match(value); !ok {
+			result = append(result, fmt.Sprintf("%s: not granted to account", value))
+		}
+	}
+	return result
+}
+
+func hasDotSegment(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEmails returns a reason for every rejected email address.
+func (r *Rules) CheckEmails(emails ...string) []string {
+	var result []string
+	for _, email := range emails {
+		value := NormalizeEmail(email)
+		if rule, ok := r.denyEmails.match(value); ok {
+			result = append(result, fmt.Sprintf("%s: denied by rule %s%s", value, denyPrefix, rule))
+			continue
+		}
+		if _, ok := r.emails.match(value); !ok {
+			result = append(result, fmt.Sprintf("%s: not granted to account", value))
+		}
+	}
+	return result
+}
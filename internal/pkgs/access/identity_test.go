@@ -0,0 +1,92 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package access
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRulesCheckURIs(t *testing.T) {
+	rules, err := Parse([]string{
+		"spiffe://td/ns/a/*",
+		"spiffe://td/ns/b/sa/web",
+		"!spiffe://td/ns/a/sa/admin",
+		"https://example.com/*",
+		"!https://example.com/private/*",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		uri  string
+		want bool
+	}{
+		{name: "prefix", uri: "spiffe://td/ns/a/sa/web", want: true},
+		{name: "prefix case of host", uri: "SPIFFE://TD/ns/a/sa/web", want: true},
+		{name: "prefix path is case-sensitive", uri: "spiffe://td/ns/A/sa/web", want: false},
+		{name: "prefix needs separator", uri: "spiffe://td/ns/ab/sa/web", want: false},
+		{name: "exact", uri: "spiffe://td/ns/b/sa/web", want: true},
+		{name: "exact does not cover longer path", uri: "spiffe://td/ns/b/sa/web/x", want: false},
+		{name: "other trust domain", uri: "spiffe://other/ns/a/sa/web", want: false},
+		{name: "deny exact wins over prefix", uri: "spiffe://td/ns/a/sa/admin", want: false},
+		{name: "https prefix", uri: "https://example.com/app", want: true},
+		{name: "deny prefix wins over prefix", uri: "https://example.com/private/key", want: false},
+		{name: "other scheme", uri: "http://example.com/app", want: false},
+		{name: "dot segments escape prefix", uri: "spiffe://td/ns/a/../../admin", want: false},
+		{name: "encoded dot segments", uri: "spiffe://td/ns/a/%2e%2e/b", want: false},
+		{name: "current dir segment", uri: "spiffe://td/ns/a/./sa/web", want: false},
+		{name: "dots inside segment", uri: "spiffe://td/ns/a/sa/web..v1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.uri)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+			got := rules.CheckURIs(u)
+			if (len(got) == 0) != tt.want {
+				t.Fatalf("CheckURIs(%s) = %v, want allowed %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesCheckEmails(t *testing.T) {
+	rules, err := Parse([]string{
+		"*@example.com",
+		"!root@example.com",
+		"admin@other.org",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		email string
+		want  bool
+	}{
+		{name: "domain", email: "user@example.com", want: true},
+		{name: "domain case", email: "user@EXAMPLE.com", want: true},
+		{name: "subdomain is not the domain", email: "user@sub.example.com", want: false},
+		{name: "suffix without at", email: "user@badexample.com", want: false},
+		{name: "deny wins over domain", email: "root@example.com", want: false},
+		{name: "exact", email: "admin@other.org", want: true},
+		{name: "exact local part is case-sensitive", email: "Admin@other.org", want: false},
+		{name: "not granted", email: "user@other.org", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.CheckEmails(tt.email)
+			if (len(got) == 0) != tt.want {
+				t.Fatalf("CheckEmails(%s) = %v, want allowed %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ChallengeHTTP01 = "http-01"
+	ChallengeDNS01  = "dns-01"
+)
+
+type Validator struct {
+	cli      *http.Client
+	resolver *net.Resolver
+}
+
+func NewValidator(c Config) *Validator {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	}
+	if len(c.HTTP01Target) > 0 {
+		target := c.HTTP01Target
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, target)
+		}
+	}
+
+	resolver := net.DefaultResolver
+	if len(c.DNSResolver) > 0 {
+		addr := c.DNSResolver
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	}
+
+	return &Validator{
+		cli: &http.Client{
+			Transport: transport,
+			Timeout:   c.ValidationTimeout,
+		},
+		resolver: resolver,
+	}
+}
+
+// Validate checks the challenge for the domain using the key authorization.
+func (v *Validator) Validate(ctx context.Context, typ, domain, token, keyAuth string) error {
+	switch typ {
+	case ChallengeHTTP01:
+		return v.http01(ctx, domain, token, keyAuth)
+	case ChallengeDNS01:
+		return v.dns01(ctx, domain, keyAuth)
+	default:
+		return fmt.Errorf("unsupported challenge type %q", typ)
+	}
+}
+
+func (v *Validator) http01(ctx context.Context, domain, token, keyAuth string) error {
+	uri := "http://" + domain + "/.well-known/acme-challenge/" + token
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := v.cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", uri, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s: got status %d", uri, resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", uri, err)
+	}
+
+	if strings.TrimSpace(string(b)) != keyAuth {
+		return fmt.Errorf("key authorization mismatch at %s", uri)
+	}
+
+	return nil
+}
+
+func (v *Validator) dns01(ctx context.Context, domain, keyAuth string) error {
+	name := "_acme-challenge." + strings.TrimPrefix(domain, "*.")
+
+	records, err := v.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return fmt.Errorf("lookup TXT %s: %w", name, err)
+	}
+
+	if !slices.Contains(records, DNS01Value(keyAuth)) {
+		return fmt.Errorf("no matching TXT record at %s", name)
+	}
+
+	return nil
+}
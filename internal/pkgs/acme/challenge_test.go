@@ -0,0 +1,169 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidatorHTTP01(t *testing.T) {
+	const token, keyAuth = "token-1", "token-1.thumbprint"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "example.test" || r.URL.Path != "/.well-known/acme-challenge/"+token {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(keyAuth + "\n"))
+	}))
+	defer srv.Close()
+
+	v := NewValidator(Config{
+		HTTP01Target:      strings.TrimPrefix(srv.URL, "http://"),
+		ValidationTimeout: 5 * time.Second,
+	})
+
+	tests := []struct {
+		name    string
+		domain  string
+		token   string
+		keyAuth string
+		wantErr bool
+	}{
+		{name: "valid", domain: "example.test", token: token, keyAuth: keyAuth},
+		{name: "key authorization mismatch", domain: "example.test", token: token, keyAuth: "other", wantErr: true},
+		{name: "unknown token", domain: "example.test", token: "other", keyAuth: keyAuth, wantErr: true},
+		{name: "other domain", domain: "other.test", token: token, keyAuth: keyAuth, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(context.Background(), ChallengeHTTP01, tt.domain, tt.token, tt.keyAuth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatorDNS01(t *testing.T) {
+	const keyAuth = "token-1.thumbprint"
+
+	addr := startTXTServer(t, map[string]string{
+		"_acme-challenge.example.test.": DNS01Value(keyAuth),
+		"_acme-challenge.other.test.":   DNS01Value("other"),
+	})
+
+	v := NewValidator(Config{
+		DNSResolver:       addr,
+		ValidationTimeout: 5 * time.Second,
+	})
+
+	tests := []struct {
+		name    string
+		domain  string
+		wantErr bool
+	}{
+		{name: "valid", domain: "example.test"},
+		{name: "wildcard uses base domain", domain: "*.example.test"},
+		{name: "value mismatch", domain: "other.test", wantErr: true},
+		{name: "no record", domain: "missing.test", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(context.Background(), ChallengeDNS01, tt.domain, "token-1", keyAuth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// startTXTServer answers TXT queries over UDP from the records, other names get NXDOMAIN.
+func startTXTServer(t *testing.T, records map[string]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen udp: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp, err := dnsAnswer(buf[:n], records); err == nil {
+				_, _ = conn.WriteTo(resp, peer)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func dnsAnswer(req []byte, records map[string]string) ([]byte, error) {
+	if len(req) < 12 {
+		return nil, fmt.Errorf("short message")
+	}
+
+	var name strings.Builder
+	off := 12
+	for {
+		if off >= len(req) {
+			return nil, fmt.Errorf("invalid name")
+		}
+		l := int(req[off])
+		off++
+		if l == 0 {
+			break
+		}
+		if off+l > len(req) {
+			return nil, fmt.Errorf("invalid name")
+		}
+		name.WriteString(strings.ToLower(string(req[off:off+l])) + ".")
+		off += l
+	}
+	if off+4 > len(req) {
+		return nil, fmt.Errorf("invalid question")
+	}
+	qtype := binary.BigEndian.Uint16(req[off:])
+	question := req[12 : off+4]
+
+	resp := make([]byte, 12, 512)
+	copy(resp, req[:2])
+	binary.BigEndian.PutUint16(resp[2:], 0x8180)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	resp = append(resp, question...)
+
+	value, ok := records[name.String()]
+	if !ok {
+		resp[3] |= 3 // NXDOMAIN
+		return resp, nil
+	}
+	if qtype != 16 {
+		return resp, nil
+	}
+
+	binary.BigEndian.PutUint16(resp[6:], 1)
+	resp = append(resp, 0xc0, 0x0c)
+	resp = binary.BigEndian.AppendUint16(resp, 16)
+	resp = binary.BigEndian.AppendUint16(resp, 1)
+	resp = binary.BigEndian.AppendUint32(resp, 60)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(value)+1))
+	resp = append(resp, byte(len(value)))
+	return append(resp, value...), nil
+}
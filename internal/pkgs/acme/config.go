@@ -0,0 +1,27 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import "time"
+
+type Config struct {
+	Enabled           bool          `yaml:"enabled"`
+	BaseURL           string        `yaml:"base_url"`
+	HTTP01Target      string        `yaml:"http01_target"`
+	DNSResolver       string        `yaml:"dns_resolver"`
+	ValidationTimeout time.Duration `yaml:"validation_timeout"`
+	OrderTTL          time.Duration `yaml:"order_ttl"`
+	// NonceKey signs replay nonces, every instance behind one base url must use the same key.
+	// Empty generates a key at start, the nonces are then accepted by this instance only.
+	NonceKey string `yaml:"nonce_key"`
+}
+
+func (c *Config) Default() {
+	c.Enabled = false
+	c.BaseURL = "https://casper.domain"
+	c.ValidationTimeout = 30 * time.Second
+	c.OrderTTL = 24 * time.Hour
+}
@@ -0,0 +1,249 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+var b64 = base64.RawURLEncoding
+
+type JWS struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+type Header struct {
+	Alg   string          `json:"alg"`
+	Nonce string          `json:"nonce"`
+	URL   string          `json:"url"`
+	Kid   string          `json:"kid"`
+	JWK   json.RawMessage `json:"jwk"`
+}
+
+type Message struct {
+	Header  Header
+	Payload []byte
+
+	signingInput []byte
+	signature    []byte
+}
+
+// ParseJWS decodes a flattened JSON JWS (RFC 7515) without verifying the signature.
+func ParseJWS(b []byte) (*Message, error) {
+	raw := JWS{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("decode jws: %w", err)
+	}
+
+	hb, err := b64.DecodeString(raw.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("decode protected header: %w", err)
+	}
+
+	msg := &Message{
+		signingInput: []byte(raw.Protected + "." + raw.Payload),
+	}
+	if err = json.Unmarshal(hb, &msg.Header); err != nil {
+		return nil, fmt.Errorf("decode protected header: %w", err)
+	}
+	if msg.Payload, err = b64.DecodeString(raw.Payload); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	if msg.signature, err = b64.DecodeString(raw.Signature); err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+
+	if len(msg.Header.Kid) > 0 && len(msg.Header.JWK) > 0 {
+		return nil, fmt.Errorf("jwk and kid are mutually exclusive")
+	}
+
+	return msg, nil
+}
+
+// Verify checks the message signature with the account public key.
+func (m *Message) Verify(pub crypto.PublicKey) error {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		var (
+			hash crypto.Hash
+			size int
+		)
+		switch m.Header.Alg {
+		case "ES256":
+			hash, size = crypto.SHA256, 32
+		case "ES384":
+			hash, size = crypto.SHA384, 48
+		case "ES512":
+			hash, size = crypto.SHA512, 66
+		default:
+			return fmt.Errorf("unsupported alg %q for ecdsa key", m.Header.Alg)
+		}
+		if len(m.signature) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		h := hash.New()
+		h.Write(m.signingInput)
+		r := new(big.Int).SetBytes(m.signature[:size])
+		s := new(big.Int).SetBytes(m.signature[size:])
+		if !ecdsa.Verify(key, h.Sum(nil), r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		if m.Header.Alg != "RS256" {
+			return fmt.Errorf("unsupported alg %q for rsa key", m.Header.Alg)
+		}
+		h := sha256.Sum256(m.signingInput)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], m.signature)
+
+	case ed25519.PublicKey:
+		if m.Header.Alg != "EdDSA" {
+			return fmt.Errorf("unsupported alg %q for ed25519 key", m.Header.Alg)
+		}
+		if !ed25519.Verify(key, m.signingInput, m.signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// VerifyHMAC checks an HS256 signature, used by external account binding.
+func (m *Message) VerifyHMAC(key []byte) error {
+	if m.Header.Alg != "HS256" {
+		return fmt.Errorf("unsupported alg %q for external account binding", m.Header.Alg)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(m.signingInput)
+	if !hmac.Equal(mac.Sum(nil), m.signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// ParseJWK decodes an account public key.
+func ParseJWK(raw []byte) (crypto.PublicKey, error) {
+	k := jwk{}
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return nil, fmt.Errorf("decode jwk: %w", err)
+	}
+
+	switch k.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := b64.DecodeString(k.X)
+		y, errY := b64.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid ec key coordinates")
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("ec key is not on curve")
+		}
+		return pub, nil
+
+	case "RSA":
+		n, errN := b64.DecodeString(k.N)
+		e, errE := b64.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			return nil, fmt.Errorf("invalid rsa key")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("rsa key is too small")
+		}
+		return pub, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key.
+func Thumbprint(raw []byte) (string, error) {
+	k := jwk{}
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return "", fmt.Errorf("decode jwk: %w", err)
+	}
+
+	var canonical string
+	switch k.Kty {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	h := sha256.Sum256([]byte(canonical))
+	return b64.EncodeToString(h[:]), nil
+}
+
+// KeyAuthorization builds the challenge response expected from the client.
+func KeyAuthorization(token, thumbprint string) string {
+	return token + "." + thumbprint
+}
+
+// DNS01Value returns the TXT record value for the dns-01 challenge.
+func DNS01Value(keyAuth string) string {
+	h := sha256.Sum256([]byte(keyAuth))
+	return b64.EncodeToString(h[:])
+}
+
+func EncodeBase64(b []byte) string {
+	return b64.EncodeToString(b)
+}
+
+func DecodeBase64(s string) ([]byte, error) {
+	return b64.DecodeString(s)
+}
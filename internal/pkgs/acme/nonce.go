@@ -0,0 +1,72 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	NonceTTL = 15 * time.Minute
+
+	nonceRandSize = 8
+	nonceMacSize  = 16
+	nonceSize     = 8 + nonceRandSize + nonceMacSize
+)
+
+// Nonces issues stateless replay nonces: the issue time and a random part signed with the key.
+// Issuing keeps nothing in memory, so anyone can ask for nonces, and any instance sharing
+// the key verifies them. A nonce is single-use only if the caller records its use.
+type Nonces struct {
+	key []byte
+}
+
+func NewNonces(key []byte) *Nonces {
+	return &Nonces{key: key}
+}
+
+// NewNonceKey generates a random key for a single instance.
+func NewNonceKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
+
+func (n *Nonces) New(now time.Time) string {
+	b := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(b, uint64(now.Unix()))
+	_, _ = rand.Read(b[8 : 8+nonceRandSize])
+	copy(b[8+nonceRandSize:], n.mac(b[:8+nonceRandSize]))
+	return b64.EncodeToString(b)
+}
+
+// Verify checks the signature and the age of the nonce and returns when it expires.
+func (n *Nonces) Verify(nonce string, now time.Time) (time.Time, error) {
+	b, err := b64.Strict().DecodeString(nonce)
+	if err != nil || len(b) != nonceSize {
+		return time.Time{}, fmt.Errorf("invalid nonce")
+	}
+	if !hmac.Equal(b[8+nonceRandSize:], n.mac(b[:8+nonceRandSize])) {
+		return time.Time{}, fmt.Errorf("invalid nonce")
+	}
+
+	issued := time.Unix(int64(binary.BigEndian.Uint64(b)), 0)
+	if issued.After(now.Add(time.Minute)) || !issued.Add(NonceTTL).After(now) {
+		return time.Time{}, fmt.Errorf("expired nonce")
+	}
+	return issued.Add(NonceTTL), nil
+}
+
+func (n *Nonces) mac(b []byte) []byte {
+	m := hmac.New(sha256.New, n.key)
+	m.Write(b)
+	return m.Sum(nil)[:nonceMacSize]
+}
@@ -0,0 +1,52 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNonces(t *testing.T) {
+	now := time.Now()
+	issuer := NewNonces([]byte("shared key"))
+	other := NewNonces([]byte("shared key"))
+
+	nonce := issuer.New(now)
+	if nonce == issuer.New(now) {
+		t.Fatalf("nonces issued at the same time must differ")
+	}
+
+	tampered := []byte(nonce)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name    string
+		nonces  *Nonces
+		nonce   string
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "issuing instance", nonces: issuer, nonce: nonce, now: now},
+		{name: "instance with the same key", nonces: other, nonce: nonce, now: now.Add(NonceTTL - time.Second)},
+		{name: "other key", nonces: NewNonces([]byte("other key")), nonce: nonce, now: now, wantErr: true},
+		{name: "expired", nonces: issuer, nonce: nonce, now: now.Add(NonceTTL + time.Second), wantErr: true},
+		{name: "issued in the future", nonces: issuer, nonce: nonce, now: now.Add(-2 * time.Minute), wantErr: true},
+		{name: "tampered", nonces: issuer, nonce: string(tampered), now: now, wantErr: true},
+		{name: "garbage", nonces: issuer, nonce: "not-a-nonce", now: now, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expires, err := tt.nonces.Verify(tt.nonce, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !expires.After(tt.now) {
+				t.Fatalf("Verify() expires = %v, must be after %v", expires, tt.now)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"crypto/rand"
+	"slices"
+	"time"
+)
+
+const (
+	StatusPending     = "pending"
+	StatusReady       = "ready"
+	StatusProcessing  = "processing"
+	StatusValid       = "valid"
+	StatusInvalid     = "invalid"
+	StatusDeactivated = "deactivated"
+)
+
+type Identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type Challenge struct {
+	ID        string
+	Type      string
+	Token     string
+	Status    string
+	Validated time.Time
+	Error     string
+}
+
+type Authz struct {
+	ID         string
+	AccountID  int64
+	Identifier Identifier
+	Wildcard   bool
+	Status     string
+	Expires    time.Time
+	Challenges []Challenge
+}
+
+type Order struct {
+	ID           string
+	AccountID    int64
+	Status       string
+	Expires      time.Time
+	Identifiers  []Identifier
+	AuthzIDs     []string
+	Error        string
+	SerialNumber string
+	CertPEM      []byte
+}
+
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return b64.EncodeToString(b)
+}
+
+// Refresh recalculates the status of a pending or ready order from its authorizations,
+// the stored status changes only when the order is finalized.
+func (o *Order) Refresh(authzs []Authz, now time.Time) {
+	if o.Status != StatusPending && o.Status != StatusReady {
+		return
+	}
+	if o.Expires.Before(now) {
+		o.Status, o.Error = StatusInvalid, "order expired"
+		return
+	}
+
+	o.Status = StatusReady
+	for _, aid := range o.AuthzIDs {
+		i := slices.IndexFunc(authzs, func(a Authz) bool { return a.ID == aid })
+		if i < 0 {
+			o.Status, o.Error = StatusInvalid, "authorization failed"
+			return
+		}
+		switch authzs[i].Status {
+		case StatusValid:
+		case StatusPending:
+			o.Status = StatusPending
+		default:
+			o.Status, o.Error = StatusInvalid, "authorization failed"
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package acme
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderRefresh(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		status string
+		expire time.Duration
+		authzs []string
+		want   string
+	}{
+		{name: "all valid", status: StatusPending, expire: time.Hour, authzs: []string{StatusValid, StatusValid}, want: StatusReady},
+		{name: "one pending", status: StatusPending, expire: time.Hour, authzs: []string{StatusValid, StatusPending}, want: StatusPending},
+		{name: "one invalid", status: StatusReady, expire: time.Hour, authzs: []string{StatusPending, StatusInvalid}, want: StatusInvalid},
+		{name: "expired", status: StatusReady, expire: -time.Second, authzs: []string{StatusValid}, want: StatusInvalid},
+		{name: "processing is kept", status: StatusProcessing, expire: time.Hour, authzs: []string{StatusInvalid}, want: StatusProcessing},
+		{name: "valid is kept", status: StatusValid, expire: -time.Second, authzs: []string{StatusValid}, want: StatusValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Order{Status: tt.status, Expires: now.Add(tt.expire)}
+			authzs := make([]Authz, 0, len(tt.authzs))
+			for _, status := range tt.authzs {
+				a := Authz{ID: NewID(), Status: status}
+				authzs = append(authzs, a)
+				o.AuthzIDs = append(o.AuthzIDs, a.ID)
+			}
+
+			o.Refresh(authzs, now)
+			if o.Status != tt.want {
+				t.Fatalf("Refresh() status = %s, want %s", o.Status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+)
+
+// CAConstraints limit what an intermediate may issue (RFC 5280 4.2.1.9, 4.2.1.10),
+// a negative MaxPathLen leaves the path length unlimited.
+type CAConstraints struct {
+	PermittedDNS   []string
+	ExcludedDNS    []string
+	PermittedURI   []string
+	ExcludedURI    []string
+	PermittedEmail []string
+	ExcludedEmail  []string
+	MaxPathLen     int
+}
+
+func (c CAConstraints) IsEmpty() bool {
+	return c.MaxPathLen < 0 && len(c.PermittedDNS)+len(c.ExcludedDNS)+len(c.PermittedURI)+
+		len(c.ExcludedURI)+len(c.PermittedEmail)+len(c.ExcludedEmail) == 0
+}
+
+// NewKey generates a private key for the signature algorithm.
+func NewKey(alg x509.SignatureAlgorithm) (crypto.Signer, error) {
+	switch alg {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case x509.ECDSAWithSHA256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case x509.ECDSAWithSHA384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case x509.ECDSAWithSHA512:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg.String())
+	}
+}
+
+// NewIntermediateCA issues an intermediate with name constraints and path length,
+// which pki.NewIntermediateCA does not support. The parent must satisfy the constraints itself.
+func NewIntermediateCA(c pki.Config, parent pki.Certificate, d time.Duration, serial int64, cc CAConstraints) (*pki.Certificate, error) {
+	key, err := NewKey(c.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	ski, err := subjectKeyId(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	subject := pkix.Name{CommonName: c.CommonName}
+	if len(c.Organization) > 0 {
+		subject.Organization = []string{c.Organization}
+	}
+	if len(c.Country) > 0 {
+		subject.Country = []string{c.Country}
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               subject,
+		NotBefore:             now,
+		NotAfter:              now.Add(d),
+		SignatureAlgorithm:    c.SignatureAlgorithm,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            cc.MaxPathLen,
+		MaxPathLenZero:        cc.MaxPathLen == 0,
+		SubjectKeyId:          ski,
+		IssuingCertificateURL: nonEmpty(c.IssuingCertificateURLs),
+		OCSPServer:            nonEmpty(c.OCSPServerURLs),
+		CRLDistributionPoints: nonEmpty(c.CRLDistributionPointURLs),
+
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         cc.PermittedDNS,
+		ExcludedDNSDomains:          cc.ExcludedDNS,
+		PermittedURIDomains:         cc.PermittedURI,
+		ExcludedURIDomains:          cc.ExcludedURI,
+		PermittedEmailAddresses:     cc.PermittedEmail,
+		ExcludedEmailAddresses:      cc.ExcludedEmail,
+	}
+	if tmpl.MaxPathLen < 0 {
+		tmpl.MaxPathLen = -1
+	}
+
+	if cps := nonEmpty(c.CertificatePoliciesURLs); len(cps) > 0 {
+		ext, err := certificatePolicies(cps)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, ext)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent.Crt, key.Public(), parent.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+
+	return &pki.Certificate{Crt: crt, Key: key}, nil
+}
+
+func nonEmpty(list []string) []string {
+	return slices.DeleteFunc(slices.Clone(list), func(s string) bool { return len(s) == 0 })
+}
@@ -5,23 +5,98 @@
 
 package certs
 
+import "time"
+
 type ConfigGroup struct {
-	Certs []Config `yaml:"certs"`
+	Certs          []Config        `yaml:"certs"`
+	Profiles       []ProfileConfig `yaml:"cert_profiles"`
+	DefaultProfile string          `yaml:"cert_default_profile"`
+	Reload         ReloadConfig    `yaml:"certs_reload"`
 }
 
 type Config struct {
-	RootCaChain              []string `yaml:"root_ca_chain"`
-	IssuingCACert            string   `yaml:"issuing_ca_cert"`
-	IssuingCAKey             string   `yaml:"issuing_ca_key"`
-	Domains                  []string `yaml:"domains"`
-	DefaultExpireDays        int      `yaml:"default_expire_days"`
-	IssuingCertificateURLs   []string `yaml:"issuing_certificate_urls"`
-	OCSPServerURLs           []string `yaml:"ocsp_server_urls"`
-	CRLDistributionPointURLs []string `yaml:"crl_distribution_point_urls"`
-	CertificatePoliciesURLs  []string `yaml:"certificate_policies_urls"`
+	RootCaChain              []string       `yaml:"root_ca_chain"`
+	IssuingCACert            string         `yaml:"issuing_ca_cert"`
+	IssuingCAKey             string         `yaml:"issuing_ca_key"`
+	IssuingCAKeyPassphrase   Passphrase     `yaml:"issuing_ca_key_passphrase"`
+	IssuingCASigner          SignerConfig   `yaml:"issuing_ca_signer"`
+	Domains                  []string       `yaml:"domains"`
+	DefaultExpireDays        int            `yaml:"default_expire_days"`
+	IssuingCertificateURLs   []string       `yaml:"issuing_certificate_urls"`
+	OCSPServerURLs           []string       `yaml:"ocsp_server_urls"`
+	CRLDistributionPointURLs []string       `yaml:"crl_distribution_point_urls"`
+	DeltaCRLURLs             []string       `yaml:"delta_crl_urls"`
+	CertificatePoliciesURLs  []string       `yaml:"certificate_policies_urls"`
+	Wildcard                 Wildcard       `yaml:"wildcard"`
+	TrustDomains             []string       `yaml:"trust_domains"`
+	OCSPWindow               OCSPWindow     `yaml:"ocsp_window"`
+	Issuers                  []IssuerConfig `yaml:"issuers,omitempty"`
+}
+
+const (
+	IssuerActive   = "active"
+	IssuerRetiring = "retiring"
+	IssuerRetired  = "retired"
+)
+
+// IssuerConfig is an intermediate of the group for rotation: new certificates come from
+// the single active issuer, a retiring one only serves CRL and OCSP until it expires,
+// a retired one is ignored. Empty URLs are taken from the group.
+type IssuerConfig struct {
+	Cert                     string       `yaml:"cert"`
+	Key                      string       `yaml:"key"`
+	KeyPassphrase            Passphrase   `yaml:"key_passphrase"`
+	Signer                   SignerConfig `yaml:"signer"`
+	State                    string       `yaml:"state"`
+	IssuingCertificateURLs   []string     `yaml:"issuing_certificate_urls,omitempty"`
+	OCSPServerURLs           []string     `yaml:"ocsp_server_urls,omitempty"`
+	CRLDistributionPointURLs []string     `yaml:"crl_distribution_point_urls,omitempty"`
+	DeltaCRLURLs             []string     `yaml:"delta_crl_urls,omitempty"`
+}
+
+// IssuerList returns the issuers of the group, the issuing_ca_* fields are an active issuer.
+func (c Config) IssuerList() []IssuerConfig {
+	result := make([]IssuerConfig, 0, len(c.Issuers)+1)
+	if len(c.IssuingCACert) > 0 {
+		result = append(result, IssuerConfig{
+			Cert:          c.IssuingCACert,
+			Key:           c.IssuingCAKey,
+			KeyPassphrase: c.IssuingCAKeyPassphrase,
+			Signer:        c.IssuingCASigner,
+			State:         IssuerActive,
+		})
+	}
+	return append(result, c.Issuers...)
+}
+
+// Wildcard controls issuing of wildcard SANs, levels count labels including the asterisk,
+// so *.example.com is level 3. Zero MaxLevel means no upper limit.
+type Wildcard struct {
+	Allow    bool `yaml:"allow"`
+	MinLevel int  `yaml:"min_level"`
+	MaxLevel int  `yaml:"max_level"`
+}
+
+const DefaultOCSPValidity = 59 * time.Minute
+
+// OCSPWindow sets thisUpdate of OCSP responses back by Backdate for clients with skewed clocks,
+// nextUpdate is Validity after the signing time. Zero Validity is DefaultOCSPValidity.
+type OCSPWindow struct {
+	Backdate time.Duration `yaml:"backdate"`
+	Validity time.Duration `yaml:"validity"`
 }
 
 func (c *ConfigGroup) Default() {
+	if len(c.Profiles) == 0 {
+		c.Profiles = defaultProfiles()
+	}
+	if len(c.DefaultProfile) == 0 {
+		c.DefaultProfile = DefaultProfileName
+	}
+	if c.Reload.Interval <= 0 {
+		c.Reload.Interval = time.Minute
+	}
+
 	if len(c.Certs) > 0 {
 		return
 	}
@@ -34,12 +109,23 @@ func (c *ConfigGroup) Default() {
 			},
 			IssuingCACert:            "/path/to/issuing-ca-l2.crt",
 			IssuingCAKey:             "/path/to/issuing-ca-l2.key",
+			IssuingCASigner:          SignerConfig{Type: SignerFile},
 			Domains:                  []string{"localhost", "example.com"},
+			TrustDomains:             []string{"example.com"},
 			DefaultExpireDays:        30,
 			IssuingCertificateURLs:   []string{"http://pki.domain/icu/ca-l2.crt"},
 			OCSPServerURLs:           []string{"http://pki.domain/ocsp/ca-l2"},
 			CRLDistributionPointURLs: []string{"http://pki.domain/crl/ca-l2.crl"},
 			CertificatePoliciesURLs:  []string{"http://pki.domain/cps/ca-l2.html"},
+			Wildcard: Wildcard{
+				Allow:    false,
+				MinLevel: 3,
+				MaxLevel: 3,
+			},
+			OCSPWindow: OCSPWindow{
+				Backdate: 0,
+				Validity: DefaultOCSPValidity,
+			},
 		},
 	)
 }
@@ -0,0 +1,183 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PolicyError is returned by SignCSR when the issuer must not sign the CSR,
+// the request is rejected and nothing is wrong with the server.
+type PolicyError struct {
+	Err error
+}
+
+func (e *PolicyError) Error() string { return "issuer policy: " + e.Err.Error() }
+func (e *PolicyError) Unwrap() error { return e.Err }
+
+// checkPolicy verifies that the issuer may sign a certificate for the CSR at now and that
+// it would pass path validation: the issuer is active and outlives the certificate,
+// the SANs match name constraints of the issuer and its parents, path length,
+// key usage and extended key usage of every CA of the chain allow it.
+func (v *Certificate) checkPolicy(csr *x509.CertificateRequest, profile *Profile, now time.Time) error {
+	if err := v.checkIssuing(profile, now); err != nil {
+		return err
+	}
+
+	for depth, ca := range v.ChainCerts() {
+		name := ca.Subject.String()
+
+		if !ca.BasicConstraintsValid || !ca.IsCA {
+			return fmt.Errorf("certificate %s of the chain is not a CA", name)
+		}
+		if (ca.MaxPathLen > 0 || ca.MaxPathLenZero) && depth > ca.MaxPathLen {
+			return fmt.Errorf("CA %s allows path length %d, the chain has %d CA below it", name, ca.MaxPathLen, depth)
+		}
+		if ca.KeyUsage != 0 && ca.KeyUsage&x509.KeyUsageCertSign == 0 {
+			return fmt.Errorf("CA %s is not allowed to sign certificates", name)
+		}
+
+		if len(ca.ExtKeyUsage) > 0 && !slices.Contains(ca.ExtKeyUsage, x509.ExtKeyUsageAny) {
+			for _, eku := range profile.ExtKeyUsage {
+				if !slices.Contains(ca.ExtKeyUsage, eku) {
+					return fmt.Errorf("CA %s does not allow ext key usage %s of profile %s", name, extKeyUsageName(eku), profile.Name)
+				}
+			}
+		}
+
+		if err := checkNameConstraints(ca, csr); err != nil {
+			return fmt.Errorf("CA %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkIssuing rejects a retiring issuer and an issuer expiring before a certificate signed at now.
+func (v *Certificate) checkIssuing(profile *Profile, now time.Time) error {
+	if !v.IsActive() {
+		return fmt.Errorf("issuer %s is %s, new certificates are issued by the active one", v.Issuer.Crt.Subject, v.State)
+	}
+	if notAfter := now.Add(profile.Validity(v)); notAfter.After(v.Issuer.Crt.NotAfter) {
+		return fmt.Errorf("issuer %s expires at %s, before the certificate not after %s",
+			v.Issuer.Crt.Subject, v.Issuer.Crt.NotAfter.Format(time.RFC3339), notAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func checkNameConstraints(ca *x509.Certificate, csr *x509.CertificateRequest) error {
+	for _, dns := range csr.DNSNames {
+		if err := checkSubtrees("dns name", dns, ca.PermittedDNSDomains, ca.ExcludedDNSDomains, matchDNSConstraint); err != nil {
+			return err
+		}
+		// a wildcard covers every label of the zone, so it violates an excluded name one level below
+		if IsWildcard(dns) {
+			base := "." + strings.ToLower(TrimWildcard(dns))
+			for _, c := range ca.ExcludedDNSDomains {
+				label, ok := strings.CutSuffix(strings.ToLower(strings.TrimPrefix(c, ".")), base)
+				if ok && len(label) > 0 && !strings.Contains(label, ".") {
+					return fmt.Errorf("dns name %q covers excluded subtree %q", dns, c)
+				}
+			}
+		}
+	}
+
+	for _, email := range csr.EmailAddresses {
+		if err := checkSubtrees("email", email, ca.PermittedEmailAddresses, ca.ExcludedEmailAddresses, matchEmailConstraint); err != nil {
+			return err
+		}
+	}
+
+	for _, uri := range csr.URIs {
+		if len(ca.PermittedURIDomains)+len(ca.ExcludedURIDomains) == 0 {
+			continue
+		}
+		host := uri.Hostname()
+		if len(host) == 0 {
+			return fmt.Errorf("uri %q has no host to check against name constraints", uri.String())
+		}
+		if err := checkSubtrees("uri host", host, ca.PermittedURIDomains, ca.ExcludedURIDomains, matchHostConstraint); err != nil {
+			return fmt.Errorf("uri %q: %w", uri.String(), err)
+		}
+	}
+
+	for _, ip := range csr.IPAddresses {
+		for _, c := range ca.ExcludedIPRanges {
+			if c.Contains(ip) {
+				return fmt.Errorf("ip %s is in excluded subtree %s", ip, c)
+			}
+		}
+		if len(ca.PermittedIPRanges) > 0 && !slices.ContainsFunc(ca.PermittedIPRanges, func(c *net.IPNet) bool { return c.Contains(ip) }) {
+			return fmt.Errorf("ip %s is not in permitted subtrees %v", ip, ca.PermittedIPRanges)
+		}
+	}
+
+	return nil
+}
+
+func checkSubtrees(kind, name string, permitted, excluded []string, match func(name, constraint string) bool) error {
+	for _, c := range excluded {
+		if match(name, c) {
+			return fmt.Errorf("%s %q is in excluded subtree %q", kind, name, c)
+		}
+	}
+	if len(permitted) == 0 {
+		return nil
+	}
+	for _, c := range permitted {
+		if match(name, c) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q is not in permitted subtrees %s", kind, name, strings.Join(permitted, ", "))
+}
+
+// matchDNSConstraint follows RFC 5280: the constraint matches the domain and its subdomains,
+// a leading dot matches subdomains only.
+func matchDNSConstraint(name, constraint string) bool {
+	name, constraint = strings.ToLower(name), strings.ToLower(constraint)
+	if len(constraint) == 0 {
+		return true
+	}
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(name, constraint)
+	}
+	return name == constraint || strings.HasSuffix(name, "."+constraint)
+}
+
+// matchHostConstraint is the URI and email host rule: the exact host, or subdomains for a leading dot.
+func matchHostConstraint(host, constraint string) bool {
+	host, constraint = strings.ToLower(host), strings.ToLower(constraint)
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(host, constraint)
+	}
+	return host == constraint
+}
+
+// matchEmailConstraint accepts a full mailbox, a host or a domain with a leading dot.
+func matchEmailConstraint(email, constraint string) bool {
+	if strings.Contains(constraint, "@") {
+		return strings.EqualFold(email, constraint)
+	}
+	_, host, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+	return matchHostConstraint(host, constraint)
+}
+
+func extKeyUsageName(eku x509.ExtKeyUsage) string {
+	for name, v := range _extKeyUsage {
+		if v == eku {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", eku)
+}
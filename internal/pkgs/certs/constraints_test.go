@@ -0,0 +1,345 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+)
+
+func TestCheckIssuing(t *testing.T) {
+	now := time.Now()
+	issuer := &pki.Certificate{Crt: &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "CA Example"},
+		NotAfter: now.Add(60 * 24 * time.Hour),
+	}}
+	profile := &Profile{Name: "tls-server", MaxValidity: 90 * 24 * time.Hour}
+
+	tests := []struct {
+		name    string
+		state   string
+		days    int
+		wantErr string
+	}{
+		{name: "active within issuer validity", state: IssuerActive, days: 30},
+		{name: "retiring", state: IssuerRetiring, days: 30, wantErr: "is retiring"},
+		{name: "expires before certificate", state: IssuerActive, days: 61, wantErr: "expires at"},
+		{name: "profile limit beyond issuer", state: IssuerActive, wantErr: "expires at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca := &Certificate{Issuer: issuer, State: tt.state, Days: tt.days}
+			err := ca.checkIssuing(profile, now)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("checkIssuing() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkIssuing() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignCSRPolicy(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(testConfigGroup(testGroup(t, dir, "CA Example", "example.com")))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	ca, _, ok := store.Match("example.com")
+	if !ok {
+		t.Fatalf("Match() found no CA")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"www.example.com"}}, key)
+	if err != nil {
+		t.Fatalf("failed to create csr: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to parse csr: %v", err)
+	}
+	profile := &Profile{Name: "tls-server", KeyUsage: x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, MaxValidity: time.Hour}
+
+	retiring := *ca
+	retiring.State = IssuerRetiring
+
+	tests := []struct {
+		name       string
+		ca         *Certificate
+		wantPolicy bool
+	}{
+		{name: "active issuer", ca: ca},
+		{name: "retiring issuer", ca: &retiring, wantPolicy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crt, err := SignCSR(tt.ca, profile, csr, big.NewInt(time.Now().UnixNano()))
+			var policy *PolicyError
+			if got := errors.As(err, &policy); got != tt.wantPolicy {
+				t.Fatalf("SignCSR() error = %v, want policy error %v", err, tt.wantPolicy)
+			}
+			if tt.wantPolicy {
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignCSR() error = %v", err)
+			}
+			if crt.NotAfter.After(ca.Issuer.Crt.NotAfter) {
+				t.Fatalf("certificate not after %s is past the issuer %s", crt.NotAfter, ca.Issuer.Crt.NotAfter)
+			}
+		})
+	}
+}
+
+func TestMatchDNSConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		want       bool
+	}{
+		{name: "example.com", constraint: "example.com", want: true},
+		{name: "www.example.com", constraint: "example.com", want: true},
+		{name: "WWW.Example.COM", constraint: "example.com", want: true},
+		{name: "a.b.example.com", constraint: "example.com", want: true},
+		{name: "badexample.com", constraint: "example.com"},
+		{name: "example.com.evil.org", constraint: "example.com"},
+		{name: "example.com", constraint: ".example.com"},
+		{name: "www.example.com", constraint: ".example.com", want: true},
+		{name: "anything.org", constraint: "", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.constraint, func(t *testing.T) {
+			if got := matchDNSConstraint(tt.name, tt.constraint); got != tt.want {
+				t.Fatalf("matchDNSConstraint(%q, %q) = %v, want %v", tt.name, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchHostConstraint(t *testing.T) {
+	tests := []struct {
+		host       string
+		constraint string
+		want       bool
+	}{
+		{host: "example.com", constraint: "example.com", want: true},
+		{host: "EXAMPLE.com", constraint: "example.COM", want: true},
+		{host: "www.example.com", constraint: "example.com"},
+		{host: "www.example.com", constraint: ".example.com", want: true},
+		{host: "example.com", constraint: ".example.com"},
+		{host: "wwwexample.com", constraint: ".example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host+" "+tt.constraint, func(t *testing.T) {
+			if got := matchHostConstraint(tt.host, tt.constraint); got != tt.want {
+				t.Fatalf("matchHostConstraint(%q, %q) = %v, want %v", tt.host, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchEmailConstraint(t *testing.T) {
+	tests := []struct {
+		email      string
+		constraint string
+		want       bool
+	}{
+		{email: "admin@example.com", constraint: "admin@example.com", want: true},
+		{email: "Admin@Example.com", constraint: "admin@example.com", want: true},
+		{email: "root@example.com", constraint: "admin@example.com"},
+		{email: "admin@example.com", constraint: "example.com", want: true},
+		{email: "admin@mail.example.com", constraint: "example.com"},
+		{email: "admin@mail.example.com", constraint: ".example.com", want: true},
+		{email: "admin@example.com", constraint: ".example.com"},
+		{email: "not-an-email", constraint: "example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email+" "+tt.constraint, func(t *testing.T) {
+			if got := matchEmailConstraint(tt.email, tt.constraint); got != tt.want {
+				t.Fatalf("matchEmailConstraint(%q, %q) = %v, want %v", tt.email, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNameConstraints(t *testing.T) {
+	mustURL := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatalf("failed to parse url: %v", err)
+		}
+		return u
+	}
+	_, lan, _ := net.ParseCIDR("10.0.0.0/8")
+	_, dmz, _ := net.ParseCIDR("10.9.0.0/16")
+
+	ca := &x509.Certificate{
+		PermittedDNSDomains:     []string{"example.com", "example.org"},
+		ExcludedDNSDomains:      []string{"secret.example.com", ".internal.example.org"},
+		PermittedEmailAddresses: []string{"example.com", "ops@example.org"},
+		ExcludedEmailAddresses:  []string{"root@example.com"},
+		PermittedURIDomains:     []string{".example.com"},
+		ExcludedURIDomains:      []string{"admin.example.com"},
+		PermittedIPRanges:       []*net.IPNet{lan},
+		ExcludedIPRanges:        []*net.IPNet{dmz},
+	}
+
+	tests := []struct {
+		name    string
+		csr     x509.CertificateRequest
+		wantErr string
+	}{
+		{name: "permitted dns", csr: x509.CertificateRequest{DNSNames: []string{"www.example.com", "example.org"}}},
+		{name: "dns outside permitted", csr: x509.CertificateRequest{DNSNames: []string{"example.net"}},
+			wantErr: "not in permitted subtrees"},
+		{name: "excluded dns", csr: x509.CertificateRequest{DNSNames: []string{"db.secret.example.com"}},
+			wantErr: "excluded subtree"},
+		{name: "excluded dns with leading dot", csr: x509.CertificateRequest{DNSNames: []string{"a.internal.example.org"}},
+			wantErr: "excluded subtree"},
+		{name: "leading dot excludes subdomains only", csr: x509.CertificateRequest{DNSNames: []string{"internal.example.org"}}},
+		{name: "wildcard covers excluded subtree", csr: x509.CertificateRequest{DNSNames: []string{"*.example.com"}},
+			wantErr: "covers excluded subtree"},
+		{name: "wildcard covers excluded subtree with leading dot", csr: x509.CertificateRequest{DNSNames: []string{"*.example.org"}},
+			wantErr: "covers excluded subtree"},
+		{name: "wildcard beside excluded subtree", csr: x509.CertificateRequest{DNSNames: []string{"*.www.example.com"}}},
+		{name: "permitted email domain", csr: x509.CertificateRequest{EmailAddresses: []string{"dev@example.com"}}},
+		{name: "permitted mailbox", csr: x509.CertificateRequest{EmailAddresses: []string{"ops@example.org"}}},
+		{name: "email outside permitted", csr: x509.CertificateRequest{EmailAddresses: []string{"dev@example.org"}},
+			wantErr: "not in permitted subtrees"},
+		{name: "excluded mailbox", csr: x509.CertificateRequest{EmailAddresses: []string{"root@example.com"}},
+			wantErr: "excluded subtree"},
+		{name: "permitted uri", csr: x509.CertificateRequest{URIs: []*url.URL{mustURL("spiffe://svc.example.com/api")}}},
+		{name: "uri host is not a subdomain", csr: x509.CertificateRequest{URIs: []*url.URL{mustURL("https://example.com/")}},
+			wantErr: "not in permitted subtrees"},
+		{name: "excluded uri", csr: x509.CertificateRequest{URIs: []*url.URL{mustURL("https://admin.example.com/")}},
+			wantErr: "excluded subtree"},
+		{name: "uri without host", csr: x509.CertificateRequest{URIs: []*url.URL{mustURL("urn:example:svc")}},
+			wantErr: "has no host"},
+		{name: "permitted ip", csr: x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("10.1.2.3")}}},
+		{name: "ip outside permitted", csr: x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("192.0.2.1")}},
+			wantErr: "not in permitted subtrees"},
+		{name: "excluded ip", csr: x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("10.9.1.1")}},
+			wantErr: "excluded subtree"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNameConstraints(ca, &tt.csr)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("checkNameConstraints() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkNameConstraints() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("no constraints", func(t *testing.T) {
+		csr := &x509.CertificateRequest{
+			DNSNames:       []string{"*.example.net"},
+			EmailAddresses: []string{"a@example.net"},
+			URIs:           []*url.URL{mustURL("urn:example:svc")},
+			IPAddresses:    []net.IP{net.ParseIP("192.0.2.1")},
+		}
+		if err := checkNameConstraints(&x509.Certificate{}, csr); err != nil {
+			t.Fatalf("checkNameConstraints() error = %v", err)
+		}
+	})
+}
+
+func TestCheckPolicyChain(t *testing.T) {
+	now := time.Now()
+	newCA := func(name string, ski, aki byte, pathLen int, pathLenZero bool) *x509.Certificate {
+		return &x509.Certificate{
+			Subject:               pkix.Name{CommonName: name},
+			NotAfter:              now.Add(365 * 24 * time.Hour),
+			SubjectKeyId:          []byte{ski},
+			AuthorityKeyId:        []byte{aki},
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+			MaxPathLen:            pathLen,
+			MaxPathLenZero:        pathLenZero,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+	profile := &Profile{Name: "tls-server", ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, MaxValidity: time.Hour}
+	csr := &x509.CertificateRequest{DNSNames: []string{"www.example.com"}}
+
+	tests := []struct {
+		name    string
+		root    func(*x509.Certificate)
+		issuing func(*x509.Certificate)
+		wantErr string
+	}{
+		{name: "valid chain"},
+		{name: "root allows one CA below", root: func(c *x509.Certificate) { c.MaxPathLen = 1 }},
+		{name: "root allows no CA below", root: func(c *x509.Certificate) { c.MaxPathLen, c.MaxPathLenZero = 0, true },
+			wantErr: "allows path length 0"},
+		{name: "issuing CA with path length zero", issuing: func(c *x509.Certificate) { c.MaxPathLen, c.MaxPathLenZero = 0, true }},
+		{name: "issuing CA is not a CA", issuing: func(c *x509.Certificate) { c.IsCA = false }, wantErr: "is not a CA"},
+		{name: "root without cert sign", root: func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageCRLSign },
+			wantErr: "not allowed to sign certificates"},
+		{name: "issuing CA limits ext key usage",
+			issuing: func(c *x509.Certificate) { c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth} },
+			wantErr: "does not allow ext key usage server_auth"},
+		{name: "issuing CA allows any ext key usage",
+			issuing: func(c *x509.Certificate) { c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageAny} }},
+		{name: "root name constraints apply", root: func(c *x509.Certificate) { c.PermittedDNSDomains = []string{"example.org"} },
+			wantErr: "not in permitted subtrees"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newCA("CA Root", 1, 1, -1, false)
+			issuing := newCA("CA Issuing", 2, 1, -1, false)
+			if tt.root != nil {
+				tt.root(root)
+			}
+			if tt.issuing != nil {
+				tt.issuing(issuing)
+			}
+			ca := &Certificate{
+				Chain:  map[string]*x509.Certificate{hex.EncodeToString(root.SubjectKeyId): root},
+				Issuer: &pki.Certificate{Crt: issuing},
+				State:  IssuerActive,
+			}
+
+			err := ca.checkPolicy(csr, profile, now)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("checkPolicy() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
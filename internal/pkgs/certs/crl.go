@@ -0,0 +1,67 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+)
+
+var oidExtDeltaCRLIndicator = asn1.ObjectIdentifier{2, 5, 29, 27}
+
+// NewCRL builds a complete CRL, the entries keep serial numbers of any size.
+func NewCRL(ca *pki.Certificate, number int64, nextUpdate time.Duration, list []x509.RevocationListEntry) ([]byte, error) {
+	now := time.Now()
+	tmpl := &x509.RevocationList{
+		Number:                    big.NewInt(number),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(nextUpdate),
+		RevokedCertificateEntries: list,
+	}
+
+	b, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.Crt, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crl: %w", err)
+	}
+	return b, nil
+}
+
+// NewDeltaCRL builds a delta CRL (RFC 5280 5.2.4) for the complete CRL with the base number,
+// the list holds only the revocations made after that CRL.
+func NewDeltaCRL(ca *pki.Certificate, number, base int64, nextUpdate time.Duration, list []x509.RevocationListEntry) ([]byte, error) {
+	if number <= base {
+		return nil, fmt.Errorf("delta crl number %d must be greater than the base number %d", number, base)
+	}
+
+	indicator, err := asn1.Marshal(big.NewInt(base))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delta crl indicator: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.RevocationList{
+		Number:                    big.NewInt(number),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(nextUpdate),
+		RevokedCertificateEntries: list,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidExtDeltaCRLIndicator, Critical: true, Value: indicator},
+		},
+	}
+
+	b, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.Crt, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delta crl: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,307 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	oidExtOCSPNoCheck = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+
+	// ASN.1 NULL
+	ocspNoCheckValue = []byte{0x05, 0x00}
+)
+
+// NewOCSPResponder issues a delegated OCSP signing certificate (RFC 6960 4.2.2.2) with a new key.
+// It carries id-pkix-ocsp-nocheck, so clients do not check its own status, and lives no longer than the CA.
+func NewOCSPResponder(ca *pki.Certificate, validity time.Duration) (*pki.Certificate, error) {
+	key, err := NewKey(x509.ECDSAWithSHA256)
+	if err != nil {
+		return nil, err
+	}
+	ski, err := subjectKeyId(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial.Add(serial, big.NewInt(1)),
+		Subject:               pkix.Name{CommonName: ca.Crt.Subject.CommonName + " OCSP Responder"},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		SubjectKeyId:          ski,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidExtOCSPNoCheck, Value: ocspNoCheckValue},
+		},
+	}
+	if tmpl.NotAfter.After(ca.Crt.NotAfter) {
+		tmpl.NotAfter = ca.Crt.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Crt, key.Public(), ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ocsp responder certificate: %w", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ocsp responder certificate: %w", err)
+	}
+	return &pki.Certificate{Crt: crt, Key: key}, nil
+}
+
+// RFC 8954: responders accept nonces up to 32 octets and reject longer ones
+const maxOCSPNonceSize = 32
+
+var oidExtOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+type ocspRequestASN1 struct {
+	TBSRequest tbsRequestASN1
+	Signature  asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type tbsRequestASN1 struct {
+	Version       int           `asn1:"explicit,tag:0,default:0,optional"`
+	RequestorName asn1.RawValue `asn1:"explicit,tag:1,optional"`
+	RequestList   []asn1.RawValue
+	Extensions    []pkix.Extension `asn1:"explicit,tag:2,optional"`
+}
+
+// OCSPRequestNonce returns the nonce extension of the request to echo in the response,
+// nil if there is none. The ocsp package does not decode request extensions.
+func OCSPRequestNonce(der []byte) (*pkix.Extension, error) {
+	var req ocspRequestASN1
+	rest, err := asn1.Unmarshal(der, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ocsp request: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to decode ocsp request: trailing data")
+	}
+
+	for _, ext := range req.TBSRequest.Extensions {
+		if !ext.Id.Equal(oidExtOCSPNonce) {
+			continue
+		}
+		var nonce []byte
+		if rest, err = asn1.Unmarshal(ext.Value, &nonce); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("failed to decode ocsp nonce")
+		}
+		if len(nonce) == 0 || len(nonce) > maxOCSPNonceSize {
+			return nil, fmt.Errorf("ocsp nonce must be from 1 to %d octets, got %d", maxOCSPNonceSize, len(nonce))
+		}
+		return &pkix.Extension{Id: oidExtOCSPNonce, Value: ext.Value}, nil
+	}
+	return nil, nil
+}
+
+var (
+	oidOCSPBasic = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+
+	oidSignatureSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSignatureECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidSignatureECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidSignatureECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+)
+
+// ocspCertIDHashes are the CertID hashes the ocsp package parses.
+var ocspCertIDHashes = map[crypto.Hash]asn1.ObjectIdentifier{
+	crypto.SHA1:   {1, 3, 14, 3, 2, 26},
+	crypto.SHA256: {2, 16, 840, 1, 101, 3, 4, 2, 1},
+	crypto.SHA384: {2, 16, 840, 1, 101, 3, 4, 2, 2},
+	crypto.SHA512: {2, 16, 840, 1, 101, 3, 4, 2, 3},
+}
+
+// RFC 6960 4.2.1 structures of a basic response.
+type ocspResponseASN1 struct {
+	Status   asn1.Enumerated
+	Response ocspResponseBytesASN1 `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseBytesASN1 struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type ocspBasicResponseASN1 struct {
+	TBSResponseData    ocspResponseDataASN1
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseDataASN1 struct {
+	Version            int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          []ocspSingleResponseASN1
+	ResponseExtensions []pkix.Extension `asn1:"optional,explicit,tag:1"`
+}
+
+type ocspSingleResponseASN1 struct {
+	CertID     ocspCertIDASN1
+	Good       asn1.Flag           `asn1:"tag:0,optional"`
+	Revoked    ocspRevokedInfoASN1 `asn1:"tag:1,optional"`
+	Unknown    asn1.Flag           `asn1:"tag:2,optional"`
+	ThisUpdate time.Time           `asn1:"generalized"`
+	NextUpdate time.Time           `asn1:"generalized,explicit,tag:0,optional"`
+}
+
+type ocspCertIDASN1 struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRevokedInfoASN1 struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+// CreateOCSPResponse builds and signs the response as ocsp.CreateResponse does, but puts
+// template.ExtraExtensions into responseExtensions: the ocsp package writes them into
+// singleExtensions, RFC 8954 and clients expect the nonce among the response extensions.
+// The response is signed with SHA-256 for RSA and the hash of the curve for ECDSA.
+func CreateOCSPResponse(issuer, responderCert *x509.Certificate, template ocsp.Response, priv crypto.Signer) ([]byte, error) {
+	if template.IssuerHash == 0 {
+		template.IssuerHash = crypto.SHA1
+	}
+	hashOID, ok := ocspCertIDHashes[template.IssuerHash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported issuer hash algorithm %s", template.IssuerHash)
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, fmt.Errorf("failed to decode issuer public key: %w", err)
+	}
+	h := template.IssuerHash.New()
+	h.Write(spki.PublicKey.RightAlign())
+	keyHash := h.Sum(nil)
+	h.Reset()
+	h.Write(issuer.RawSubject)
+	nameHash := h.Sum(nil)
+
+	single := ocspSingleResponseASN1{
+		CertID: ocspCertIDASN1{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: hashOID, Parameters: asn1.NullRawValue},
+			NameHash:      nameHash,
+			IssuerKeyHash: keyHash,
+			SerialNumber:  template.SerialNumber,
+		},
+		ThisUpdate: template.ThisUpdate.UTC(),
+		NextUpdate: template.NextUpdate.UTC(),
+	}
+	switch template.Status {
+	case ocsp.Good:
+		single.Good = true
+	case ocsp.Unknown:
+		single.Unknown = true
+	case ocsp.Revoked:
+		single.Revoked = ocspRevokedInfoASN1{
+			RevocationTime: template.RevokedAt.UTC(),
+			Reason:         asn1.Enumerated(template.RevocationReason),
+		}
+	default:
+		return nil, fmt.Errorf("unknown ocsp status %d", template.Status)
+	}
+
+	tbs := ocspResponseDataASN1{
+		RawResponderID: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        1, // byName
+			IsCompound: true,
+			Bytes:      responderCert.RawSubject,
+		},
+		ProducedAt:         time.Now().Truncate(time.Minute).UTC(),
+		Responses:          []ocspSingleResponseASN1{single},
+		ResponseExtensions: template.ExtraExtensions,
+	}
+	tbsDER, err := asn1.Marshal(tbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ocsp response data: %w", err)
+	}
+
+	hash, alg, err := ocspSigningParams(priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	h = hash.New()
+	h.Write(tbsDER)
+	sig, err := priv.Sign(rand.Reader, h.Sum(nil), hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ocsp response: %w", err)
+	}
+
+	basic := ocspBasicResponseASN1{
+		TBSResponseData:    tbs,
+		SignatureAlgorithm: alg,
+		Signature:          asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	}
+	if template.Certificate != nil {
+		basic.Certificates = []asn1.RawValue{{FullBytes: template.Certificate.Raw}}
+	}
+	basicDER, err := asn1.Marshal(basic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode basic ocsp response: %w", err)
+	}
+
+	der, err := asn1.Marshal(ocspResponseASN1{
+		Status:   asn1.Enumerated(ocsp.Success),
+		Response: ocspResponseBytesASN1{ResponseType: oidOCSPBasic, Response: basicDER},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ocsp response: %w", err)
+	}
+	return der, nil
+}
+
+// ocspSigningParams picks the signature algorithm of the ocsp package for the key.
+func ocspSigningParams(pub crypto.PublicKey) (crypto.Hash, pkix.AlgorithmIdentifier, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return crypto.SHA256, pkix.AlgorithmIdentifier{Algorithm: oidSignatureSHA256WithRSA, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P224(), elliptic.P256():
+			return crypto.SHA256, pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256}, nil
+		case elliptic.P384():
+			return crypto.SHA384, pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA384}, nil
+		case elliptic.P521():
+			return crypto.SHA512, pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA512}, nil
+		}
+		return 0, pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported curve %s for ocsp responses", pub.Curve.Params().Name)
+	default:
+		return 0, pkix.AlgorithmIdentifier{}, fmt.Errorf("ocsp responses are signed with rsa and ecdsa keys only, got %T", pub)
+	}
+}
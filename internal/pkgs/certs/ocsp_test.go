@@ -0,0 +1,209 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+	"time"
+
+	"go.osspkg.com/encrypt/pki"
+	"golang.org/x/crypto/ocsp"
+)
+
+// testOCSPRequest builds a request for the CA itself with the extensions, the ocsp package writes none.
+func testOCSPRequest(t *testing.T, exts ...pkix.Extension) []byte {
+	t.Helper()
+
+	crt := loadTestCert(t, testCA(t, t.TempDir(), "CA Example", nil).Cert)
+	der, err := ocsp.CreateRequest(crt, crt, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		t.Fatalf("failed to create ocsp request: %v", err)
+	}
+	if len(exts) == 0 {
+		return der
+	}
+
+	var req ocspRequestASN1
+	if _, err = asn1.Unmarshal(der, &req); err != nil {
+		t.Fatalf("failed to decode ocsp request: %v", err)
+	}
+	req.TBSRequest.Extensions = exts
+	if der, err = asn1.Marshal(req); err != nil {
+		t.Fatalf("failed to encode ocsp request: %v", err)
+	}
+	return der
+}
+
+func testNonceExtension(t *testing.T, nonce []byte) pkix.Extension {
+	t.Helper()
+
+	b, err := asn1.Marshal(nonce)
+	if err != nil {
+		t.Fatalf("failed to encode nonce: %v", err)
+	}
+	return pkix.Extension{Id: oidExtOCSPNonce, Value: b}
+}
+
+func TestOCSPRequestNonce(t *testing.T) {
+	nonce := bytes.Repeat([]byte{0x5a}, maxOCSPNonceSize)
+
+	tests := []struct {
+		name    string
+		exts    []pkix.Extension
+		want    []byte
+		wantErr bool
+	}{
+		{name: "no extensions"},
+		{name: "other extension", exts: []pkix.Extension{{Id: oidExtOCSPNoCheck, Value: ocspNoCheckValue}}},
+		{name: "nonce", exts: []pkix.Extension{testNonceExtension(t, nonce[:16])}, want: nonce[:16]},
+		{name: "largest nonce", exts: []pkix.Extension{testNonceExtension(t, nonce)}, want: nonce},
+		{name: "oversize nonce", exts: []pkix.Extension{testNonceExtension(t, append(nonce, 0x5a))}, wantErr: true},
+		{name: "empty nonce", exts: []pkix.Extension{testNonceExtension(t, []byte{})}, wantErr: true},
+		{name: "nonce is not an octet string", exts: []pkix.Extension{{Id: oidExtOCSPNonce, Value: nonce}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OCSPRequestNonce(testOCSPRequest(t, tt.exts...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OCSPRequestNonce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("OCSPRequestNonce() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || !got.Id.Equal(oidExtOCSPNonce) {
+				t.Fatalf("OCSPRequestNonce() = %v, want the nonce extension", got)
+			}
+			var value []byte
+			if _, err = asn1.Unmarshal(got.Value, &value); err != nil || !bytes.Equal(value, tt.want) {
+				t.Fatalf("OCSPRequestNonce() value = %x, want %x", value, tt.want)
+			}
+		})
+	}
+
+	t.Run("trailing data", func(t *testing.T) {
+		der := append(testOCSPRequest(t), 0x00)
+		if _, err := OCSPRequestNonce(der); err == nil {
+			t.Fatalf("OCSPRequestNonce() accepted trailing data")
+		}
+	})
+}
+
+func TestCreateOCSPResponse(t *testing.T) {
+	nonce := testNonceExtension(t, []byte("nonce"))
+	revokedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		key       func() (crypto.Signer, error)
+		status    int
+		hash      crypto.Hash
+		exts      []pkix.Extension
+		delegated bool
+	}{
+		{name: "ecdsa p256", key: testECDSAKey(elliptic.P256()), status: ocsp.Good},
+		{name: "ecdsa p256 nonce", key: testECDSAKey(elliptic.P256()), status: ocsp.Good, exts: []pkix.Extension{nonce}},
+		{name: "ecdsa p384 nonce", key: testECDSAKey(elliptic.P384()), status: ocsp.Good, hash: crypto.SHA256, exts: []pkix.Extension{nonce}},
+		{name: "rsa nonce", key: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) }, status: ocsp.Good, exts: []pkix.Extension{nonce}},
+		{name: "revoked", key: testECDSAKey(elliptic.P256()), status: ocsp.Revoked, exts: []pkix.Extension{nonce}},
+		{name: "unknown", key: testECDSAKey(elliptic.P256()), status: ocsp.Unknown},
+		{name: "delegated nonce", key: testECDSAKey(elliptic.P256()), status: ocsp.Good, exts: []pkix.Extension{nonce}, delegated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.key()
+			if err != nil {
+				t.Fatalf("failed to generate key: %v", err)
+			}
+			crt := loadTestCert(t, testCA(t, t.TempDir(), "CA Example", key).Cert)
+
+			tmpl := ocsp.Response{
+				Status:           tt.status,
+				SerialNumber:     crt.SerialNumber,
+				ThisUpdate:       time.Now().Truncate(time.Second),
+				NextUpdate:       time.Now().Add(time.Hour).Truncate(time.Second),
+				IssuerHash:       tt.hash,
+				ExtraExtensions:  tt.exts,
+				RevokedAt:        revokedAt,
+				RevocationReason: ocsp.KeyCompromise,
+			}
+			responder := &pki.Certificate{Crt: crt, Key: key}
+			if tt.delegated {
+				if responder, err = NewOCSPResponder(&pki.Certificate{Crt: crt, Key: key}, time.Hour); err != nil {
+					t.Fatalf("NewOCSPResponder() error = %v", err)
+				}
+				tmpl.Certificate = responder.Crt
+			}
+
+			der, err := CreateOCSPResponse(crt, responder.Crt, tmpl, responder.Key)
+			if err != nil {
+				t.Fatalf("CreateOCSPResponse() error = %v", err)
+			}
+
+			resp, err := ocsp.ParseResponseForCert(der, crt, crt)
+			if err != nil {
+				t.Fatalf("failed to verify ocsp response: %v", err)
+			}
+			if resp.Status != tt.status || resp.SerialNumber.Cmp(crt.SerialNumber) != 0 {
+				t.Fatalf("ocsp response status %d serial %s, want %d %s", resp.Status, resp.SerialNumber, tt.status, crt.SerialNumber)
+			}
+			if !resp.ThisUpdate.Equal(tmpl.ThisUpdate) || !resp.NextUpdate.Equal(tmpl.NextUpdate) {
+				t.Fatalf("ocsp response window %s - %s, want %s - %s", resp.ThisUpdate, resp.NextUpdate, tmpl.ThisUpdate, tmpl.NextUpdate)
+			}
+			if tt.status == ocsp.Revoked && (!resp.RevokedAt.Equal(revokedAt) || resp.RevocationReason != ocsp.KeyCompromise) {
+				t.Fatalf("ocsp response revoked at %s reason %d, want %s %d", resp.RevokedAt, resp.RevocationReason, revokedAt, ocsp.KeyCompromise)
+			}
+			if tt.delegated && (resp.Certificate == nil || !resp.Certificate.Equal(responder.Crt)) {
+				t.Fatalf("ocsp response does not carry the delegated responder certificate")
+			}
+			if len(resp.Extensions) != 0 {
+				t.Fatalf("single extensions = %v, want none", resp.Extensions)
+			}
+
+			var (
+				outer ocspResponseASN1
+				basic ocspBasicResponseASN1
+			)
+			if _, err = asn1.Unmarshal(der, &outer); err != nil {
+				t.Fatalf("failed to decode ocsp response: %v", err)
+			}
+			if _, err = asn1.Unmarshal(outer.Response.Response, &basic); err != nil {
+				t.Fatalf("failed to decode basic ocsp response: %v", err)
+			}
+			if exts := basic.TBSResponseData.ResponseExtensions; len(exts) != len(tt.exts) ||
+				(len(tt.exts) > 0 && (!exts[0].Id.Equal(oidExtOCSPNonce) || !bytes.Equal(exts[0].Value, nonce.Value))) {
+				t.Fatalf("response extensions = %v, want %v", exts, tt.exts)
+			}
+		})
+	}
+
+	t.Run("ed25519", func(t *testing.T) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		crt := loadTestCert(t, testCA(t, t.TempDir(), "CA Example", nil).Cert)
+		_, err = CreateOCSPResponse(crt, crt, ocsp.Response{Status: ocsp.Good, SerialNumber: crt.SerialNumber}, key)
+		if err == nil {
+			t.Fatalf("CreateOCSPResponse() signed with an ed25519 key")
+		}
+	})
+}
+
+func testECDSAKey(curve elliptic.Curve) func() (crypto.Signer, error) {
+	return func() (crypto.Signer, error) { return ecdsa.GenerateKey(curve, rand.Reader) }
+}
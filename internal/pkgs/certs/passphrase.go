@@ -0,0 +1,84 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/term"
+)
+
+// Passphrase lists the sources of a private key passphrase, the first configured one is used:
+// a file, an environment variable, a systemd credential (LoadCredential=, read from
+// $CREDENTIALS_DIRECTORY) or an interactive prompt on the controlling terminal.
+// The server prompts on start only, Store.Reload keeps the keys it has opened.
+type Passphrase struct {
+	File       string `yaml:"file,omitempty"`
+	Env        string `yaml:"env,omitempty"`
+	Credential string `yaml:"systemd_credential,omitempty"`
+	Prompt     bool   `yaml:"prompt,omitempty"`
+}
+
+func (p Passphrase) Resolve(label string) ([]byte, error) {
+	switch {
+	case len(p.File) > 0:
+		b, err := os.ReadFile(p.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return nonEmptyPassphrase(bytes.TrimRight(b, "\r\n"))
+
+	case len(p.Env) > 0:
+		return nonEmptyPassphrase([]byte(os.Getenv(p.Env)))
+
+	case len(p.Credential) > 0:
+		dir := os.Getenv("CREDENTIALS_DIRECTORY")
+		if len(dir) == 0 {
+			return nil, fmt.Errorf("systemd credentials are not available, CREDENTIALS_DIRECTORY is not set")
+		}
+		b, err := os.ReadFile(filepath.Join(dir, p.Credential))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read systemd credential: %w", err)
+		}
+		return nonEmptyPassphrase(bytes.TrimRight(b, "\r\n"))
+
+	case p.Prompt:
+		return PromptPassphrase(fmt.Sprintf("Passphrase for %s: ", label))
+
+	default:
+		return nil, fmt.Errorf("key %s is encrypted, but no passphrase source is configured", label)
+	}
+}
+
+func nonEmptyPassphrase(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	return b, nil
+}
+
+// PromptPassphrase reads a line from the controlling terminal with echo turned off.
+func PromptPassphrase(prompt string) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("no terminal for the passphrase prompt: %w", err)
+	}
+	defer tty.Close()
+
+	if _, err = fmt.Fprint(tty, prompt); err != nil {
+		return nil, err
+	}
+
+	line, err := term.ReadPassword(int(tty.Fd()))
+	_, _ = fmt.Fprintln(tty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return nonEmptyPassphrase(line)
+}
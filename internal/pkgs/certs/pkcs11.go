@@ -0,0 +1,335 @@
+//go:build cgo
+
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"unsafe"
+
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Key is a private key of a PKCS#11 token, the key never leaves the token.
+// A session handles one operation at a time, so signing is serialized.
+type PKCS11Key struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	pub     crypto.PublicKey
+	owner   bool
+	mux     sync.Mutex
+}
+
+// OpenPKCS11Key loads the module, logs in to the token and finds the private key by label.
+func OpenPKCS11Key(conf PKCS11Config) (*PKCS11Key, error) {
+	if len(conf.Module) == 0 || len(conf.Token) == 0 || len(conf.KeyLabel) == 0 {
+		return nil, fmt.Errorf("pkcs11 requires module, token and key label")
+	}
+
+	ctx := pkcs11.New(conf.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load pkcs11 module %q", conf.Module)
+	}
+	k := &PKCS11Key{ctx: ctx, owner: true}
+
+	var opts []pkcs11.InitializeOption
+	if len(conf.ModuleArgs) > 0 {
+		// NSS softokn reads its parameters from pReserved, the string is used during the call only
+		args := append([]byte(conf.ModuleArgs), 0)
+		opts = append(opts, pkcs11.InitializeWithReserved(unsafe.Pointer(&args[0])))
+	}
+	if err := ctx.Initialize(opts...); err != nil {
+		if !isPKCS11Error(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+			ctx.Destroy()
+			return nil, fmt.Errorf("failed to initialize pkcs11 module: %w", err)
+		}
+		k.owner = false
+	}
+
+	if err := k.open(conf); err != nil {
+		_ = k.Close()
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *PKCS11Key) open(conf PKCS11Config) error {
+	slot, err := k.findSlot(conf.Token)
+	if err != nil {
+		return err
+	}
+	if k.session, err = k.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION); err != nil {
+		return fmt.Errorf("failed to open pkcs11 session: %w", err)
+	}
+	if err = k.ctx.Login(k.session, pkcs11.CKU_USER, conf.Pin); err != nil &&
+		!isPKCS11Error(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return fmt.Errorf("failed to log in to token %q: %w", conf.Token, err)
+	}
+
+	if k.key, err = k.findObject(pkcs11.CKO_PRIVATE_KEY, conf.KeyLabel, nil); err != nil {
+		return err
+	}
+	attrs, err := k.ctx.GetAttributeValue(k.session, k.key, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read key %q: %w", conf.KeyLabel, err)
+	}
+
+	switch keyType := bytesToUint(attrs[0].Value); keyType {
+	case pkcs11.CKK_RSA:
+		k.pub, err = k.rsaPublicKey()
+	case pkcs11.CKK_EC:
+		k.pub, err = k.ecPublicKey(conf.KeyLabel, attrs[1].Value)
+	default:
+		return fmt.Errorf("key %q has unsupported type %#x, rsa and ec keys are supported", conf.KeyLabel, keyType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read public key of %q: %w", conf.KeyLabel, err)
+	}
+	return nil
+}
+
+func (k *PKCS11Key) findSlot(token string) (uint, error) {
+	slots, err := k.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pkcs11 slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := k.ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == token {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11 token %q not found", token)
+}
+
+// findObject returns the single object of the class with the label, the id narrows it if given.
+func (k *PKCS11Key) findObject(class uint, label string, id []byte) (pkcs11.ObjectHandle, error) {
+	tmpl := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if len(id) > 0 {
+		tmpl = append(tmpl, pkcs11.NewAttribute(pkcs11.CKA_ID, id))
+	}
+	if err := k.ctx.FindObjectsInit(k.session, tmpl); err != nil {
+		return 0, fmt.Errorf("failed to search key %q: %w", label, err)
+	}
+	list, _, err := k.ctx.FindObjects(k.session, 2)
+	if e := k.ctx.FindObjectsFinal(k.session); err == nil {
+		err = e
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to search key %q: %w", label, err)
+	}
+
+	switch len(list) {
+	case 0:
+		return 0, fmt.Errorf("key %q not found", label)
+	case 1:
+		return list[0], nil
+	default:
+		return 0, fmt.Errorf("key label %q is not unique", label)
+	}
+}
+
+func (k *PKCS11Key) rsaPublicKey() (crypto.PublicKey, error) {
+	attrs, err := k.ctx.GetAttributeValue(k.session, k.key, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	e := new(big.Int).SetBytes(attrs[1].Value)
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("rsa public exponent is too large")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(attrs[0].Value), E: int(e.Int64())}, nil
+}
+
+// ecPublicKey reads the point of the public key object, the private one has no CKA_EC_POINT.
+func (k *PKCS11Key) ecPublicKey(label string, id []byte) (crypto.PublicKey, error) {
+	obj, err := k.findObject(pkcs11.CKO_PUBLIC_KEY, label, id)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := k.ctx.GetAttributeValue(k.session, obj, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var oid asn1.ObjectIdentifier
+	if _, err = asn1.Unmarshal(attrs[0].Value, &oid); err != nil {
+		return nil, fmt.Errorf("failed to decode ec params: %w", err)
+	}
+	var curve elliptic.Curve
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidNamedCurveP384):
+		curve = elliptic.P384()
+	case oid.Equal(oidNamedCurveP521):
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", oid)
+	}
+
+	// PKCS#11 wraps the point into an OCTET STRING, some tokens return it bare
+	point := attrs[1].Value
+	var wrapped []byte
+	if rest, err := asn1.Unmarshal(point, &wrapped); err == nil && len(rest) == 0 {
+		point = wrapped
+	}
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
+
+func (k *PKCS11Key) Public() crypto.PublicKey { return k.pub }
+
+// Sign signs the digest with CKM_ECDSA, CKM_RSA_PKCS or CKM_RSA_PKCS_PSS,
+// the result is encoded as crypto/ecdsa and crypto/rsa do.
+func (k *PKCS11Key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if hash == 0 || len(digest) != hash.Size() {
+		return nil, fmt.Errorf("pkcs11 key signs digests only, got %d bytes for %s", len(digest), hash)
+	}
+
+	var (
+		mech *pkcs11.Mechanism
+		data = digest
+	)
+	switch k.pub.(type) {
+	case *ecdsa.PublicKey:
+		mech = pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)
+	case *rsa.PublicKey:
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			params, err := pkcs11PSSParams(hash, pss.SaltLength)
+			if err != nil {
+				return nil, err
+			}
+			mech = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_PSS, params)
+			break
+		}
+		prefix, ok := pkcs1DigestPrefixes[hash]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hash %s", hash)
+		}
+		mech = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)
+		data = append(append([]byte{}, prefix...), digest...)
+	}
+
+	k.mux.Lock()
+	defer k.mux.Unlock()
+
+	if err := k.ctx.SignInit(k.session, []*pkcs11.Mechanism{mech}, k.key); err != nil {
+		return nil, fmt.Errorf("failed to init pkcs11 sign: %w", err)
+	}
+	sig, err := k.ctx.Sign(k.session, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with pkcs11: %w", err)
+	}
+
+	if _, ok := k.pub.(*ecdsa.PublicKey); ok {
+		// CKM_ECDSA returns r || s
+		half := len(sig) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			R: new(big.Int).SetBytes(sig[:half]),
+			S: new(big.Int).SetBytes(sig[half:]),
+		})
+	}
+	return sig, nil
+}
+
+// Close logs out and finalizes the module if this key initialized it.
+func (k *PKCS11Key) Close() error {
+	var errs []error
+	if k.session != 0 {
+		if err := k.ctx.Logout(k.session); err != nil && !isPKCS11Error(err, pkcs11.CKR_USER_NOT_LOGGED_IN) {
+			errs = append(errs, err)
+		}
+		if err := k.ctx.CloseSession(k.session); err != nil {
+			errs = append(errs, err)
+		}
+		k.session = 0
+	}
+	if k.owner {
+		if err := k.ctx.Finalize(); err != nil {
+			errs = append(errs, err)
+		}
+		k.ctx.Destroy()
+		k.owner = false
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// pkcs1DigestPrefixes are the DER DigestInfo headers CKM_RSA_PKCS expects before the digest.
+var pkcs1DigestPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+var pkcs11PSSHashes = map[crypto.Hash][2]uint{
+	crypto.SHA1:   {pkcs11.CKM_SHA_1, pkcs11.CKG_MGF1_SHA1},
+	crypto.SHA256: {pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256},
+	crypto.SHA384: {pkcs11.CKM_SHA384, pkcs11.CKG_MGF1_SHA384},
+	crypto.SHA512: {pkcs11.CKM_SHA512, pkcs11.CKG_MGF1_SHA512},
+}
+
+// pkcs11PSSParams maps the salt length of crypto/rsa, auto and equals hash both sign with the hash size.
+func pkcs11PSSParams(hash crypto.Hash, saltLength int) ([]byte, error) {
+	h, ok := pkcs11PSSHashes[hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash %s", hash)
+	}
+	if saltLength == rsa.PSSSaltLengthAuto || saltLength == rsa.PSSSaltLengthEqualsHash {
+		saltLength = hash.Size()
+	}
+	if saltLength < 0 {
+		return nil, fmt.Errorf("invalid pss salt length %d", saltLength)
+	}
+	return pkcs11.NewPSSParams(h[0], h[1], uint(saltLength)), nil
+}
+
+func isPKCS11Error(err error, code uint) bool {
+	var e pkcs11.Error
+	return errors.As(err, &e) && uint(e) == code
+}
+
+func bytesToUint(b []byte) uint {
+	var v uint
+	// CK_ULONG is in the byte order of the host, little endian on every supported platform
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint(b[i])
+	}
+	return v
+}
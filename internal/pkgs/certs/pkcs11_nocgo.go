@@ -0,0 +1,29 @@
+//go:build !cgo
+
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+)
+
+// PKCS11Key needs cgo to load the module, without it OpenPKCS11Key always fails.
+type PKCS11Key struct{}
+
+func OpenPKCS11Key(PKCS11Config) (*PKCS11Key, error) {
+	return nil, fmt.Errorf("pkcs11 is not supported, casper-cli is built without cgo")
+}
+
+func (*PKCS11Key) Public() crypto.PublicKey { return nil }
+
+func (*PKCS11Key) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return nil, fmt.Errorf("pkcs11 is not supported, casper-cli is built without cgo")
+}
+
+func (*PKCS11Key) Close() error { return nil }
@@ -0,0 +1,302 @@
+//go:build cgo
+
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/miekg/pkcs11"
+)
+
+const testPKCS11Pin = "1234"
+
+// testPKCS11Token initializes a software token in a temporary directory: SoftHSM if it is
+// installed, NSS softokn otherwise, CASPER_PKCS11_MODULE picks the module explicitly.
+// The returned config has no key label, keys are generated by gen while the token is open.
+func testPKCS11Token(t *testing.T, gen func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle)) PKCS11Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	find := func(paths ...string) string {
+		for _, p := range paths {
+			if _, err := os.Stat(p); err == nil {
+				return p
+			}
+		}
+		return ""
+	}
+	softhsm := find(os.Getenv("CASPER_PKCS11_MODULE"), "/usr/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so", "/usr/local/lib/softhsm/libsofthsm2.so")
+	nss := find("/usr/lib/x86_64-linux-gnu/libsoftokn3.so", "/usr/lib64/libsoftokn3.so", "/usr/lib/libsoftokn3.so")
+
+	var conf PKCS11Config
+	switch {
+	case len(softhsm) > 0:
+		tokens := filepath.Join(dir, "tokens")
+		if err := os.Mkdir(tokens, 0700); err != nil {
+			t.Fatalf("failed to create token dir: %v", err)
+		}
+		file := filepath.Join(dir, "softhsm2.conf")
+		if err := os.WriteFile(file, []byte("directories.tokendir = "+tokens+"\n"), 0600); err != nil {
+			t.Fatalf("failed to write softhsm config: %v", err)
+		}
+		t.Setenv("SOFTHSM2_CONF", file)
+		conf = PKCS11Config{Module: softhsm, Token: "casper"}
+	case len(nss) > 0:
+		conf = PKCS11Config{
+			Module:     nss,
+			ModuleArgs: fmt.Sprintf("configdir='sql:%s' certPrefix='' keyPrefix='' secmod='' flags=", dir),
+			Token:      "NSS Certificate DB",
+		}
+	default:
+		t.Skip("no software PKCS#11 token, install SoftHSM or set CASPER_PKCS11_MODULE")
+	}
+	conf.Pin = testPKCS11Pin
+
+	ctx := pkcs11.New(conf.Module)
+	if ctx == nil {
+		t.Fatalf("failed to load %s", conf.Module)
+	}
+	defer ctx.Destroy()
+
+	var err error
+	if len(conf.ModuleArgs) > 0 {
+		args := append([]byte(conf.ModuleArgs), 0)
+		err = ctx.Initialize(pkcs11.InitializeWithReserved(unsafe.Pointer(&args[0])))
+	} else {
+		err = ctx.Initialize()
+	}
+	if err != nil {
+		t.Fatalf("failed to initialize %s: %v", conf.Module, err)
+	}
+	defer func() { _ = ctx.Finalize() }()
+
+	slots, err := ctx.GetSlotList(false)
+	if err != nil || len(slots) == 0 {
+		t.Fatalf("failed to list slots: %v", err)
+	}
+	soPin := ""
+	if len(softhsm) > 0 {
+		soPin = testPKCS11Pin
+		if err = ctx.InitToken(slots[0], soPin, conf.Token); err != nil {
+			t.Fatalf("failed to init token: %v", err)
+		}
+	}
+
+	k := &PKCS11Key{ctx: ctx}
+	slot, err := k.findSlot(conf.Token)
+	if err != nil {
+		t.Fatalf("findSlot() error = %v", err)
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatalf("failed to open session: %v", err)
+	}
+	defer func() { _ = ctx.CloseSession(session) }()
+
+	if err = ctx.Login(session, pkcs11.CKU_SO, soPin); err != nil {
+		t.Fatalf("failed to log in as SO: %v", err)
+	}
+	if err = ctx.InitPIN(session, testPKCS11Pin); err != nil {
+		t.Fatalf("failed to init user PIN: %v", err)
+	}
+	if err = ctx.Logout(session); err != nil {
+		t.Fatalf("failed to log out: %v", err)
+	}
+	if err = ctx.Login(session, pkcs11.CKU_USER, testPKCS11Pin); err != nil {
+		t.Fatalf("failed to log in: %v", err)
+	}
+	gen(ctx, session)
+	_ = ctx.Logout(session)
+	return conf
+}
+
+func testPKCS11GenerateKey(t *testing.T, ctx *pkcs11.Ctx, session pkcs11.SessionHandle, label string, curve asn1.ObjectIdentifier) {
+	t.Helper()
+
+	mech := pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)
+	pub := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(label)),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+	}
+	if curve != nil {
+		params, err := asn1.Marshal(curve)
+		if err != nil {
+			t.Fatalf("failed to encode curve: %v", err)
+		}
+		mech = pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)
+		pub = append(pub, pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params))
+	} else {
+		pub = append(pub,
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, 2048),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}))
+	}
+	priv := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(label)),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+	}
+	if _, _, err := ctx.GenerateKeyPair(session, []*pkcs11.Mechanism{mech}, pub, priv); err != nil {
+		t.Fatalf("failed to generate %s key: %v", label, err)
+	}
+}
+
+func TestPKCS11Key(t *testing.T) {
+	base := testPKCS11Token(t, func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+		testPKCS11GenerateKey(t, ctx, session, "ec256", oidNamedCurveP256)
+		testPKCS11GenerateKey(t, ctx, session, "ec384", oidNamedCurveP384)
+		testPKCS11GenerateKey(t, ctx, session, "rsa", nil)
+	})
+
+	open := func(label, pin string) (*PKCS11Key, error) {
+		conf := base
+		conf.KeyLabel, conf.Pin = label, pin
+		return OpenPKCS11Key(conf)
+	}
+
+	t.Run("wrong pin", func(t *testing.T) {
+		if k, err := open("ec256", "0000"); err == nil {
+			_ = k.Close()
+			t.Fatalf("OpenPKCS11Key() accepted a wrong PIN")
+		}
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		if k, err := open("missing", testPKCS11Pin); err == nil {
+			_ = k.Close()
+			t.Fatalf("OpenPKCS11Key() found a missing key")
+		}
+	})
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "signer.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	keys := map[string]crypto.Signer{}
+	srv := &SignerServer{Keys: keys, OnError: func(err error) { t.Logf("signer server: %v", err) }}
+
+	tests := []struct {
+		name  string
+		label string
+		algs  []x509.SignatureAlgorithm
+	}{
+		{name: "ecdsa p256", label: "ec256", algs: []x509.SignatureAlgorithm{x509.ECDSAWithSHA256}},
+		{name: "ecdsa p384", label: "ec384", algs: []x509.SignatureAlgorithm{x509.ECDSAWithSHA384}},
+		{name: "rsa", label: "rsa", algs: []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.SHA384WithRSAPSS}},
+	}
+	cas := map[string]*x509.Certificate{}
+	for _, tt := range tests {
+		key, err := open(tt.label, testPKCS11Pin)
+		if err != nil {
+			t.Fatalf("OpenPKCS11Key(%s) error = %v", tt.label, err)
+		}
+		// only the first key initialized the module, deferred last it finalizes the module
+		defer func() { _ = key.Close() }()
+		keys[tt.label] = key
+
+		// the CA certificate is self-signed on the token directly
+		tpl := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: "CA " + tt.name},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+		if err != nil {
+			t.Fatalf("failed to self-sign %s: %v", tt.label, err)
+		}
+		if cas[tt.label], err = x509.ParseCertificate(der); err != nil {
+			t.Fatalf("failed to parse certificate: %v", err)
+		}
+		if err = cas[tt.label].CheckSignatureFrom(cas[tt.label]); err != nil {
+			t.Fatalf("CheckSignatureFrom() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = srv.Serve(ctx, l) }()
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	for _, tt := range tests {
+		for _, alg := range tt.algs {
+			t.Run(tt.name+" "+alg.String(), func(t *testing.T) {
+				ca := cas[tt.label]
+				signer, err := NewSigner(IssuerConfig{Signer: SignerConfig{Type: SignerSocket, Socket: socket, KeyID: tt.label}}, ca)
+				if err != nil {
+					t.Fatalf("NewSigner() error = %v", err)
+				}
+
+				tpl := &x509.Certificate{
+					SerialNumber:       big.NewInt(1),
+					Subject:            pkix.Name{CommonName: "leaf"},
+					NotBefore:          time.Now(),
+					NotAfter:           time.Now().Add(time.Hour),
+					SignatureAlgorithm: alg,
+				}
+				der, err := x509.CreateCertificate(rand.Reader, tpl, ca, leafKey.Public(), signer)
+				if err != nil {
+					t.Fatalf("failed to sign certificate: %v", err)
+				}
+				leaf, err := x509.ParseCertificate(der)
+				if err != nil {
+					t.Fatalf("failed to parse certificate: %v", err)
+				}
+				if err = leaf.CheckSignatureFrom(ca); err != nil {
+					t.Fatalf("CheckSignatureFrom() error = %v", err)
+				}
+			})
+		}
+	}
+
+	t.Run("pss salt lengths", func(t *testing.T) {
+		key := keys["rsa"]
+		digest := make([]byte, crypto.SHA256.Size())
+		for _, salt := range []int{rsa.PSSSaltLengthAuto, rsa.PSSSaltLengthEqualsHash, 20} {
+			opts := &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: salt}
+			sig, err := key.Sign(rand.Reader, digest, opts)
+			if err != nil {
+				t.Fatalf("Sign(salt %d) error = %v", salt, err)
+			}
+			if err = rsa.VerifyPSS(key.Public().(*rsa.PublicKey), crypto.SHA256, digest, sig, opts); err != nil {
+				t.Fatalf("VerifyPSS(salt %d) error = %v", salt, err)
+			}
+		}
+		if _, err := key.Sign(rand.Reader, digest[:10], crypto.SHA256); err == nil {
+			t.Fatalf("Sign() accepted a digest of wrong size")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// DefaultProfileName is the profile of requests without one, it issues the leaves casper
+// issued before profiles: server and client auth, valid for default_expire_days of the CA.
+const DefaultProfileName = "default"
+
+type ProfileConfig struct {
+	Name            string        `yaml:"name"`
+	KeyUsage        []string      `yaml:"key_usage"`
+	ExtKeyUsage     []string      `yaml:"ext_key_usage"`
+	MaxValidityDays int           `yaml:"max_validity_days"`
+	Backdate        time.Duration `yaml:"backdate"`
+	MustStaple      bool          `yaml:"must_staple"`
+}
+
+var _keyUsage = map[string]x509.KeyUsage{
+	"digital_signature":  x509.KeyUsageDigitalSignature,
+	"content_commitment": x509.KeyUsageContentCommitment,
+	"key_encipherment":   x509.KeyUsageKeyEncipherment,
+	"data_encipherment":  x509.KeyUsageDataEncipherment,
+	"key_agreement":      x509.KeyUsageKeyAgreement,
+}
+
+var _extKeyUsage = map[string]x509.ExtKeyUsage{
+	"server_auth":      x509.ExtKeyUsageServerAuth,
+	"client_auth":      x509.ExtKeyUsageClientAuth,
+	"code_signing":     x509.ExtKeyUsageCodeSigning,
+	"email_protection": x509.ExtKeyUsageEmailProtection,
+	"time_stamping":    x509.ExtKeyUsageTimeStamping,
+}
+
+// Profile is a leaf certificate template selected by name in the issuance request.
+type Profile struct {
+	Name        string
+	KeyUsage    x509.KeyUsage
+	ExtKeyUsage []x509.ExtKeyUsage
+	MaxValidity time.Duration
+	Backdate    time.Duration
+	MustStaple  bool
+}
+
+func newProfile(c ProfileConfig) (*Profile, error) {
+	if len(c.Name) == 0 {
+		return nil, fmt.Errorf("profile name is empty")
+	}
+	if c.MaxValidityDays < 0 || c.Backdate < 0 {
+		return nil, fmt.Errorf("profile %q: negative validity or backdate", c.Name)
+	}
+
+	p := &Profile{
+		Name:        c.Name,
+		MaxValidity: time.Duration(c.MaxValidityDays) * time.Hour * 24,
+		Backdate:    c.Backdate,
+		MustStaple:  c.MustStaple,
+	}
+
+	for _, name := range c.KeyUsage {
+		ku, ok := _keyUsage[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %q: unknown key usage %q", c.Name, name)
+		}
+		p.KeyUsage |= ku
+	}
+
+	for _, name := range c.ExtKeyUsage {
+		eku, ok := _extKeyUsage[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %q: unknown ext key usage %q", c.Name, name)
+		}
+		p.ExtKeyUsage = append(p.ExtKeyUsage, eku)
+	}
+
+	if p.KeyUsage == 0 || len(p.ExtKeyUsage) == 0 {
+		return nil, fmt.Errorf("profile %q: key usage and ext key usage are required", c.Name)
+	}
+
+	return p, nil
+}
+
+// Validity returns the certificate lifetime limited by the CA default and the profile maximum.
+func (p *Profile) Validity(ca *Certificate) time.Duration {
+	d := time.Duration(ca.Days) * time.Hour * 24
+	if p.MaxValidity > 0 && (d <= 0 || p.MaxValidity < d) {
+		d = p.MaxValidity
+	}
+	return d
+}
+
+// defaultProfiles keeps the issued leaves unchanged for configs without cert_profiles,
+// the narrower profiles are used only when an account requests them.
+func defaultProfiles() []ProfileConfig {
+	return []ProfileConfig{
+		{
+			Name:        DefaultProfileName,
+			KeyUsage:    []string{"digital_signature", "key_encipherment"},
+			ExtKeyUsage: []string{"server_auth", "client_auth"},
+		},
+		{
+			Name:            "tls-server",
+			KeyUsage:        []string{"digital_signature", "key_encipherment"},
+			ExtKeyUsage:     []string{"server_auth"},
+			MaxValidityDays: 90,
+			Backdate:        time.Minute * 5,
+		},
+		{
+			Name:            "tls-client",
+			KeyUsage:        []string{"digital_signature"},
+			ExtKeyUsage:     []string{"client_auth"},
+			MaxValidityDays: 90,
+			Backdate:        time.Minute * 5,
+		},
+		{
+			Name:            "mtls-both",
+			KeyUsage:        []string{"digital_signature", "key_encipherment"},
+			ExtKeyUsage:     []string{"server_auth", "client_auth"},
+			MaxValidityDays: 90,
+			Backdate:        time.Minute * 5,
+		},
+		{
+			Name:            "code-signing",
+			KeyUsage:        []string{"digital_signature"},
+			ExtKeyUsage:     []string{"code_signing"},
+			MaxValidityDays: 365,
+			Backdate:        time.Minute * 5,
+		},
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDefaultProfileLeaf(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(testConfigGroup(testGroup(t, dir, "CA Example", "example.com")))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	ca, _, ok := store.Match("example.com")
+	if !ok {
+		t.Fatalf("Match() found no CA")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"www.example.com"}}, key)
+	if err != nil {
+		t.Fatalf("failed to create csr: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to parse csr: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		profile  string
+		wantEKU  []x509.ExtKeyUsage
+		wantKU   x509.KeyUsage
+		wantDays int
+	}{
+		{name: "default keeps the leaves of casper without profiles", profile: store.DefaultProfile(),
+			wantEKU:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			wantKU:   x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			wantDays: ca.Days},
+		{name: "tls-server is opt-in and server only", profile: "tls-server",
+			wantEKU:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			wantKU:   x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			wantDays: ca.Days},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, ok := store.Profile(tt.profile)
+			if !ok {
+				t.Fatalf("Profile(%q) not found", tt.profile)
+			}
+			crt, err := SignCSR(ca, profile, csr, big.NewInt(time.Now().UnixNano()))
+			if err != nil {
+				t.Fatalf("SignCSR() error = %v", err)
+			}
+			if !slices.Equal(crt.ExtKeyUsage, tt.wantEKU) {
+				t.Fatalf("ExtKeyUsage = %v, want %v", crt.ExtKeyUsage, tt.wantEKU)
+			}
+			if crt.KeyUsage != tt.wantKU {
+				t.Fatalf("KeyUsage = %v, want %v", crt.KeyUsage, tt.wantKU)
+			}
+			lifetime := crt.NotAfter.Sub(crt.NotBefore) - profile.Backdate
+			if want := time.Duration(tt.wantDays) * 24 * time.Hour; lifetime != want {
+				t.Fatalf("lifetime = %s, want %s", lifetime, want)
+			}
+		})
+	}
+
+	if store.DefaultProfile() != DefaultProfileName {
+		t.Fatalf("DefaultProfile() = %q, want %q", store.DefaultProfile(), DefaultProfileName)
+	}
+}
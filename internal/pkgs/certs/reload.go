@@ -0,0 +1,78 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.osspkg.com/ioutils/codec"
+)
+
+// ReloadConfig controls the hot reload of the CA set, SIGHUP always triggers it,
+// Watch also polls the config and every file it references for changes.
+type ReloadConfig struct {
+	Watch    bool          `yaml:"watch"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// LoadConfigGroup reads the certs section of the server config file.
+func LoadConfigGroup(path string) (*ConfigGroup, error) {
+	c := &ConfigGroup{}
+	if err := codec.FileEncoder(path).Decode(c); err != nil {
+		return nil, fmt.Errorf("failed to decode config %q: %w", path, err)
+	}
+	if len(c.Certs) == 0 {
+		return nil, fmt.Errorf("no certs in config %q", path)
+	}
+	c.Default()
+	return c, nil
+}
+
+// ServerConfigFile returns the value of the --config flag the server was started with.
+func ServerConfigFile() (string, bool) {
+	args := os.Args[1:]
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value, len(value) > 0
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// FilesVersion summarizes size and modification time of the config file and of the
+// certificates, keys and passphrase files it references, any change alters the result.
+func FilesVersion(path string, c *ConfigGroup) string {
+	files := []string{path}
+	for _, conf := range c.Certs {
+		files = append(files, conf.RootCaChain...)
+		for _, issuer := range conf.IssuerList() {
+			files = append(files, issuer.Cert, issuer.Key, issuer.KeyPassphrase.File)
+		}
+	}
+
+	var sb strings.Builder
+	for _, file := range files {
+		if len(file) == 0 {
+			continue
+		}
+		if fi, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&sb, "%s:%d:%d;", file, fi.Size(), fi.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&sb, "%s:-;", file)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	oidExtCertificatePolicies = asn1.ObjectIdentifier{2, 5, 29, 32}
+	oidAnyPolicy              = asn1.ObjectIdentifier{2, 5, 29, 32, 0}
+	oidQualifierCPS           = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}
+	oidExtTLSFeature          = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+	oidExtFreshestCRL         = asn1.ObjectIdentifier{2, 5, 29, 46}
+
+	// RFC 7633: TLS feature status_request (5)
+	mustStapleValue = []byte{0x30, 0x03, 0x02, 0x01, 0x05}
+)
+
+type policyQualifier struct {
+	ID  asn1.ObjectIdentifier
+	CPS string `asn1:"ia5"`
+}
+
+type policyInformation struct {
+	ID         asn1.ObjectIdentifier
+	Qualifiers []policyQualifier `asn1:"optional"`
+}
+
+// distributionPoint is the CRLDistributionPoints syntax (RFC 5280 4.2.1.13) with full names only.
+type distributionPoint struct {
+	DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+}
+
+type distributionPointName struct {
+	FullName []asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// SignCSR issues a leaf certificate for the CSR using the CA URLs and the profile extensions.
+// A CSR the issuer must not sign is rejected with *PolicyError.
+func SignCSR(ca *Certificate, profile *Profile, csr *x509.CertificateRequest, serial *big.Int) (*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid csr signature: %w", err)
+	}
+
+	now := time.Now()
+	if err := ca.checkPolicy(csr, profile, now); err != nil {
+		return nil, &PolicyError{Err: err}
+	}
+
+	ski, err := subjectKeyId(csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               csr.Subject,
+		DNSNames:              csr.DNSNames,
+		URIs:                  csr.URIs,
+		EmailAddresses:        csr.EmailAddresses,
+		NotBefore:             now.Add(-profile.Backdate),
+		NotAfter:              now.Add(profile.Validity(ca)),
+		KeyUsage:              profile.KeyUsage,
+		ExtKeyUsage:           profile.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		SubjectKeyId:          ski,
+		IssuingCertificateURL: ca.ICUs,
+		OCSPServer:            ca.OCSPs,
+		CRLDistributionPoints: ca.CRLs,
+	}
+
+	if len(ca.CPSs) > 0 {
+		ext, err := certificatePolicies(ca.CPSs)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, ext)
+	}
+
+	if len(ca.DeltaCRLs) > 0 {
+		ext, err := freshestCRL(ca.DeltaCRLs)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, ext)
+	}
+
+	if profile.MustStaple {
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: oidExtTLSFeature, Value: mustStapleValue})
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Issuer.Crt, csr.PublicKey, ca.Issuer.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// certificatePolicies encodes anyPolicy with the CPS URIs as qualifiers.
+func certificatePolicies(cps []string) (pkix.Extension, error) {
+	policy := policyInformation{ID: oidAnyPolicy}
+	for _, uri := range cps {
+		policy.Qualifiers = append(policy.Qualifiers, policyQualifier{ID: oidQualifierCPS, CPS: uri})
+	}
+	b, err := asn1.Marshal([]policyInformation{policy})
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode certificate policies: %w", err)
+	}
+	return pkix.Extension{Id: oidExtCertificatePolicies, Value: b}, nil
+}
+
+// freshestCRL points to the delta CRLs (RFC 5280 4.2.1.15), the syntax is the one of CRL distribution points.
+func freshestCRL(urls []string) (pkix.Extension, error) {
+	points := make([]distributionPoint, 0, len(urls))
+	for _, uri := range urls {
+		points = append(points, distributionPoint{
+			DistributionPoint: distributionPointName{
+				FullName: []asn1.RawValue{{Tag: 6, Class: asn1.ClassContextSpecific, Bytes: []byte(uri)}},
+			},
+		})
+	}
+	b, err := asn1.Marshal(points)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode freshest crl: %w", err)
+	}
+	return pkix.Extension{Id: oidExtFreshestCRL, Value: b}, nil
+}
+
+func subjectKeyId(pub any) ([]byte, error) {
+	b, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err = asn1.Unmarshal(b, &spki); err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	sum := sha1.Sum(spki.PublicKey.Bytes)
+	return sum[:], nil
+}
@@ -0,0 +1,326 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	SignerFile   = "file"
+	SignerSocket = "socket"
+
+	signerOpPublic = "public"
+	signerOpSign   = "sign"
+
+	signerDefaultTimeout = 10 * time.Second
+	// ed25519 signs the whole message, so a request may carry a large CRL
+	signerMaxMessageSize = 16 << 20
+)
+
+// SignerConfig selects where the issuing CA key lives. The file backend loads the key
+// into memory, the socket backend sends digests to an external signing process
+// (casper-cli signer, HSM or KMS agent) over a unix socket and never sees the key.
+// A PKCS#11 token is reached through the socket backend as well: casper-cli signer
+// opens the token with PKCS11Config and serves its key, so the server needs no cgo.
+type SignerConfig struct {
+	Type    string        `yaml:"type"`
+	Socket  string        `yaml:"socket,omitempty"`
+	KeyID   string        `yaml:"key_id,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// PKCS11Config locates a private key on a PKCS#11 token. ModuleArgs is passed to
+// C_Initialize as the library parameters, NSS softokn needs it for its database directory.
+type PKCS11Config struct {
+	Module     string
+	ModuleArgs string
+	Token      string
+	KeyLabel   string
+	Pin        string
+}
+
+// Signer signs certificates, CRLs and OCSP responses on behalf of the issuing CA.
+type Signer interface {
+	crypto.Signer
+	Backend() string
+}
+
+// NewSigner opens the key backend of the CA and checks that it matches the CA certificate.
+func NewSigner(conf IssuerConfig, crt *x509.Certificate) (Signer, error) {
+	var (
+		s   Signer
+		err error
+	)
+
+	switch conf.Signer.Type {
+	case "", SignerFile:
+		s, err = newFileSigner(conf.Key, conf.KeyPassphrase)
+	case SignerSocket:
+		s, err = newSocketSigner(conf.Signer)
+	default:
+		return nil, fmt.Errorf("unknown signer type %q", conf.Signer.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := s.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(crt.PublicKey) {
+		return nil, fmt.Errorf("%s signer key does not match the CA certificate", s.Backend())
+	}
+	return s, nil
+}
+
+type fileSigner struct {
+	crypto.Signer
+}
+
+func newFileSigner(path string, pass Passphrase) (*fileSigner, error) {
+	key, err := LoadPrivateKey(path, pass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key %q: %w", path, err)
+	}
+	return &fileSigner{Signer: key}, nil
+}
+
+func (v *fileSigner) Backend() string { return SignerFile }
+
+// Decrypt forwards to the key, SCEP clients encrypt requests to the CA certificate.
+func (v *fileSigner) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	dec, ok := v.Signer.(crypto.Decrypter)
+	if !ok {
+		return nil, fmt.Errorf("%s signer key %T does not support decryption", v.Backend(), v.Signer)
+	}
+	return dec.Decrypt(rand, msg, opts)
+}
+
+// CanDecrypt reports whether the CA key opens messages encrypted to the CA certificate:
+// RSA keys of the file backend do, the socket backend only signs.
+func CanDecrypt(key crypto.Signer) bool {
+	if v, ok := key.(*fileSigner); ok {
+		key = v.Signer
+	}
+	_, ok := key.(crypto.Decrypter)
+	return ok
+}
+
+// signerMessage is a single line of the socket protocol, requests carry Op,
+// responses carry the result or Error. Binary fields are base64 encoded by json.
+//
+//	{"op":"public","key_id":"ca"}                                  -> {"public_key":"<DER SPKI>"}
+//	{"op":"sign","key_id":"ca","hash":"SHA-256","digest":"..."}   -> {"signature":"..."}
+//
+// PSSSaltLength is set for RSA-PSS only, -1 means the salt length equals the hash size.
+type signerMessage struct {
+	Op            string `json:"op,omitempty"`
+	KeyID         string `json:"key_id,omitempty"`
+	Hash          string `json:"hash,omitempty"`
+	Digest        []byte `json:"digest,omitempty"`
+	PSSSaltLength *int   `json:"pss_salt_length,omitempty"`
+	PublicKey     []byte `json:"public_key,omitempty"`
+	Signature     []byte `json:"signature,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+type socketSigner struct {
+	conf SignerConfig
+	pub  crypto.PublicKey
+}
+
+func newSocketSigner(conf SignerConfig) (*socketSigner, error) {
+	if len(conf.Socket) == 0 {
+		return nil, fmt.Errorf("socket signer requires a socket path")
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = signerDefaultTimeout
+	}
+
+	v := &socketSigner{conf: conf}
+	resp, err := v.call(signerMessage{Op: signerOpPublic, KeyID: conf.KeyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key from %s: %w", conf.Socket, err)
+	}
+	if v.pub, err = x509.ParsePKIXPublicKey(resp.PublicKey); err != nil {
+		return nil, fmt.Errorf("failed to decode public key from %s: %w", conf.Socket, err)
+	}
+	return v, nil
+}
+
+func (v *socketSigner) Backend() string { return SignerSocket }
+
+func (v *socketSigner) Public() crypto.PublicKey { return v.pub }
+
+func (v *socketSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := signerMessage{
+		Op:     signerOpSign,
+		KeyID:  v.conf.KeyID,
+		Hash:   signerHashName(opts.HashFunc()),
+		Digest: digest,
+	}
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		salt := pss.SaltLength
+		req.PSSSaltLength = &salt
+	}
+
+	resp, err := v.call(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with %s: %w", v.conf.Socket, err)
+	}
+	return resp.Signature, nil
+}
+
+func (v *socketSigner) call(req signerMessage) (*signerMessage, error) {
+	conn, err := net.DialTimeout("unix", v.conf.Socket, v.conf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(v.conf.Timeout)); err != nil {
+		return nil, err
+	}
+	if err = json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp signerMessage
+	if err = readSignerMessage(conn, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Error) > 0 {
+		return nil, fmt.Errorf("signer error: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+func readSignerMessage(r io.Reader, msg *signerMessage) error {
+	return json.NewDecoder(io.LimitReader(r, signerMaxMessageSize)).Decode(msg)
+}
+
+// SignerServer is the other side of the socket backend, it keeps the keys in a
+// separate process so that casper itself never loads them.
+type SignerServer struct {
+	Keys    map[string]crypto.Signer
+	OnError func(err error)
+}
+
+func (s *SignerServer) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *SignerServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(signerDefaultTimeout)); err != nil {
+		s.onError(err)
+		return
+	}
+
+	var req signerMessage
+	resp := signerMessage{}
+	if err := readSignerMessage(conn, &req); err != nil {
+		resp.Error = fmt.Sprintf("failed to decode request: %s", err)
+	} else if err = s.process(&req, &resp); err != nil {
+		resp.Error = err.Error()
+	}
+	if len(resp.Error) > 0 {
+		s.onError(fmt.Errorf("signer request %q: %s", req.Op, resp.Error))
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		s.onError(err)
+	}
+}
+
+func (s *SignerServer) process(req, resp *signerMessage) error {
+	key, ok := s.Keys[req.KeyID]
+	if !ok {
+		return fmt.Errorf("unknown key id %q", req.KeyID)
+	}
+
+	switch req.Op {
+	case signerOpPublic:
+		b, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			return fmt.Errorf("failed to encode public key: %w", err)
+		}
+		resp.PublicKey = b
+		return nil
+
+	case signerOpSign:
+		h, ok := signerHashes[req.Hash]
+		if !ok {
+			return fmt.Errorf("unsupported hash %q", req.Hash)
+		}
+		if h != 0 && len(req.Digest) != h.Size() {
+			return fmt.Errorf("invalid digest size %d for %s", len(req.Digest), req.Hash)
+		}
+
+		var opts crypto.SignerOpts = h
+		if req.PSSSaltLength != nil {
+			opts = &rsa.PSSOptions{SaltLength: *req.PSSSaltLength, Hash: h}
+		}
+
+		sig, err := key.Sign(rand.Reader, req.Digest, opts)
+		if err != nil {
+			return fmt.Errorf("failed to sign: %w", err)
+		}
+		resp.Signature = sig
+		return nil
+
+	default:
+		return fmt.Errorf("unknown operation %q", req.Op)
+	}
+}
+
+func (s *SignerServer) onError(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+const signerHashNone = "none"
+
+// signerHashes lists the hashes accepted by the socket protocol, ed25519 signs
+// the message itself and uses none.
+var signerHashes = map[string]crypto.Hash{
+	crypto.SHA1.String():   crypto.SHA1,
+	crypto.SHA256.String(): crypto.SHA256,
+	crypto.SHA384.String(): crypto.SHA384,
+	crypto.SHA512.String(): crypto.SHA512,
+	signerHashNone:         0,
+}
+
+func signerHashName(h crypto.Hash) string {
+	if h == 0 {
+		return signerHashNone
+	}
+	return h.String()
+}
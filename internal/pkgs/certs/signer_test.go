@@ -0,0 +1,182 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadTestCert(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read certificate: %v", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		t.Fatalf("no pem block in %s", path)
+	}
+	crt, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return crt
+}
+
+func TestFileSignerDecrypt(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	dir := t.TempDir()
+	open := func(name string, key crypto.Signer) Signer {
+		conf := testCA(t, dir, name, key)
+		ca := loadTestCert(t, conf.Cert)
+		s, err := NewSigner(conf, ca)
+		if err != nil {
+			t.Fatalf("NewSigner() error = %v", err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		key  crypto.Signer
+		want bool
+	}{
+		{name: "rsa file signer", key: open("rsa", rsaKey), want: true},
+		{name: "ec file signer", key: open("ec", ecKey)},
+		{name: "signer without decrypt", key: &socketSigner{pub: rsaKey.Public()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanDecrypt(tt.key); got != tt.want {
+				t.Fatalf("CanDecrypt() = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+
+			msg := []byte("content encryption key")
+			enc, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &rsaKey.PublicKey, msg, nil)
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+			dec, ok := tt.key.(crypto.Decrypter)
+			if !ok {
+				t.Fatalf("%T does not implement crypto.Decrypter", tt.key)
+			}
+			got, err := dec.Decrypt(rand.Reader, enc, &rsa.OAEPOptions{Hash: crypto.SHA256})
+			if err != nil || string(got) != string(msg) {
+				t.Fatalf("Decrypt() = %q, %v, want %q", got, err, msg)
+			}
+		})
+	}
+}
+
+func TestSocketSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "signer.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := &SignerServer{
+		Keys:    map[string]crypto.Signer{"rsa": rsaKey, "ec": ecKey, "ed": edKey},
+		OnError: func(err error) { t.Logf("signer server: %v", err) },
+	}
+	go func() { _ = srv.Serve(ctx, l) }()
+
+	tests := []struct {
+		name    string
+		keyID   string
+		key     crypto.Signer
+		alg     x509.SignatureAlgorithm
+		wantErr bool
+	}{
+		{name: "rsa pkcs1", keyID: "rsa", key: rsaKey, alg: x509.SHA256WithRSA},
+		{name: "rsa pss", keyID: "rsa", key: rsaKey, alg: x509.SHA384WithRSAPSS},
+		{name: "ecdsa", keyID: "ec", key: ecKey, alg: x509.ECDSAWithSHA256},
+		{name: "ed25519", keyID: "ed", key: edKey, alg: x509.PureEd25519},
+		{name: "key of other CA", keyID: "ec", key: rsaKey, wantErr: true},
+		{name: "unknown key id", keyID: "missing", key: rsaKey, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := testCA(t, dir, tt.name, tt.key)
+			ca := loadTestCert(t, conf.Cert)
+			conf.Signer = SignerConfig{Type: SignerSocket, Socket: socket, KeyID: tt.keyID}
+
+			signer, err := NewSigner(conf, ca)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if CanDecrypt(signer) {
+				t.Fatalf("CanDecrypt() = true, the socket signer only signs")
+			}
+
+			tpl := &x509.Certificate{
+				SerialNumber:       big.NewInt(1),
+				Subject:            pkix.Name{CommonName: "leaf"},
+				NotBefore:          time.Now(),
+				NotAfter:           time.Now().Add(time.Hour),
+				SignatureAlgorithm: tt.alg,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, tpl, ca, ecKey.Public(), signer)
+			if err != nil {
+				t.Fatalf("failed to sign certificate: %v", err)
+			}
+			leaf, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("failed to parse certificate: %v", err)
+			}
+			if err = leaf.CheckSignatureFrom(ca); err != nil {
+				t.Fatalf("CheckSignatureFrom() error = %v", err)
+			}
+		})
+	}
+}
@@ -6,21 +6,72 @@
 package certs
 
 import (
+	"bytes"
 	"crypto/x509"
 	"encoding/hex"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.osspkg.com/encrypt/pki"
 )
 
 type Certificate struct {
-	Chain  map[string]*x509.Certificate
-	Issuer *pki.Certificate
-	Days   int
-	ICUs   []string
-	OCSPs  []string
-	CRLs   []string
-	CPSs   []string
+	Chain     map[string]*x509.Certificate
+	Issuer    *pki.Certificate
+	State     string
+	Days      int
+	ICUs      []string
+	OCSPs     []string
+	CRLs      []string
+	DeltaCRLs []string
+	CPSs      []string
+	Wildcard  Wildcard
+	OCSP      OCSPWindow
+}
+
+// ChainCerts returns the issuing CA followed by its parents up to the root,
+// the chain is walked by the authority key id.
+func (v *Certificate) ChainCerts() []*x509.Certificate {
+	result := make([]*x509.Certificate, 0, len(v.Chain)+1)
+	result = append(result, v.Issuer.Crt)
+
+	cur := v.Issuer.Crt
+	for i := 0; i < len(v.Chain); i++ {
+		if len(cur.AuthorityKeyId) == 0 || bytes.Equal(cur.AuthorityKeyId, cur.SubjectKeyId) {
+			break
+		}
+		next, ok := v.GetBySubjectKeyId(cur.AuthorityKeyId)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		cur = next
+	}
+
+	return result
+}
+
+// IsActive reports whether new certificates may be issued by the CA.
+func (v *Certificate) IsActive() bool {
+	return v.State == IssuerActive
+}
+
+// CheckWildcard validates the wildcard name against the CA policy, plain names are always accepted.
+func (v *Certificate) CheckWildcard(name string) error {
+	if !IsWildcard(name) {
+		return nil
+	}
+	if !v.Wildcard.Allow {
+		return fmt.Errorf("wildcard is not allowed: %s", name)
+	}
+	level := strings.Count(name, ".") + 1
+	if level < v.Wildcard.MinLevel || (v.Wildcard.MaxLevel > 0 && level > v.Wildcard.MaxLevel) {
+		return fmt.Errorf("wildcard level %d is not allowed: %s", level, name)
+	}
+	return nil
 }
 
 func (v *Certificate) GetBySubjectKeyId(ski []byte) (*x509.Certificate, bool) {
@@ -31,29 +82,89 @@ func (v *Certificate) GetBySubjectKeyId(ski []byte) (*x509.Certificate, bool) {
 	return c, true
 }
 
+// Store serves the CA set of the last successfully loaded config,
+// Reload swaps it atomically so requests in flight keep their snapshot.
 type Store struct {
-	domains map[string]*Certificate
-	list    []*Certificate
+	state atomic.Pointer[storeState]
+	mux   sync.Mutex
+}
+
+type storeState struct {
+	domains        map[string]*Certificate
+	trustDomains   map[string]*Certificate
+	list           []*Certificate
+	profiles       map[string]*Profile
+	defaultProfile string
+	conf           *ConfigGroup
+	// signers are reused by the next reload while the key source and the CA key stay the same
+	signers map[signerSource]Signer
+}
+
+// signerSource identifies an opened CA key: its config and the public key of the CA certificate.
+type signerSource struct {
+	key    string
+	pass   Passphrase
+	signer SignerConfig
+	spki   string
 }
 
 func NewStore(c *ConfigGroup) (*Store, error) {
-	obj := &Store{
-		domains: make(map[string]*Certificate),
-		list:    make([]*Certificate, 0, len(c.Certs)),
+	state, err := newStoreState(c, nil)
+	if err != nil {
+		return nil, err
+	}
+	obj := &Store{}
+	obj.state.Store(state)
+	return obj, nil
+}
+
+// Reload validates the config and replaces the CA set, the current set is kept on error.
+// Keys of unchanged issuers are not opened again, a new key can not be unlocked by a prompt.
+func (s *Store) Reload(c *ConfigGroup) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	state, err := newStoreState(c, s.state.Load())
+	if err != nil {
+		return err
 	}
+	s.state.Store(state)
+	return nil
+}
 
-	for _, conf := range c.Certs {
+// Config returns the config of the current CA set.
+func (s *Store) Config() *ConfigGroup {
+	return s.state.Load().conf
+}
 
-		cert := &Certificate{
-			Chain:  make(map[string]*x509.Certificate),
-			Issuer: &pki.Certificate{},
-			Days:   conf.DefaultExpireDays,
-			ICUs:   conf.IssuingCertificateURLs,
-			OCSPs:  conf.OCSPServerURLs,
-			CRLs:   conf.CRLDistributionPointURLs,
-			CPSs:   conf.CertificatePoliciesURLs,
+// newStoreState loads the CA set, prev is the current set on reload and nil on start.
+func newStoreState(c *ConfigGroup, prev *storeState) (*storeState, error) {
+	obj := &storeState{
+		domains:        make(map[string]*Certificate),
+		trustDomains:   make(map[string]*Certificate),
+		list:           make([]*Certificate, 0, len(c.Certs)),
+		profiles:       make(map[string]*Profile, len(c.Profiles)),
+		defaultProfile: c.DefaultProfile,
+		conf:           c,
+		signers:        make(map[signerSource]Signer),
+	}
+
+	for _, conf := range c.Profiles {
+		p, err := newProfile(conf)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj.profiles[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate profile %q", p.Name)
 		}
+		obj.profiles[p.Name] = p
+	}
+	if _, ok := obj.profiles[obj.defaultProfile]; !ok {
+		return nil, fmt.Errorf("default profile %q is not configured", obj.defaultProfile)
+	}
 
+	for _, conf := range c.Certs {
+		chain := make(map[string]*x509.Certificate)
 		for _, path := range conf.RootCaChain {
 			ca := &pki.Certificate{}
 			if err := ca.LoadCert(path); err != nil {
@@ -62,34 +173,148 @@ func NewStore(c *ConfigGroup) (*Store, error) {
 			if !ca.IsCA() {
 				return nil, fmt.Errorf("file %q is not a CA", path)
 			}
-			cert.Chain[hex.EncodeToString(ca.Crt.SubjectKeyId)] = ca.Crt
+			chain[hex.EncodeToString(ca.Crt.SubjectKeyId)] = ca.Crt
 		}
 
-		if err := cert.Issuer.LoadCert(conf.IssuingCACert); err != nil {
-			return nil, fmt.Errorf("decode cert %q: %w", conf.IssuingCACert, err)
-		}
-		if err := cert.Issuer.LoadKey(conf.IssuingCAKey); err != nil {
-			return nil, fmt.Errorf("decode key %q: %w", conf.IssuingCAKey, err)
-		}
+		var active *Certificate
+		for _, ic := range conf.IssuerList() {
+			switch ic.State {
+			case IssuerRetired:
+				continue
+			case IssuerActive, IssuerRetiring:
+			default:
+				return nil, fmt.Errorf("issuer %q has unknown state %q", ic.Cert, ic.State)
+			}
+
+			cert, err := newIssuer(conf, ic, chain, prev, obj.signers)
+			if err != nil {
+				return nil, err
+			}
 
-		if !cert.Issuer.IsCA() {
-			return nil, fmt.Errorf("file %v is not a CA", []string{conf.IssuingCACert, conf.IssuingCAKey})
+			if ic.State == IssuerActive {
+				if active != nil {
+					return nil, fmt.Errorf("issuers %q and %q are both active",
+						active.Issuer.Crt.Subject.String(), cert.Issuer.Crt.Subject.String())
+				}
+				active = cert
+			}
+			obj.list = append(obj.list, cert)
 		}
-		if !cert.Issuer.IsValidPair() {
-			return nil, fmt.Errorf("file %v is not a valid pair", []string{conf.IssuingCACert, conf.IssuingCAKey})
+		if active == nil {
+			return nil, fmt.Errorf("no active issuer for domains %v", conf.Domains)
 		}
 
-		obj.list = append(obj.list, cert)
 		for _, domain := range conf.Domains {
-			obj.domains[domain] = cert
+			domain = normalizeDomain(domain)
+			if _, ok := obj.domains[domain]; ok {
+				return nil, fmt.Errorf("domain %q is served by several CA", domain)
+			}
+			obj.domains[domain] = active
+		}
+		for _, td := range conf.TrustDomains {
+			td = normalizeDomain(td)
+			if _, ok := obj.trustDomains[td]; ok {
+				return nil, fmt.Errorf("trust domain %q is served by several CA", td)
+			}
+			obj.trustDomains[td] = active
 		}
 	}
 
+	if err := checkEndpointURLs(obj.list); err != nil {
+		return nil, err
+	}
+
 	return obj, nil
 }
 
+func newIssuer(
+	conf Config, ic IssuerConfig, chain map[string]*x509.Certificate, prev *storeState, signers map[signerSource]Signer,
+) (*Certificate, error) {
+	cert := &Certificate{
+		Chain:     chain,
+		Issuer:    &pki.Certificate{},
+		State:     ic.State,
+		Days:      conf.DefaultExpireDays,
+		ICUs:      firstNonEmpty(ic.IssuingCertificateURLs, conf.IssuingCertificateURLs),
+		OCSPs:     firstNonEmpty(ic.OCSPServerURLs, conf.OCSPServerURLs),
+		CRLs:      firstNonEmpty(ic.CRLDistributionPointURLs, conf.CRLDistributionPointURLs),
+		DeltaCRLs: firstNonEmpty(ic.DeltaCRLURLs, conf.DeltaCRLURLs),
+		CPSs:      conf.CertificatePoliciesURLs,
+		Wildcard:  conf.Wildcard,
+		OCSP:      conf.OCSPWindow,
+	}
+
+	if cert.OCSP.Backdate < 0 || cert.OCSP.Validity < 0 {
+		return nil, fmt.Errorf("ocsp window of %q must not be negative", ic.Cert)
+	}
+	if cert.OCSP.Validity == 0 {
+		cert.OCSP.Validity = DefaultOCSPValidity
+	}
+
+	if err := cert.Issuer.LoadCert(ic.Cert); err != nil {
+		return nil, fmt.Errorf("decode cert %q: %w", ic.Cert, err)
+	}
+	if !cert.Issuer.IsCA() {
+		return nil, fmt.Errorf("file %q is not a CA", ic.Cert)
+	}
+	// all CA signatures go through the signer, so the key may stay outside of casper
+	src := signerSource{
+		key:    ic.Key,
+		pass:   ic.KeyPassphrase,
+		signer: ic.Signer,
+		spki:   string(cert.Issuer.Crt.RawSubjectPublicKeyInfo),
+	}
+	signer, ok := signers[src]
+	if !ok && prev != nil {
+		signer, ok = prev.signers[src]
+	}
+	if !ok {
+		// there is no terminal to ask on reload, only the other sources unlock a new key
+		prompt := ic.KeyPassphrase.Prompt && prev != nil
+		if prompt {
+			ic.KeyPassphrase.Prompt = false
+		}
+		var err error
+		if signer, err = NewSigner(ic, cert.Issuer.Crt); err != nil {
+			if prompt {
+				return nil, fmt.Errorf("issuing CA %q: %w: the passphrase prompt is available at start only, "+
+					"restart the server or configure another passphrase source for the new key", ic.Cert, err)
+			}
+			return nil, fmt.Errorf("issuing CA %q: %w", ic.Cert, err)
+		}
+	}
+	signers[src] = signer
+	cert.Issuer.Key = signer
+
+	return cert, nil
+}
+
+func firstNonEmpty(list ...[]string) []string {
+	for _, v := range list {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return nil
+}
+
+func normalizeDomain(name string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// IsWildcard reports whether the name has a leading wildcard label.
+func IsWildcard(name string) bool {
+	return strings.HasPrefix(name, "*.")
+}
+
+// TrimWildcard returns the name without the leading wildcard label.
+func TrimWildcard(name string) string {
+	return strings.TrimPrefix(name, "*.")
+}
+
 func (s *Store) GetBySubjectKeyId(domain string, ski []byte) (*x509.Certificate, bool) {
-	v, ok := s.domains[domain]
+	st := s.state.Load()
+	v, ok := st.domains[domain]
 	if !ok {
 		return nil, false
 	}
@@ -101,15 +326,59 @@ func (s *Store) GetBySubjectKeyId(domain string, ski []byte) (*x509.Certificate,
 }
 
 func (s *Store) Get(domain string) (*Certificate, bool) {
-	v, ok := s.domains[domain]
+	st := s.state.Load()
+	v, ok := st.domains[domain]
 	if !ok {
 		return nil, false
 	}
 	return v, true
 }
 
+// Match finds the CA by the longest configured domain that equals the name or is its parent zone.
+// A wildcard name is matched by its base domain.
+func (s *Store) Match(name string) (*Certificate, string, bool) {
+	st := s.state.Load()
+	name = normalizeDomain(TrimWildcard(normalizeDomain(name)))
+	for len(name) > 0 {
+		if v, ok := st.domains[name]; ok {
+			return v, name, true
+		}
+		_, name, _ = strings.Cut(name, ".")
+	}
+	return nil, "", false
+}
+
+// MatchTrustDomain finds the CA issuing SPIFFE IDs of the trust domain.
+func (s *Store) MatchTrustDomain(td string) (*Certificate, bool) {
+	st := s.state.Load()
+	v, ok := st.trustDomains[normalizeDomain(td)]
+	return v, ok
+}
+
+// Profile returns the profile by name, an empty name selects the default profile.
+func (s *Store) Profile(name string) (*Profile, bool) {
+	st := s.state.Load()
+	if len(name) == 0 {
+		name = st.defaultProfile
+	}
+	p, ok := st.profiles[name]
+	return p, ok
+}
+
+func (s *Store) DefaultProfile() string {
+	return s.state.Load().defaultProfile
+}
+
+// List returns the issuers serving CRL and OCSP: the active ones and the retiring ones
+// until they expire, no certificate issued by a retiring issuer outlives it.
 func (s *Store) List() []*Certificate {
-	result := make([]*Certificate, 0, len(s.list))
-	result = append(result, s.list...)
+	st := s.state.Load()
+	now := time.Now()
+	result := make([]*Certificate, 0, len(st.list))
+	for _, c := range st.list {
+		if c.IsActive() || now.Before(c.Issuer.Crt.NotAfter) {
+			result = append(result, c)
+		}
+	}
 	return result
 }
@@ -0,0 +1,243 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA writes a self-signed CA certificate and its PKCS#8 key into dir
+// and returns the issuer config pointing at them.
+func testCA(t *testing.T, dir, name string, key crypto.Signer) IssuerConfig {
+	t.Helper()
+
+	if key == nil {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		key = k
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+
+	file := strings.ReplaceAll(name, " ", "-")
+	conf := IssuerConfig{
+		Cert:  filepath.Join(dir, file+".crt"),
+		Key:   filepath.Join(dir, file+".key"),
+		State: IssuerActive,
+	}
+	if err = os.WriteFile(conf.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err = os.WriteFile(conf.Key, pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return conf
+}
+
+// testGroup returns a CA group with a single active issuer serving the domains.
+func testGroup(t *testing.T, dir, name string, domains ...string) Config {
+	t.Helper()
+
+	ca := testCA(t, dir, name, nil)
+	return Config{
+		IssuingCACert:     ca.Cert,
+		IssuingCAKey:      ca.Key,
+		Domains:           domains,
+		DefaultExpireDays: 30,
+	}
+}
+
+func testConfigGroup(certs ...Config) *ConfigGroup {
+	c := &ConfigGroup{Certs: certs}
+	c.Default()
+	return c
+}
+
+func TestStoreMatch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(testConfigGroup(
+		testGroup(t, dir, "CA Example", "example.com"),
+		testGroup(t, dir, "CA Sub", "a.example.com"),
+		testGroup(t, dir, "CA Other", "Other.ORG."),
+	))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		domain   string
+		wantCA   string
+		wantZone string
+	}{
+		{name: "zone itself", domain: "example.com", wantCA: "CA Example", wantZone: "example.com"},
+		{name: "below zone", domain: "www.example.com", wantCA: "CA Example", wantZone: "example.com"},
+		{name: "longest suffix wins", domain: "x.a.example.com", wantCA: "CA Sub", wantZone: "a.example.com"},
+		{name: "longer zone itself", domain: "a.example.com", wantCA: "CA Sub", wantZone: "a.example.com"},
+		{name: "label suffix is not a zone", domain: "ba.example.com", wantCA: "CA Example", wantZone: "example.com"},
+		{name: "wildcard by base domain", domain: "*.a.example.com", wantCA: "CA Sub", wantZone: "a.example.com"},
+		{name: "wildcard of zone", domain: "*.example.com", wantCA: "CA Example", wantZone: "example.com"},
+		{name: "case and trailing dot", domain: "WWW.Other.org.", wantCA: "CA Other", wantZone: "other.org"},
+		{name: "unknown zone", domain: "example.org"},
+		{name: "parent of zone", domain: "com"},
+		{name: "empty", domain: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca, zone, ok := store.Match(tt.domain)
+			if ok != (len(tt.wantCA) > 0) {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.domain, ok, !ok)
+			}
+			if !ok {
+				return
+			}
+			if got := ca.Issuer.Crt.Subject.CommonName; got != tt.wantCA {
+				t.Fatalf("Match(%q) CA = %s, want %s", tt.domain, got, tt.wantCA)
+			}
+			if zone != tt.wantZone {
+				t.Fatalf("Match(%q) zone = %s, want %s", tt.domain, zone, tt.wantZone)
+			}
+		})
+	}
+}
+
+func TestNewStoreDuplicateDomain(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewStore(testConfigGroup(
+		testGroup(t, dir, "CA One", "example.com"),
+		testGroup(t, dir, "CA Two", "EXAMPLE.com."),
+	))
+	if err == nil || !strings.Contains(err.Error(), "served by several CA") {
+		t.Fatalf("NewStore() error = %v, want duplicate domain", err)
+	}
+}
+
+// testEncryptedGroup returns a CA group whose key is encrypted with the passphrase.
+func testEncryptedGroup(t *testing.T, dir, name string, pass Passphrase, domains ...string) Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ca := testCA(t, dir, name, key)
+	b, err := MarshalEncryptedKeyPEM(key, []byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+	if err = os.WriteFile(ca.Key, b, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return Config{
+		IssuingCACert:          ca.Cert,
+		IssuingCAKey:           ca.Key,
+		IssuingCAKeyPassphrase: pass,
+		Domains:                domains,
+		DefaultExpireDays:      30,
+	}
+}
+
+func TestStoreReloadKeys(t *testing.T) {
+	const env = "CASPER_TEST_CA_PASSPHRASE"
+
+	tests := []struct {
+		name       string
+		conf       func(t *testing.T, dir string, current Config) Config
+		wantErr    string
+		wantReused bool
+	}{
+		{
+			name:       "unchanged key is reused",
+			conf:       func(t *testing.T, dir string, current Config) Config { return current },
+			wantReused: true,
+		},
+		{
+			name: "reused with changed settings",
+			conf: func(t *testing.T, dir string, current Config) Config {
+				current.DefaultExpireDays = 10
+				return current
+			},
+			wantReused: true,
+		},
+		{
+			name: "new key behind the prompt",
+			conf: func(t *testing.T, dir string, current Config) Config {
+				return testEncryptedGroup(t, dir, "CA New", Passphrase{Prompt: true}, "example.com")
+			},
+			wantErr: "available at start only",
+		},
+		{
+			name: "passphrase source changed",
+			conf: func(t *testing.T, dir string, current Config) Config {
+				current.IssuingCAKeyPassphrase = Passphrase{Prompt: true}
+				return current
+			},
+			wantErr: "available at start only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			current := testEncryptedGroup(t, dir, "CA Example", Passphrase{Env: env, Prompt: true}, "example.com")
+
+			t.Setenv(env, "secret")
+			store, err := NewStore(testConfigGroup(current))
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			signer := store.List()[0].Issuer.Key
+
+			// the passphrase is gone after start, as with a prompt
+			t.Setenv(env, "")
+			err = store.Reload(testConfigGroup(tt.conf(t, dir, current)))
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Reload() error = %v, want %q", err, tt.wantErr)
+				}
+				if store.List()[0].Issuer.Key != signer {
+					t.Fatalf("Reload() replaced the CA set on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if reused := store.List()[0].Issuer.Key == signer; reused != tt.wantReused {
+				t.Fatalf("signer reused = %v, want %v", reused, tt.wantReused)
+			}
+		})
+	}
+}
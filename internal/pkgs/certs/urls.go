@@ -0,0 +1,84 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// endpointOwner is the issuer and kind of the endpoint served on a path.
+type endpointOwner struct {
+	id     string
+	issuer string
+	kind   string
+	url    string
+}
+
+// checkEndpointURLs verifies that the URLs put into issued certificates can be served:
+// every URL is parseable, a path belongs to one issuer and endpoint kind (the pki server
+// routes by path only), and no URL repeats the one of the issuing CA's own certificate,
+// which points at the parent CA and would be answered with the data of the wrong CA.
+// Issuers are told apart by name and key: a retiring and an active CA of the same name
+// sign different CRLs and OCSP responses, so they need their own URLs. A path of the
+// issuing certificate belongs to the certificate itself.
+func checkEndpointURLs(list []*Certificate) error {
+	var (
+		errs  []error
+		paths = make(map[string]endpointOwner)
+	)
+
+	for _, cert := range list {
+		crt := cert.Issuer.Crt
+		keyID := sha256.Sum256(append(slices.Clip(crt.RawSubject), crt.RawSubjectPublicKeyInfo...))
+		crtID := sha256.Sum256(crt.Raw)
+		issuer := fmt.Sprintf("%s (key %x)", crt.Subject, keyID[:8])
+
+		for _, ep := range []struct {
+			kind string
+			id   []byte
+			urls []string
+			own  []string
+		}{
+			{kind: "issuing certificate", id: crtID[:], urls: cert.ICUs, own: crt.IssuingCertificateURL},
+			{kind: "ocsp server", id: keyID[:], urls: cert.OCSPs, own: crt.OCSPServer},
+			{kind: "crl distribution point", id: keyID[:], urls: cert.CRLs, own: crt.CRLDistributionPoints},
+			{kind: "delta crl", id: keyID[:], urls: cert.DeltaCRLs},
+		} {
+			for _, addr := range ep.urls {
+				uri, err := url.ParseRequestURI(addr)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("issuer %s: %s url %q: %w", issuer, ep.kind, addr, err))
+					continue
+				}
+				if slices.Contains(ep.own, addr) {
+					errs = append(errs, fmt.Errorf("issuer %s: %s url %q is the one of the issuing CA certificate, "+
+						"it is served by the parent CA", issuer, ep.kind, addr))
+				}
+
+				owner := endpointOwner{id: hex.EncodeToString(ep.id), issuer: issuer, kind: ep.kind, url: addr}
+				prev, ok := paths[uri.Path]
+				if !ok {
+					paths[uri.Path] = owner
+					continue
+				}
+				if prev.id != owner.id || prev.kind != owner.kind {
+					errs = append(errs, fmt.Errorf("issuer %s: %s url %q has the same path as %s url %q of issuer %s, "+
+						"every issuer needs its own urls", issuer, ep.kind, addr, prev.kind, prev.url, prev.issuer))
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("endpoint urls of issued certificates disagree:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
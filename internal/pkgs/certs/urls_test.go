@@ -0,0 +1,69 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package certs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckEndpointURLsSameSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		own     func(ic *IssuerConfig)
+		wantErr string
+	}{
+		{
+			name:    "retiring issuer inherits the group urls",
+			own:     func(*IssuerConfig) {},
+			wantErr: "has the same path",
+		},
+		{
+			name: "retiring issuer shares the crl only",
+			own: func(ic *IssuerConfig) {
+				ic.IssuingCertificateURLs = []string{"http://pki.example.com/icu/ca-old.crt"}
+				ic.OCSPServerURLs = []string{"http://pki.example.com/ocsp/ca-old"}
+				ic.DeltaCRLURLs = []string{"http://pki.example.com/crl/ca-old-delta.crl"}
+			},
+			wantErr: "crl distribution point url",
+		},
+		{
+			name: "retiring issuer with its own urls",
+			own: func(ic *IssuerConfig) {
+				ic.IssuingCertificateURLs = []string{"http://pki.example.com/icu/ca-old.crt"}
+				ic.OCSPServerURLs = []string{"http://pki.example.com/ocsp/ca-old"}
+				ic.CRLDistributionPointURLs = []string{"http://pki.example.com/crl/ca-old.crl"}
+				ic.DeltaCRLURLs = []string{"http://pki.example.com/crl/ca-old-delta.crl"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := testGroup(t, t.TempDir(), "CA Example", "example.com")
+			group.IssuingCertificateURLs = []string{"http://pki.example.com/icu/ca.crt"}
+			group.OCSPServerURLs = []string{"http://pki.example.com/ocsp/ca"}
+			group.CRLDistributionPointURLs = []string{"http://pki.example.com/crl/ca.crl"}
+			group.DeltaCRLURLs = []string{"http://pki.example.com/crl/ca-delta.crl"}
+
+			// the same subject with another key, as a re-keyed intermediate has
+			retiring := testCA(t, t.TempDir(), "CA Example", nil)
+			retiring.State = IssuerRetiring
+			tt.own(&retiring)
+			group.Issuers = []IssuerConfig{retiring}
+
+			_, err := NewStore(testConfigGroup(group))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("NewStore() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewStore() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
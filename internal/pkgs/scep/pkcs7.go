@@ -0,0 +1,564 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package scep
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Minimal PKCS#7 (RFC 2315) subset used by SCEP: SignedData with authenticated
+// attributes and EnvelopedData with RSA key transport.
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+
+	oidAttrContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA1WithRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECPublicKey     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// contentInfo keeps the [0] EXPLICIT wrapper in Content, the inner value is in Content.Bytes.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      contentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerial
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerial struct {
+	IssuerName   asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type envelopedData struct {
+	Version              int
+	RecipientInfos       []recipientInfo `asn1:"set"`
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type recipientInfo struct {
+	Version                int
+	IssuerAndSerialNumber  issuerAndSerial
+	KeyEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedKey           []byte
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// signed is a verified SignedData.
+type signed struct {
+	Content    []byte
+	Signer     *x509.Certificate
+	Attributes map[string]asn1.RawValue
+	Digest     asn1.ObjectIdentifier
+}
+
+func explicit0(inner []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: inner}
+}
+
+// octets returns the value of a primitive or a BER constructed OCTET STRING.
+func octets(v asn1.RawValue) ([]byte, error) {
+	if !v.IsCompound {
+		return v.Bytes, nil
+	}
+	var (
+		result []byte
+		rest   = v.Bytes
+	)
+	for len(rest) > 0 {
+		var part asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &part); err != nil {
+			return nil, err
+		}
+		b, err := octets(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, b...)
+	}
+	return result, nil
+}
+
+func hashByOID(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, true
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func signatureAlgorithm(digest, enc asn1.ObjectIdentifier) (x509.SignatureAlgorithm, error) {
+	h, ok := hashByOID(digest)
+	if !ok {
+		return 0, fmt.Errorf("unsupported digest algorithm %s", digest)
+	}
+
+	switch {
+	case enc.Equal(oidRSAEncryption), enc.Equal(oidSHA1WithRSA), enc.Equal(oidSHA256WithRSA),
+		enc.Equal(oidSHA384WithRSA), enc.Equal(oidSHA512WithRSA):
+		switch h {
+		case crypto.SHA1:
+			return x509.SHA1WithRSA, nil
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, nil
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, nil
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, nil
+		}
+	case enc.Equal(oidECPublicKey), enc.Equal(oidECDSAWithSHA1), enc.Equal(oidECDSAWithSHA256),
+		enc.Equal(oidECDSAWithSHA384), enc.Equal(oidECDSAWithSHA512):
+		switch h {
+		case crypto.SHA1:
+			return x509.ECDSAWithSHA1, nil
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported signature algorithm %s", enc)
+}
+
+// parseSigned decodes SignedData with a single signer and verifies the signature
+// over the authenticated attributes.
+func parseSigned(der []byte) (*signed, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("failed to decode content info: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unexpected content type %s", ci.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("failed to decode signed data: %w", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("expected one signer, got %d", len(sd.SignerInfos))
+	}
+
+	var encap asn1.RawValue
+	if len(sd.ContentInfo.Content.Bytes) > 0 {
+		if _, err := asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &encap); err != nil {
+			return nil, fmt.Errorf("failed to decode content: %w", err)
+		}
+	}
+	content, err := octets(encap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificates: %w", err)
+	}
+
+	si := sd.SignerInfos[0]
+	idx := slices.IndexFunc(certs, func(c *x509.Certificate) bool {
+		return bytes.Equal(c.RawIssuer, si.IssuerAndSerialNumber.IssuerName.FullBytes) &&
+			c.SerialNumber.Cmp(si.IssuerAndSerialNumber.SerialNumber) == 0
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("signer certificate not found")
+	}
+
+	result := &signed{
+		Content:    content,
+		Signer:     certs[idx],
+		Attributes: make(map[string]asn1.RawValue),
+		Digest:     si.DigestAlgorithm.Algorithm,
+	}
+
+	if len(si.AuthenticatedAttributes.Bytes) == 0 {
+		return nil, fmt.Errorf("authenticated attributes are required")
+	}
+	for rest := si.AuthenticatedAttributes.Bytes; len(rest) > 0; {
+		var attr attribute
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return nil, fmt.Errorf("failed to decode attribute: %w", err)
+		}
+		var value asn1.RawValue
+		if _, err = asn1.Unmarshal(attr.Value.Bytes, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode attribute %s: %w", attr.Type, err)
+		}
+		result.Attributes[attr.Type.String()] = value
+	}
+
+	h, ok := hashByOID(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %s", si.DigestAlgorithm.Algorithm)
+	}
+	hh := h.New()
+	hh.Write(content)
+	if md, ok := result.Attributes[oidAttrMessageDigest.String()]; !ok || !bytes.Equal(md.Bytes, hh.Sum(nil)) {
+		return nil, fmt.Errorf("message digest mismatch")
+	}
+
+	alg, err := signatureAlgorithm(si.DigestAlgorithm.Algorithm, si.DigestEncryptionAlgorithm.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature covers the attributes encoded as SET OF instead of [0] IMPLICIT
+	signedAttrs := slices.Clone(si.AuthenticatedAttributes.FullBytes)
+	signedAttrs[0] = 0x31
+	if err = result.Signer.CheckSignature(alg, signedAttrs, si.EncryptedDigest); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return result, nil
+}
+
+func marshalAttribute(oid asn1.ObjectIdentifier, value []byte) ([]byte, error) {
+	return asn1.Marshal(attribute{
+		Type:  oid,
+		Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+	})
+}
+
+type attributeValue struct {
+	Type  asn1.ObjectIdentifier
+	Value []byte
+}
+
+// marshalSigned builds SignedData over the content with authenticated attributes signed by the key.
+func marshalSigned(
+	content []byte, crt *x509.Certificate, key crypto.Signer, attrs []attributeValue,
+) ([]byte, error) {
+	digest := crypto.SHA256.New()
+	digest.Write(content)
+
+	md, err := asn1.Marshal(digest.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	ct, err := asn1.Marshal(oidData)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs,
+		attributeValue{Type: oidAttrMessageDigest, Value: md},
+		attributeValue{Type: oidAttrContentType, Value: ct},
+	)
+
+	encoded := make([][]byte, 0, len(attrs))
+	for _, attr := range attrs {
+		b, err := marshalAttribute(attr.Type, attr.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode attribute %s: %w", attr.Type, err)
+		}
+		encoded = append(encoded, b)
+	}
+	// DER SET OF is ordered by encoding
+	slices.SortFunc(encoded, bytes.Compare)
+	attrBytes := bytes.Join(encoded, nil)
+
+	signedAttrs, err := asn1.Marshal(asn1.RawValue{
+		Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sum := crypto.SHA256.New()
+	sum.Write(signedAttrs)
+	sig, err := key.Sign(rand.Reader, sum.Sum(nil), crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attributes: %w", err)
+	}
+
+	encAlg := pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}
+	if _, ok := key.Public().(*rsa.PublicKey); !ok {
+		encAlg = pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}
+	}
+
+	octet, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+		ContentInfo      struct {
+			ContentType asn1.ObjectIdentifier
+			Content     asn1.RawValue
+		}
+		Certificates asn1.RawValue
+		SignerInfos  []struct {
+			Version                   int
+			IssuerAndSerialNumber     issuerAndSerial
+			DigestAlgorithm           pkix.AlgorithmIdentifier
+			AuthenticatedAttributes   asn1.RawValue
+			DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+			EncryptedDigest           []byte
+		} `asn1:"set"`
+	}{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}},
+		ContentInfo: struct {
+			ContentType asn1.ObjectIdentifier
+			Content     asn1.RawValue
+		}{ContentType: oidData, Content: explicit0(octet)},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: crt.Raw,
+		},
+		SignerInfos: []struct {
+			Version                   int
+			IssuerAndSerialNumber     issuerAndSerial
+			DigestAlgorithm           pkix.AlgorithmIdentifier
+			AuthenticatedAttributes   asn1.RawValue
+			DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+			EncryptedDigest           []byte
+		}{{
+			Version: 1,
+			IssuerAndSerialNumber: issuerAndSerial{
+				IssuerName:   asn1.RawValue{FullBytes: crt.RawIssuer},
+				SerialNumber: crt.SerialNumber,
+			},
+			DigestAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			AuthenticatedAttributes: asn1.RawValue{
+				Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrBytes,
+			},
+			DigestEncryptionAlgorithm: encAlg,
+			EncryptedDigest:           sig,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed data: %w", err)
+	}
+
+	return asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{ContentType: oidSignedData, Content: explicit0(sd)})
+}
+
+func newBlock(alg asn1.ObjectIdentifier, key []byte) (cipher.Block, error) {
+	switch {
+	case alg.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher(key)
+	case alg.Equal(oidAES128CBC), alg.Equal(oidAES256CBC):
+		return aes.NewCipher(key)
+	}
+	return nil, fmt.Errorf("unsupported content encryption %s", alg)
+}
+
+func keySize(alg asn1.ObjectIdentifier) int {
+	switch {
+	case alg.Equal(oidDESEDE3CBC):
+		return 24
+	case alg.Equal(oidAES256CBC):
+		return 32
+	default:
+		return 16
+	}
+}
+
+// decryptEnveloped opens EnvelopedData addressed to the certificate, returning the content
+// and the content encryption algorithm to mirror in the reply.
+func decryptEnveloped(der []byte, crt *x509.Certificate, key crypto.Decrypter) ([]byte, asn1.ObjectIdentifier, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode content info: %w", err)
+	}
+	if !ci.ContentType.Equal(oidEnvelopedData) {
+		return nil, nil, fmt.Errorf("unexpected content type %s", ci.ContentType)
+	}
+
+	var ed envelopedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode enveloped data: %w", err)
+	}
+
+	idx := slices.IndexFunc(ed.RecipientInfos, func(ri recipientInfo) bool {
+		return bytes.Equal(ri.IssuerAndSerialNumber.IssuerName.FullBytes, crt.RawIssuer) &&
+			ri.IssuerAndSerialNumber.SerialNumber.Cmp(crt.SerialNumber) == 0
+	})
+	if idx < 0 {
+		return nil, nil, fmt.Errorf("recipient not found")
+	}
+
+	cek, err := key.Decrypt(rand.Reader, ed.RecipientInfos[idx].EncryptedKey, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt content key: %w", err)
+	}
+
+	eci := ed.EncryptedContentInfo
+	block, err := newBlock(eci.ContentEncryptionAlgorithm.Algorithm, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var iv []byte
+	if _, err = asn1.Unmarshal(eci.ContentEncryptionAlgorithm.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode iv: %w", err)
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, nil, fmt.Errorf("invalid iv size")
+	}
+
+	data, err := octets(eci.EncryptedContent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode encrypted content: %w", err)
+	}
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, nil, fmt.Errorf("invalid encrypted content size")
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > block.BlockSize() || pad > len(plain) {
+		return nil, nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range plain[len(plain)-pad:] {
+		if int(b) != pad {
+			return nil, nil, fmt.Errorf("invalid padding")
+		}
+	}
+
+	return plain[:len(plain)-pad], eci.ContentEncryptionAlgorithm.Algorithm, nil
+}
+
+// encryptEnveloped builds EnvelopedData for the RSA recipient certificate.
+func encryptEnveloped(content []byte, recipient *x509.Certificate, alg asn1.ObjectIdentifier) ([]byte, error) {
+	pub, ok := recipient.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("recipient key is not RSA")
+	}
+
+	cek := make([]byte, keySize(alg))
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	block, err := newBlock(alg, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, block.BlockSize())
+	if _, err = rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	pad := block.BlockSize() - len(content)%block.BlockSize()
+	plain := append(slices.Clone(content), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	data := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, plain)
+
+	encKey, err := rsa.EncryptPKCS1v15(rand.Reader, pub, cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt content key: %w", err)
+	}
+
+	ivDER, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	ed, err := asn1.Marshal(envelopedData{
+		Version: 0,
+		RecipientInfos: []recipientInfo{{
+			Version: 0,
+			IssuerAndSerialNumber: issuerAndSerial{
+				IssuerName:   asn1.RawValue{FullBytes: recipient.RawIssuer},
+				SerialNumber: recipient.SerialNumber,
+			},
+			KeyEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue},
+			EncryptedKey:           encKey,
+		}},
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType: oidData,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  alg,
+				Parameters: asn1.RawValue{FullBytes: ivDER},
+			},
+			EncryptedContent: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: data},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode enveloped data: %w", err)
+	}
+
+	return asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{ContentType: oidEnvelopedData, Content: explicit0(ed)})
+}
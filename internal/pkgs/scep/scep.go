@@ -0,0 +1,231 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package scep
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"time"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+// RFC 8894 message attributes.
+var (
+	oidAttrMessageType    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 2}
+	oidAttrPKIStatus      = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 3}
+	oidAttrFailInfo       = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 4}
+	oidAttrSenderNonce    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 5}
+	oidAttrRecipientNonce = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 6}
+	oidAttrTransactionID  = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 7}
+
+	oidChallengePassword = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 7}
+)
+
+// Capabilities returned by GetCACaps.
+var Capabilities = []string{"POSTPKIOperation", "Renewal", "SHA-1", "SHA-256", "AES", "DES3", "SCEPStandard"}
+
+type MessageType string
+
+const (
+	MessageTypeCertRep    MessageType = "3"
+	MessageTypeRenewalReq MessageType = "17"
+	MessageTypePKCSReq    MessageType = "19"
+)
+
+type PKIStatus string
+
+const (
+	PKIStatusSuccess PKIStatus = "0"
+	PKIStatusFailure PKIStatus = "2"
+)
+
+type FailInfo string
+
+const (
+	FailInfoBadAlg          FailInfo = "0"
+	FailInfoBadMessageCheck FailInfo = "1"
+	FailInfoBadRequest      FailInfo = "2"
+	FailInfoBadTime         FailInfo = "3"
+	FailInfoBadCertID       FailInfo = "4"
+)
+
+// PKIMessage is a verified client request. The signer is the self-signed
+// certificate of the device for PKCSReq or the current certificate for RenewalReq.
+type PKIMessage struct {
+	MessageType   MessageType
+	TransactionID string
+	SenderNonce   []byte
+	Signer        *x509.Certificate
+
+	envelope []byte
+	cipher   asn1.ObjectIdentifier
+}
+
+// ParsePKIMessage decodes a PKIOperation message and verifies its signature.
+func ParsePKIMessage(der []byte) (*PKIMessage, error) {
+	sd, err := parseSigned(der)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &PKIMessage{
+		Signer:   sd.Signer,
+		envelope: sd.Content,
+		cipher:   oidDESEDE3CBC,
+	}
+
+	if v, ok := sd.Attributes[oidAttrMessageType.String()]; ok {
+		msg.MessageType = MessageType(v.Bytes)
+	}
+	if v, ok := sd.Attributes[oidAttrTransactionID.String()]; ok {
+		msg.TransactionID = string(v.Bytes)
+	}
+	if v, ok := sd.Attributes[oidAttrSenderNonce.String()]; ok {
+		msg.SenderNonce = v.Bytes
+	}
+
+	if len(msg.MessageType) == 0 || len(msg.TransactionID) == 0 || len(msg.SenderNonce) == 0 {
+		return nil, fmt.Errorf("required scep attributes are missing")
+	}
+
+	return msg, nil
+}
+
+// DecryptCSR opens the envelope with the CA key, RSA keys are required by SCEP key transport.
+func (m *PKIMessage) DecryptCSR(ca *x509.Certificate, key crypto.Signer) (*x509.CertificateRequest, error) {
+	dec, ok := key.(crypto.Decrypter)
+	if !ok {
+		return nil, fmt.Errorf("ca key does not support decryption")
+	}
+
+	b, alg, err := decryptEnveloped(m.envelope, ca, dec)
+	if err != nil {
+		return nil, err
+	}
+	m.cipher = alg
+
+	csr, err := x509.ParseCertificateRequest(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode csr: %w", err)
+	}
+	return csr, nil
+}
+
+// Success builds CertRep with the issued certificate encrypted for the signer.
+func (m *PKIMessage) Success(ca *x509.Certificate, key crypto.Signer, issued *x509.Certificate) ([]byte, error) {
+	p7, err := certs.MarshalCertsOnlyPKCS7(issued)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := encryptEnveloped(p7, m.Signer, m.cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.reply(ca, key, envelope, PKIStatusSuccess, "")
+}
+
+// Failure builds CertRep without content.
+func (m *PKIMessage) Failure(ca *x509.Certificate, key crypto.Signer, info FailInfo) ([]byte, error) {
+	return m.reply(ca, key, nil, PKIStatusFailure, info)
+}
+
+func (m *PKIMessage) reply(
+	ca *x509.Certificate, key crypto.Signer, content []byte, status PKIStatus, info FailInfo,
+) ([]byte, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	attrs := []attributeValue{
+		stringAttribute(oidAttrMessageType, string(MessageTypeCertRep)),
+		stringAttribute(oidAttrPKIStatus, string(status)),
+		stringAttribute(oidAttrTransactionID, m.TransactionID),
+		octetAttribute(oidAttrSenderNonce, nonce),
+		octetAttribute(oidAttrRecipientNonce, m.SenderNonce),
+	}
+	if status == PKIStatusFailure {
+		attrs = append(attrs, stringAttribute(oidAttrFailInfo, string(info)))
+	}
+
+	signingTime, err := asn1.MarshalWithParams(time.Now().UTC(), "utc")
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attributeValue{Type: oidAttrSigningTime, Value: signingTime})
+
+	return marshalSigned(content, ca, key, attrs)
+}
+
+// stringAttribute encodes PrintableString, falling back to UTF8String for other characters.
+func stringAttribute(oid asn1.ObjectIdentifier, value string) attributeValue {
+	b, _ := asn1.Marshal(value)
+	return attributeValue{Type: oid, Value: b}
+}
+
+func octetAttribute(oid asn1.ObjectIdentifier, value []byte) attributeValue {
+	b, _ := asn1.Marshal(value)
+	return attributeValue{Type: oid, Value: b}
+}
+
+// IsAddressedTo reports whether the envelope can be opened by the certificate.
+func (m *PKIMessage) IsAddressedTo(crt *x509.Certificate) bool {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(m.envelope, &ci); err != nil {
+		return false
+	}
+	var ed envelopedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+		return false
+	}
+	for _, ri := range ed.RecipientInfos {
+		if bytes.Equal(ri.IssuerAndSerialNumber.IssuerName.FullBytes, crt.RawIssuer) &&
+			ri.IssuerAndSerialNumber.SerialNumber.Cmp(crt.SerialNumber) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ChallengePassword extracts the PKCS#9 challengePassword attribute of the CSR.
+func ChallengePassword(csr *x509.CertificateRequest) (string, error) {
+	var tbs struct {
+		Version    int
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Attributes asn1.RawValue `asn1:"optional,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return "", fmt.Errorf("failed to decode csr: %w", err)
+	}
+
+	for rest := tbs.Attributes.Bytes; len(rest) > 0; {
+		var (
+			attr attribute
+			err  error
+		)
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return "", fmt.Errorf("failed to decode csr attribute: %w", err)
+		}
+		if !attr.Type.Equal(oidChallengePassword) {
+			continue
+		}
+		var value string
+		if _, err = asn1.Unmarshal(attr.Value.Bytes, &value); err != nil {
+			return "", fmt.Errorf("failed to decode challenge password: %w", err)
+		}
+		return value, nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,235 @@
+/*
+ *  Copyright (c) 2025 Mikhail Knyazhev <markus621@yandex.com>. All rights reserved.
+ *  Use of this source code is governed by a GPL-3.0 license that can be found in the LICENSE file.
+ */
+
+package scep
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.arwos.org/casper/internal/pkgs/certs"
+)
+
+func testCert(t *testing.T, name string, key crypto.Signer, isCA bool) *x509.Certificate {
+	t.Helper()
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: isCA,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tpl.KeyUsage |= x509.KeyUsageCertSign
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return crt
+}
+
+// testFileSigner writes the CA into dir and opens it with the file signer as the server does.
+func testFileSigner(t *testing.T, dir string, key crypto.Signer) (*x509.Certificate, certs.Signer) {
+	t.Helper()
+
+	crt := testCert(t, "SCEP CA", key, true)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	conf := certs.IssuerConfig{Cert: filepath.Join(dir, "ca.crt"), Key: filepath.Join(dir, "ca.key")}
+	if err = os.WriteFile(conf.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err = os.WriteFile(conf.Key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	signer, err := certs.NewSigner(conf, crt)
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	return crt, signer
+}
+
+// testRequest builds a PKCSReq of a device as a SCEP client does.
+func testRequest(t *testing.T, ca *x509.Certificate, alg asn1.ObjectIdentifier) ([]byte, *x509.CertificateRequest, *x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	device := testCert(t, "device", key, false)
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "device.example.com"},
+		DNSNames: []string{"device.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatalf("failed to create csr: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		t.Fatalf("failed to parse csr: %v", err)
+	}
+
+	envelope, err := encryptEnveloped(csrDER, ca, alg)
+	if err != nil {
+		t.Fatalf("encryptEnveloped() error = %v", err)
+	}
+	req, err := marshalSigned(envelope, device, key, []attributeValue{
+		stringAttribute(oidAttrMessageType, string(MessageTypePKCSReq)),
+		stringAttribute(oidAttrTransactionID, "transaction-1"),
+		octetAttribute(oidAttrSenderNonce, []byte("sender-nonce-123")),
+	})
+	if err != nil {
+		t.Fatalf("marshalSigned() error = %v", err)
+	}
+	return req, csr, device, key
+}
+
+func TestPKIMessageRoundTrip(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ca, signer := testFileSigner(t, t.TempDir(), caKey)
+
+	tests := []struct {
+		name string
+		alg  asn1.ObjectIdentifier
+	}{
+		{name: "aes128", alg: oidAES128CBC},
+		{name: "aes256", alg: oidAES256CBC},
+		{name: "des3", alg: oidDESEDE3CBC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, csr, device, deviceKey := testRequest(t, ca, tt.alg)
+
+			msg, err := ParsePKIMessage(req)
+			if err != nil {
+				t.Fatalf("ParsePKIMessage() error = %v", err)
+			}
+			if msg.MessageType != MessageTypePKCSReq || msg.TransactionID != "transaction-1" || !msg.Signer.Equal(device) {
+				t.Fatalf("ParsePKIMessage() = %s %s, unexpected message", msg.MessageType, msg.TransactionID)
+			}
+			if !msg.IsAddressedTo(ca) {
+				t.Fatalf("IsAddressedTo() = false, want true")
+			}
+
+			got, err := msg.DecryptCSR(ca, signer)
+			if err != nil {
+				t.Fatalf("DecryptCSR() error = %v", err)
+			}
+			if !bytes.Equal(got.Raw, csr.Raw) {
+				t.Fatalf("DecryptCSR() returned other csr")
+			}
+
+			issuedDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+				SerialNumber: big.NewInt(42),
+				Subject:      got.Subject,
+				DNSNames:     got.DNSNames,
+				NotBefore:    time.Now(),
+				NotAfter:     time.Now().Add(time.Hour),
+			}, ca, got.PublicKey, signer)
+			if err != nil {
+				t.Fatalf("failed to issue certificate: %v", err)
+			}
+			issued, err := x509.ParseCertificate(issuedDER)
+			if err != nil {
+				t.Fatalf("failed to parse issued certificate: %v", err)
+			}
+
+			reply, err := msg.Success(ca, signer, issued)
+			if err != nil {
+				t.Fatalf("Success() error = %v", err)
+			}
+
+			// the client verifies the reply of the CA and opens the certificate with its key
+			sd, err := parseSigned(reply)
+			if err != nil {
+				t.Fatalf("parseSigned() error = %v", err)
+			}
+			if !sd.Signer.Equal(ca) {
+				t.Fatalf("reply is signed by %s, want the CA", sd.Signer.Subject)
+			}
+			if v := sd.Attributes[oidAttrPKIStatus.String()]; string(v.Bytes) != string(PKIStatusSuccess) {
+				t.Fatalf("reply status = %q, want success", v.Bytes)
+			}
+			if v := sd.Attributes[oidAttrRecipientNonce.String()]; !bytes.Equal(v.Bytes, msg.SenderNonce) {
+				t.Fatalf("reply recipient nonce = %q, want %q", v.Bytes, msg.SenderNonce)
+			}
+			p7, _, err := decryptEnveloped(sd.Content, device, deviceKey)
+			if err != nil {
+				t.Fatalf("decryptEnveloped() error = %v", err)
+			}
+			if !bytes.Contains(p7, issued.Raw) {
+				t.Fatalf("reply does not carry the issued certificate")
+			}
+		})
+	}
+}
+
+func TestDecryptCSRWithoutDecrypter(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ca := testCert(t, "SCEP CA", rsaKey, true)
+	_, ecSigner := testFileSigner(t, t.TempDir(), ecKey)
+
+	req, _, _, _ := testRequest(t, ca, oidAES256CBC)
+	msg, err := ParsePKIMessage(req)
+	if err != nil {
+		t.Fatalf("ParsePKIMessage() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  crypto.Signer
+	}{
+		{name: "signer without decrypt", key: signOnly{rsaKey}},
+		{name: "ec key of file signer", key: ecSigner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := msg.DecryptCSR(ca, tt.key); err == nil {
+				t.Fatalf("DecryptCSR() error = nil, want error")
+			}
+		})
+	}
+}
+
+// signOnly hides Decrypt of the key like the socket signer.
+type signOnly struct {
+	crypto.Signer
+}
@@ -0,0 +1,19 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "acme_account__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "acme_account"
+(
+	"id" BIGINT DEFAULT nextval('acme_account__id__seq') NOT NULL,
+	CONSTRAINT "acme_account__id__pk" PRIMARY KEY ( "id" ),
+	"owner" BIGINT NOT NULL,
+	CONSTRAINT "acme_account__owner__fk" FOREIGN KEY ( "owner" ) REFERENCES "auth" ( "id" ) ON DELETE CASCADE NOT DEFERRABLE,
+	"thumbprint" TEXT NOT NULL,
+	CONSTRAINT "acme_account__thumbprint__unq" UNIQUE ( "thumbprint" ),
+	"jwk" TEXT NOT NULL,
+	"contacts" TEXT[] NOT NULL,
+	"status" TEXT NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL,
+	"updated_at" TIMESTAMPTZ NOT NULL
+);
+
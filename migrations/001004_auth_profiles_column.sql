@@ -0,0 +1,2 @@
+-- COLUMN
+ALTER TABLE "auth" ADD COLUMN IF NOT EXISTS "profiles" TEXT[] DEFAULT '{}' NOT NULL;
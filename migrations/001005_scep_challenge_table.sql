@@ -0,0 +1,16 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "scep_challenge__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "scep_challenge"
+(
+	"id" BIGINT DEFAULT nextval('scep_challenge__id__seq') NOT NULL,
+	CONSTRAINT "scep_challenge__id__pk" PRIMARY KEY ( "id" ),
+	"owner" BIGINT NOT NULL,
+	CONSTRAINT "scep_challenge__owner__fk" FOREIGN KEY ( "owner" ) REFERENCES "auth" ( "id" ) ON DELETE CASCADE NOT DEFERRABLE,
+	"secret" TEXT NOT NULL,
+	CONSTRAINT "scep_challenge__secret__unq" UNIQUE ( "secret" ),
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL
+);
+
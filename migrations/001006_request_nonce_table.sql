@@ -0,0 +1,13 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "request_nonce__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "request_nonce"
+(
+	"id" BIGINT DEFAULT nextval('request_nonce__id__seq') NOT NULL,
+	CONSTRAINT "request_nonce__id__pk" PRIMARY KEY ( "id" ),
+	"nonce" TEXT NOT NULL,
+	CONSTRAINT "request_nonce__nonce__unq" UNIQUE ( "nonce" ),
+	"expires_at" TIMESTAMPTZ NOT NULL
+);
+
@@ -0,0 +1,20 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "crl__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "crl"
+(
+	"id" BIGINT DEFAULT nextval('crl__id__seq') NOT NULL,
+	CONSTRAINT "crl__id__pk" PRIMARY KEY ( "id" ),
+	"issuer_key_hash" TEXT NOT NULL,
+	"number" BIGINT NOT NULL,
+	"base_number" BIGINT NOT NULL,
+	"data" BYTEA NOT NULL,
+	"this_update" TIMESTAMPTZ NOT NULL,
+	"next_update" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL
+);
+
+-- INDEX
+CREATE INDEX "crl__issuer_key_hash__idx" ON "crl" USING btree ( "issuer_key_hash" );
+
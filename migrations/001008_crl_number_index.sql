@@ -0,0 +1,2 @@
+-- INDEX
+CREATE UNIQUE INDEX IF NOT EXISTS "crl__issuer_key_hash__number__unq" ON "crl" USING btree ( "issuer_key_hash", "number" );
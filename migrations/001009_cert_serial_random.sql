@@ -0,0 +1,25 @@
+-- FUNCTION
+-- Random positive serial with 127 bits of entropy (RFC 5280 4.1.2.2, at most 20 octets),
+-- gen_random_uuid uses a strong random source and its first 8 hex digits are random.
+CREATE OR REPLACE FUNCTION "cert_serial_random"() RETURNS NUMERIC
+LANGUAGE plpgsql VOLATILE AS $$
+DECLARE
+	result NUMERIC := 0;
+BEGIN
+	FOR i IN 1..4 LOOP
+		result := result * 4294967296 + ('x' || substr(gen_random_uuid()::TEXT, 1, 8))::BIT(32)::BIGINT;
+	END LOOP;
+	RETURN mod(result, 170141183460469231731687303715884105728) + 1;
+END;
+$$;
+
+-- COLUMN
+ALTER TABLE "cert_domain" DROP CONSTRAINT IF EXISTS "cert_domain__cert_id__fk";
+ALTER TABLE "cert_info" ALTER COLUMN "id" DROP DEFAULT;
+ALTER TABLE "cert_info" ALTER COLUMN "id" TYPE NUMERIC(40, 0);
+ALTER TABLE "cert_domain" ALTER COLUMN "cert_id" TYPE NUMERIC(40, 0);
+ALTER TABLE "cert_info" ALTER COLUMN "id" SET DEFAULT "cert_serial_random"();
+ALTER TABLE "cert_domain" ADD CONSTRAINT "cert_domain__cert_id__fk" FOREIGN KEY ( "cert_id" ) REFERENCES "cert_info" ( "id" ) ON DELETE CASCADE NOT DEFERRABLE;
+
+-- SEQUENCE
+DROP SEQUENCE IF EXISTS "cert_info__id__seq";
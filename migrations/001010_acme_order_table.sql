@@ -0,0 +1,25 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "acme_order__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "acme_order"
+(
+	"id" BIGINT DEFAULT nextval('acme_order__id__seq') NOT NULL,
+	CONSTRAINT "acme_order__id__pk" PRIMARY KEY ( "id" ),
+	"order_id" TEXT NOT NULL,
+	CONSTRAINT "acme_order__order_id__unq" UNIQUE ( "order_id" ),
+	"account_id" BIGINT NOT NULL,
+	CONSTRAINT "acme_order__account_id__fk" FOREIGN KEY ( "account_id" ) REFERENCES "acme_account" ( "id" ) ON DELETE CASCADE NOT DEFERRABLE,
+	"status" TEXT NOT NULL,
+	"identifiers" TEXT[] NOT NULL,
+	"error" TEXT NOT NULL,
+	"serial_number" TEXT NOT NULL,
+	"cert_pem" BYTEA NOT NULL,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL,
+	"updated_at" TIMESTAMPTZ NOT NULL
+);
+
+-- INDEX
+CREATE INDEX "acme_order__expires_at__idx" ON "acme_order" USING btree ( "expires_at" );
+
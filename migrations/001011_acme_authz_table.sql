@@ -0,0 +1,19 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "acme_authz__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "acme_authz"
+(
+	"id" BIGINT DEFAULT nextval('acme_authz__id__seq') NOT NULL,
+	CONSTRAINT "acme_authz__id__pk" PRIMARY KEY ( "id" ),
+	"authz_id" TEXT NOT NULL,
+	CONSTRAINT "acme_authz__authz_id__unq" UNIQUE ( "authz_id" ),
+	"order_id" TEXT NOT NULL,
+	CONSTRAINT "acme_authz__order_id__fk" FOREIGN KEY ( "order_id" ) REFERENCES "acme_order" ( "order_id" ) ON DELETE CASCADE NOT DEFERRABLE,
+	"account_id" BIGINT NOT NULL,
+	"identifier" TEXT NOT NULL,
+	"wildcard" BOOLEAN NOT NULL,
+	"status" TEXT NOT NULL,
+	"expires_at" TIMESTAMPTZ NOT NULL
+);
+
@@ -0,0 +1,19 @@
+-- SEQUENCE
+CREATE SEQUENCE IF NOT EXISTS "acme_challenge__id__seq" INCREMENT 1 MINVALUE 1 MAXVALUE 9223372036854775807 CACHE 1;
+
+-- TABLE
+CREATE TABLE IF NOT EXISTS "acme_challenge"
+(
+	"id" BIGINT DEFAULT nextval('acme_challenge__id__seq') NOT NULL,
+	CONSTRAINT "acme_challenge__id__pk" PRIMARY KEY ( "id" ),
+	"challenge_id" TEXT NOT NULL,
+	CONSTRAINT "acme_challenge__challenge_id__unq" UNIQUE ( "challenge_id" ),
+	"authz_id" TEXT NOT NULL,
+	CONSTRAINT "acme_challenge__authz_id__fk" FOREIGN KEY ( "authz_id" ) REFERENCES "acme_authz" ( "authz_id" ) ON DELETE CASCADE NOT DEFERRABLE,
+	"type" TEXT NOT NULL,
+	"token" TEXT NOT NULL,
+	"status" TEXT NOT NULL,
+	"error" TEXT NOT NULL,
+	"validated_at" TIMESTAMPTZ NOT NULL
+);
+
@@ -0,0 +1,3 @@
+-- INDEX
+CREATE INDEX IF NOT EXISTS "acme_authz__order_id__idx" ON "acme_authz" USING btree ( "order_id" );
+CREATE INDEX IF NOT EXISTS "acme_challenge__authz_id__idx" ON "acme_challenge" USING btree ( "authz_id" );